@@ -8,9 +8,41 @@ import (
 	"github.com/gin-gonic/gin"
 	db "github.com/gu3sswho/simplebank/db/sqlc"
 	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
 	"github.com/lib/pq"
 )
 
+// accountResponse is an account with the part of its balance which is not held
+// Both balances are also shown as decimal strings in major units of the currency
+type accountResponse struct {
+	db.Account
+	AvailableBalance        int64  `json:"availableBalance"`
+	BalanceDecimal          string `json:"balance_decimal"`
+	AvailableBalanceDecimal string `json:"available_balance_decimal"`
+}
+
+// newAccountResponse loads active holds of the account to calculate its available balance
+func (server *Server) newAccountResponse(ctx *gin.Context, account db.Account) (accountResponse, error) {
+	held, err := server.store.GetHeldAmount(ctx, account.ID)
+	if err != nil {
+		return accountResponse{}, err
+	}
+
+	return makeAccountResponse(account, held), nil
+}
+
+// makeAccountResponse returns the account with the held amount taken from its available balance
+func makeAccountResponse(account db.Account, held int64) accountResponse {
+	available := account.Balance - held
+
+	return accountResponse{
+		Account:                 account,
+		AvailableBalance:        available,
+		BalanceDecimal:          decimal(account.Balance, account.Currency),
+		AvailableBalanceDecimal: decimal(available, account.Currency),
+	}
+}
+
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
 }
@@ -31,7 +63,7 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		Balance:  0,
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	account, err := server.store.CreateAccountTx(ctx, arg)
 
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
@@ -45,7 +77,9 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	//new account can't have holds yet
+	setAccountETag(ctx, account)
+	ctx.JSON(http.StatusOK, makeAccountResponse(account, 0))
 }
 
 type getAccountRequest struct {
@@ -79,12 +113,24 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	rsp, err := server.newAccountResponse(ctx, account)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	setAccountETag(ctx, account)
+	ctx.JSON(http.StatusOK, rsp)
 }
 
 type listAccountsRequest struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
-	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	pageRequest
+}
+
+// listAccountsResponse is returned in keyset mode, page mode keeps returning the bare list
+type listAccountsResponse struct {
+	Accounts   []accountResponse `json:"accounts"`
+	NextCursor string            `json:"next_cursor,omitempty"`
 }
 
 func (server *Server) listAccounts(ctx *gin.Context) {
@@ -95,15 +141,34 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 		return
 	}
 
+	after, err := server.after(req.pageRequest)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 
-	arg := db.ListAccountsParams{
-		Owner:  authPayload.Username,
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
-	}
+	var accounts []db.Account
+
+	if req.keyset() {
+		arg := db.ListAccountsAfterParams{
+			Owner:          authPayload.Username,
+			AfterCreatedAt: after.CreatedAt,
+			AfterID:        after.ID,
+			Limit:          req.PageSize,
+		}
 
-	accounts, err := server.store.ListAccounts(ctx, arg)
+		accounts, err = server.store.ListAccountsAfter(ctx, arg)
+	} else {
+		arg := db.ListAccountsParams{
+			Owner:  authPayload.Username,
+			Limit:  req.PageSize,
+			Offset: req.offset(),
+		}
+
+		accounts, err = server.store.ListAccounts(ctx, arg)
+	}
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -115,42 +180,205 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	rsp := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		rsp[i], err = server.newAccountResponse(ctx, account)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	if !req.keyset() {
+		ctx.JSON(http.StatusOK, rsp)
+		return
+	}
+
+	var last pageCursor
+	if len(accounts) > 0 {
+		last = pageCursor{CreatedAt: accounts[len(accounts)-1].CreatedAt, ID: accounts[len(accounts)-1].ID}
+	}
+
+	nextCursor, err := server.nextCursor(req.pageRequest, len(accounts), last)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listAccountsResponse{Accounts: rsp, NextCursor: nextCursor})
 }
 
-type updateAccountRequestID struct {
+type accountStatusRequestID struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
-type updateAccountRequestBalance struct {
-	Balance int64 `form:"balance" binding:"required"`
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, util.FrozenAccount)
+}
+
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	server.changeAccountStatus(ctx, util.ActiveAccount)
 }
 
-func (server *Server) updateAccount(ctx *gin.Context) {
-	var reqID updateAccountRequestID
-	var reqBalance updateAccountRequestBalance
+// changeAccountStatus moves the account to the status if the transition is allowed
+func (server *Server) changeAccountStatus(ctx *gin.Context, status string) {
+	var req accountStatusRequestID
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	if !ifMatchAccount(ctx, account) {
+		return
+	}
+
+	if !util.IsAllowedAccountTransition(account.Status, status) {
+		ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountTransition, db.ErrAccountTransition))
+		return
+	}
+
+	arg := db.UpdateAccountStatusParams{
+		Status:     status,
+		ID:         account.ID,
+		FromStatus: account.Status,
+		Version:    account.Version,
+	}
+
+	account, err := server.store.UpdateAccountStatusTx(ctx, arg)
+
+	if err != nil {
+		//status is updated only if nobody has changed the account since it was read
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusPreconditionFailed, errorCodeResponse(errCodeVersionMismatch, db.ErrVersionMismatch))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp, err := server.newAccountResponse(ctx, account)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	setAccountETag(ctx, account)
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type closeAccountRequestSweep struct {
+	SweepToAccountID int64 `json:"sweep_to_account_id" binding:"min=0"`
+}
+
+// closeAccountResponse shows the closed account and the transfer which swept its balance away
+type closeAccountResponse struct {
+	Account accountResponse   `json:"account"`
+	Sweep   *transferResponse `json:"sweep,omitempty"`
+}
+
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var reqID accountStatusRequestID
+	var reqSweep closeAccountRequestSweep
 
 	if err := ctx.ShouldBindUri(&reqID); err != nil {
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	if err := ctx.ShouldBindJSON(&reqBalance); err != nil {
+	//request without body closes an account with zero balance
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&reqSweep); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	if reqSweep.SweepToAccountID == reqID.ID {
+		err := errors.New("account can't be swept into itself")
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
 		return
 	}
 
-	arg := db.UpdateAccountParams{
-		ID:      reqID.ID,
-		Balance: reqBalance.Balance,
+	account, valid := server.validAccount(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
 	}
 
-	account, err := server.store.UpdateAccount(ctx, arg)
+	if !ifMatchAccount(ctx, account) {
+		return
+	}
+
+	//remaining balance can only go to another account of the same owner
+	if reqSweep.SweepToAccountID != 0 {
+		sweepAccount, valid := server.validAccount(ctx, reqSweep.SweepToAccountID)
+		if !valid {
+			return
+		}
+
+		if sweepAccount.Owner != account.Owner {
+			err := errors.New("sweep account doesn't belong to the authenticated user")
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+	}
+
+	arg := db.CloseAccountTxParam{
+		AccountID:        account.ID,
+		SweepToAccountID: reqSweep.SweepToAccountID,
+		Version:          account.Version,
+	}
+
+	closeTx, err := server.store.CloseAccountTx(ctx, arg)
 
 	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrVersionMismatch):
+			ctx.JSON(http.StatusPreconditionFailed, errorCodeResponse(errCodeVersionMismatch, err))
+			return
+		case errors.Is(err, db.ErrAccountTransition):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountTransition, err))
+			return
+		case errors.Is(err, db.ErrAccountHasBalance):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountHasBalance, err))
+			return
+		case errors.Is(err, db.ErrAccountHasHolds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountHasHolds, err))
+			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, err))
+			return
+		case errors.Is(err, db.ErrExchangeRateNotFound):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeExchangeRateNotFound, err))
+			return
+		case errors.Is(err, db.ErrAmountTooSmall), errors.Is(err, util.ErrAmountOverflow):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInvalidAmount, err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	//closed account can't have holds
+	rsp := closeAccountResponse{Account: makeAccountResponse(closeTx.Account, 0)}
+	if closeTx.Sweep != nil {
+		sweep := newTransferResponse(*closeTx.Sweep)
+		rsp.Sweep = &sweep
+	}
+
+	setAccountETag(ctx, closeTx.Account)
+	ctx.JSON(http.StatusOK, rsp)
 }
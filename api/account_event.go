@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+)
+
+// eventStreamHeartbeat is how often idle streams get a comment to keep the connection open, the account is re-checked then too
+const eventStreamHeartbeat = 15 * time.Second
+
+// eventStreamBatchSize is the number of entries read from the database at once
+const eventStreamBatchSize = 100
+
+// Names of events sent by account event streams
+const (
+	entryStreamEvent   = "entry"
+	balanceStreamEvent = "balance"
+)
+
+var errInvalidLastEventID = errors.New("Last-Event-ID header must be the ID of an entry")
+
+// streamAccountEvents streams new entries of the account and its balance after them as Server-Sent Events
+// Entry events carry the entry ID, so a client resuming with Last-Event-ID gets the entries it missed
+// Without the header the stream starts with the current balance and only entries committed afterwards
+func (server *Server) streamAccountEvents(ctx *gin.Context) {
+	var req getAccountRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	//subscribe before reading, so activity committed in between wakes the stream up
+	notifications, unsubscribe := server.broker.Subscribe(account.ID)
+	defer unsubscribe()
+
+	var lastEntryID int64
+
+	if header := strings.TrimSpace(ctx.GetHeader("Last-Event-ID")); header != "" {
+		id, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || id < 0 {
+			ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidLastEventID))
+			return
+		}
+		lastEntryID = id
+	} else {
+		id, err := server.store.GetAccountLastEntryID(ctx, account.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+		lastEntryID = id
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	var lastBalance *accountResponse
+
+	for {
+		var err error
+
+		lastEntryID, lastBalance, err = server.sendAccountEvents(ctx, account.ID, lastEntryID, lastBalance)
+		if err != nil {
+			//the client reconnects with the ID of the last entry it got
+			log.Printf("cannot stream events of account [%d]: %v", account.ID, err)
+			return
+		}
+
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case <-notifications:
+		case <-heartbeat.C:
+			if _, err := io.WriteString(ctx.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+// sendAccountEvents sends entries of the account after the last sent one and then its balance if it changed
+// It returns the ID of the last entry and the balance sent so far
+func (server *Server) sendAccountEvents(ctx *gin.Context, accountID int64, lastEntryID int64, lastBalance *accountResponse) (int64, *accountResponse, error) {
+	for {
+		entries, err := server.store.ListAccountEntriesSince(ctx, db.ListAccountEntriesSinceParams{
+			AccountID: accountID,
+			AfterID:   lastEntryID,
+			Limit:     eventStreamBatchSize,
+		})
+		if err != nil {
+			return lastEntryID, lastBalance, err
+		}
+
+		for _, entry := range entries {
+			if err := writeStreamEvent(ctx.Writer, strconv.FormatInt(entry.ID, 10), entryStreamEvent, entry); err != nil {
+				return lastEntryID, lastBalance, err
+			}
+			lastEntryID = entry.ID
+		}
+
+		if len(entries) < eventStreamBatchSize {
+			break
+		}
+	}
+
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return lastEntryID, lastBalance, err
+	}
+
+	balance, err := server.newAccountResponse(ctx, account)
+	if err != nil {
+		return lastEntryID, lastBalance, err
+	}
+
+	if lastBalance == nil || *lastBalance != balance {
+		//balance events carry no ID, so they do not move the point the client resumes from
+		if err := writeStreamEvent(ctx.Writer, "", balanceStreamEvent, balance); err != nil {
+			return lastEntryID, lastBalance, err
+		}
+		lastBalance = &balance
+	}
+
+	ctx.Writer.Flush()
+	return lastEntryID, lastBalance, nil
+}
+
+// writeStreamEvent writes the event in the Server-Sent Events format with the data as JSON
+func writeStreamEvent(w io.Writer, id string, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
@@ -0,0 +1,342 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// streamEvent is an event read from a Server-Sent Events stream
+type streamEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readStreamEvent reads the next event from the stream, skipping comments
+func readStreamEvent(t *testing.T, reader *bufio.Reader) streamEvent {
+	var event streamEvent
+
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && event.event != "":
+			return event
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func requireEntryEvent(t *testing.T, event streamEvent, entry db.Entry) {
+	require.Equal(t, entryStreamEvent, event.event)
+	require.Equal(t, fmt.Sprint(entry.ID), event.id)
+
+	var gotEntry db.Entry
+	err := json.Unmarshal([]byte(event.data), &gotEntry)
+	require.NoError(t, err)
+	require.Equal(t, entry, gotEntry)
+}
+
+func requireBalanceEvent(t *testing.T, event streamEvent, account db.Account) {
+	require.Equal(t, balanceStreamEvent, event.event)
+	require.Empty(t, event.id)
+
+	var gotBalance accountResponse
+	err := json.Unmarshal([]byte(event.data), &gotBalance)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, gotBalance.ID)
+	require.Equal(t, account.Balance, gotBalance.Balance)
+	require.Equal(t, account.Version, gotBalance.Version)
+	require.Equal(t, account.Balance, gotBalance.AvailableBalance)
+}
+
+// fakeLedger plays the committed state of an account for the stubs of a stream
+type fakeLedger struct {
+	mutex   sync.Mutex
+	account db.Account
+	entries []db.Entry
+}
+
+// commit adds the entry to the ledger and applies it to the balance of the account
+func (ledger *fakeLedger) commit(entry db.Entry) {
+	ledger.mutex.Lock()
+	defer ledger.mutex.Unlock()
+
+	ledger.entries = append(ledger.entries, entry)
+	ledger.account.Balance += entry.Amount
+	ledger.account.Version++
+}
+
+func (ledger *fakeLedger) buildStubs(store *mockdb.MockStore) {
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(ledger.account.ID)).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, id int64) (db.Account, error) {
+			ledger.mutex.Lock()
+			defer ledger.mutex.Unlock()
+			return ledger.account, nil
+		})
+
+	store.EXPECT().
+		ListAccountEntriesSince(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(ctx context.Context, arg db.ListAccountEntriesSinceParams) ([]db.Entry, error) {
+			ledger.mutex.Lock()
+			defer ledger.mutex.Unlock()
+
+			entries := []db.Entry{}
+			for _, entry := range ledger.entries {
+				if entry.AccountID == arg.AccountID && entry.ID > arg.AfterID && len(entries) < int(arg.Limit) {
+					entries = append(entries, entry)
+				}
+			}
+			return entries, nil
+		})
+
+	store.EXPECT().GetHeldAmount(gomock.Any(), gomock.Eq(ledger.account.ID)).AnyTimes().Return(int64(0), nil)
+}
+
+func randomEntry(accountID int64, id int64) db.Entry {
+	return db.Entry{
+		ID:         id,
+		AccountID:  accountID,
+		Amount:     util.RandomMoney(),
+		CreatedAt:  time.Now().UTC().Truncate(time.Second),
+		TransferID: sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true},
+	}
+}
+
+// openAccountStream starts the server and opens the event stream of the account
+func openAccountStream(t *testing.T, server *Server, accountID int64, username string, lastEventID string) *bufio.Reader {
+	httpServer := httptest.NewServer(server.router)
+	t.Cleanup(httpServer.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	url := fmt.Sprintf("%s/accounts/%d/events", httpServer.URL, accountID)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	if lastEventID != "" {
+		request.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	t.Cleanup(func() { response.Body.Close() })
+
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	return bufio.NewReader(response.Body)
+}
+
+func TestStreamAccountEventsAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	account := createRandomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	ledger := &fakeLedger{account: account, entries: []db.Entry{randomEntry(account.ID, 10)}}
+	ledger.buildStubs(store)
+
+	store.EXPECT().GetAccountLastEntryID(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(int64(10), nil)
+
+	server := newTestServer(t, store)
+	stream := openAccountStream(t, server, account.ID, user.Username, "")
+
+	//entries committed before the stream opened are not sent
+	requireBalanceEvent(t, readStreamEvent(t, stream), account)
+
+	entry := randomEntry(account.ID, 11)
+	ledger.commit(entry)
+	server.broker.Notify(account.ID)
+
+	requireEntryEvent(t, readStreamEvent(t, stream), entry)
+	requireBalanceEvent(t, readStreamEvent(t, stream), ledger.account)
+}
+
+func TestResumeAccountEventsAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	account := createRandomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	entries := []db.Entry{randomEntry(account.ID, 5), randomEntry(account.ID, 6), randomEntry(account.ID, 7)}
+
+	ledger := &fakeLedger{account: account, entries: entries}
+	ledger.buildStubs(store)
+
+	store.EXPECT().GetAccountLastEntryID(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	stream := openAccountStream(t, server, account.ID, user.Username, "5")
+
+	requireEntryEvent(t, readStreamEvent(t, stream), entries[1])
+	requireEntryEvent(t, readStreamEvent(t, stream), entries[2])
+	requireBalanceEvent(t, readStreamEvent(t, stream), account)
+}
+
+func TestStreamAccountEventsRejectedAPI(t *testing.T) {
+	user1, _ := createRandomUser(t)
+	user2, _ := createRandomUser(t)
+	account := createRandomAccount(user1.Username)
+
+	testCases := []struct {
+		name          string
+		lastEventID   string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "UnauthorizedUser",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntriesSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:        "InvalidLastEventID",
+			lastEventID: "entry-5",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().ListAccountEntriesSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountLastEntryID(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/events", account.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			if tc.lastEventID != "" {
+				request.Header.Set("Last-Event-ID", tc.lastEventID)
+			}
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAccountBroker(t *testing.T) {
+	broker := NewAccountBroker()
+
+	notifications1, unsubscribe1 := broker.Subscribe(1)
+	notifications2, unsubscribe2 := broker.Subscribe(2)
+	defer unsubscribe2()
+
+	//notifications not received yet are merged
+	broker.Notify(1)
+	broker.Notify(1)
+
+	require.Len(t, notifications1, 1)
+	require.Len(t, notifications2, 0)
+	<-notifications1
+
+	broker.NotifyAll()
+	require.Len(t, notifications1, 1)
+	require.Len(t, notifications2, 1)
+	<-notifications1
+	<-notifications2
+
+	unsubscribe1()
+	broker.Notify(1)
+	require.Len(t, notifications1, 0)
+	require.NotContains(t, broker.subscribers, int64(1))
+}
@@ -26,6 +26,8 @@ func createRandomAccount(owner string) db.Account {
 		Owner:    owner,
 		Balance:  util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		Status:   util.ActiveAccount,
+		Version:  util.RandomInt(1, 100),
 	}
 }
 
@@ -41,6 +43,21 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	require.Equal(t, account, gotAccount)
 }
 
+func requireBodyMatchAvailableBalance(t *testing.T, body *bytes.Buffer, account db.Account, availableBalance int64) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotAccount accountResponse
+
+	err = json.Unmarshal(data, &gotAccount)
+	require.NoError(t, err)
+
+	require.Equal(t, account, gotAccount.Account)
+	require.Equal(t, availableBalance, gotAccount.AvailableBalance)
+	require.Equal(t, decimal(account.Balance, account.Currency), gotAccount.BalanceDecimal)
+	require.Equal(t, decimal(availableBalance, account.Currency), gotAccount.AvailableBalanceDecimal)
+}
+
 func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Account) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
@@ -56,6 +73,7 @@ func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Ac
 func TestGetAccountAPI(t *testing.T) {
 	user, _ := createRandomUser(t)
 	account := createRandomAccount(user.Username)
+	held := account.Balance / 2
 
 	testCases := []struct {
 		name          string
@@ -68,24 +86,51 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "OK",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
 					Return(account, nil)
+
+				store.EXPECT().
+					GetHeldAmount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(held, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, account)
+				require.Equal(t, accountETag(account), recorder.Header().Get("ETag"))
+				requireBodyMatchAvailableBalance(t, recorder.Body, account, account.Balance-held)
+			},
+		},
+		{
+			name:      "HeldAmountError",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+
+				store.EXPECT().
+					GetHeldAmount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
 			name:      "UnauthorizedUser",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -115,7 +160,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "NotFound",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -131,7 +176,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "InternalError",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -147,7 +192,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "InvalidID",
 			accountID: 0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -204,7 +249,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": account.Currency,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				arg := db.CreateAccountParams{
@@ -214,7 +259,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				}
 
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Eq(arg)).
+					CreateAccountTx(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(account, nil)
 			},
@@ -230,11 +275,11 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": account.Currency,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTx(gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(db.Account{}, sql.ErrConnDone)
 			},
@@ -249,11 +294,11 @@ func TestCreateAccountAPI(t *testing.T) {
 				"currency": "RUR",
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					CreateAccount(gomock.Any(), gomock.Any()).
+					CreateAccountTx(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -286,62 +331,79 @@ func TestCreateAccountAPI(t *testing.T) {
 			require.NoError(t, err)
 
 			tc.setupAuth(t, request, server.tokenMaker)
+			checkAudit := expectAuditLog(t, store, "/accounts")
 			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
 			tc.checkResponse(t, recorder)
 		})
 	}
 }
 
-func TestUpdateAccountAPI(t *testing.T) {
+func TestListAccountsAPI(t *testing.T) {
+	n := 5
+	accounts := make([]db.Account, n)
+
 	user, _ := createRandomUser(t)
-	account := createRandomAccount(user.Username)
-	newBalance := 15
+
+	for i := 0; i < n; i++ {
+		accounts[i] = createRandomAccount(user.Username)
+	}
+
+	type Query struct {
+		pageID   int
+		pageSize int
+	}
 
 	testCases := []struct {
 		name          string
-		body          gin.H
-		AccountID     int64
+		query         Query
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
-			body: gin.H{
-				"balance": newBalance,
+			query: Query{
+				pageID:   1,
+				pageSize: n,
 			},
-			AccountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.UpdateAccountParams{
-					ID:      account.ID,
-					Balance: int64(newBalance),
+				arg := db.ListAccountsParams{
+					Owner:  user.Username,
+					Limit:  int32(n),
+					Offset: 0,
 				}
 
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Eq(arg)).
+					ListAccounts(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
-					Return(account, nil)
+					Return(accounts, nil)
+
+				store.EXPECT().
+					GetHeldAmount(gomock.Any(), gomock.Any()).
+					Times(n).
+					Return(int64(0), nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccount(t, recorder.Body, account)
+				requireBodyMatchAccounts(t, recorder.Body, accounts)
 			},
 		},
 		{
-			name: "InvalidID",
-			body: gin.H{
-				"balance": account.Balance,
+			name: "InvalidPageID",
+			query: Query{
+				pageID:   0,
+				pageSize: n,
 			},
-			AccountID: 0,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Any()).
+					ListAccounts(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -349,17 +411,17 @@ func TestUpdateAccountAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "InvalidBalance",
-			body: gin.H{
-				"balance": "balance",
+			name: "InvalidPageSize",
+			query: Query{
+				pageID:   1,
+				pageSize: -n,
 			},
-			AccountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Any()).
+					ListAccounts(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
@@ -367,24 +429,43 @@ func TestUpdateAccountAPI(t *testing.T) {
 			},
 		},
 		{
-			name: "InnternalError",
-			body: gin.H{
-				"balance": newBalance,
+			name: "InternalError",
+			query: Query{
+				pageID:   1,
+				pageSize: n,
 			},
-			AccountID: account.ID,
 			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
-					UpdateAccount(gomock.Any(), gomock.Any()).
+					ListAccounts(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return(db.Account{}, sql.ErrConnDone)
+					Return([]db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "NotFound",
+			query: Query{
+				pageID:   1,
+				pageSize: n,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAccounts(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -399,17 +480,19 @@ func TestUpdateAccountAPI(t *testing.T) {
 			//build stubs
 			tc.buildStubs(store)
 
-			//marshal body data to JSON
-			data, err := json.Marshal(tc.body)
-			require.NoError(t, err)
-
 			//start HTTP server and build request
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
-			url := fmt.Sprintf("/accounts/%d", tc.AccountID)
-			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			url := "/accounts"
+			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			//add query parameters to request URL
+			q := request.URL.Query()
+			q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
+			q.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
+			request.URL.RawQuery = q.Encode()
+
 			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
@@ -417,7 +500,7 @@ func TestUpdateAccountAPI(t *testing.T) {
 	}
 }
 
-func TestListAccountsAPI(t *testing.T) {
+func TestListAccountsKeysetAPI(t *testing.T) {
 	n := 5
 	accounts := make([]db.Account, n)
 
@@ -425,118 +508,418 @@ func TestListAccountsAPI(t *testing.T) {
 
 	for i := 0; i < n; i++ {
 		accounts[i] = createRandomAccount(user.Username)
+		accounts[i].CreatedAt = time.Date(2024, time.January, i+1, 0, 0, 0, 0, time.UTC)
 	}
 
-	type Query struct {
-		pageID   int
-		pageSize int
-	}
+	after := pageCursor{CreatedAt: time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC), ID: 7}
+	last := pageCursor{CreatedAt: accounts[n-1].CreatedAt, ID: accounts[n-1].ID}
 
 	testCases := []struct {
 		name          string
-		query         Query
-		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		cursor        func(server *Server) string
 		buildStubs    func(store *mockdb.MockStore)
-		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
 	}{
 		{
-			name: "OK",
-			query: Query{
-				pageID:   1,
-				pageSize: n,
-			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			name: "FirstPage",
+			cursor: func(server *Server) string {
+				return ""
 			},
 			buildStubs: func(store *mockdb.MockStore) {
-				arg := db.ListAccountsParams{
-					Owner:  user.Username,
-					Limit:  int32(n),
-					Offset: 0,
+				arg := db.ListAccountsAfterParams{
+					Owner: user.Username,
+					Limit: int32(n),
 				}
 
 				store.EXPECT().
-					ListAccounts(gomock.Any(), gomock.Eq(arg)).
+					ListAccountsAfter(gomock.Any(), gomock.Eq(arg)).
 					Times(1).
 					Return(accounts, nil)
+
+				store.EXPECT().
+					GetHeldAmount(gomock.Any(), gomock.Any()).
+					Times(n).
+					Return(int64(0), nil)
 			},
-			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
-				requireBodyMatchAccounts(t, recorder.Body, accounts)
+
+				var rsp listAccountsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Len(t, rsp.Accounts, n)
+
+				next, err := server.decodeCursor(rsp.NextCursor)
+				require.NoError(t, err)
+				require.Equal(t, last, next)
 			},
 		},
 		{
-			name: "InvalidPageID",
-			query: Query{
-				pageID:   0,
-				pageSize: n,
+			name: "LastPage",
+			cursor: func(server *Server) string {
+				cursor, err := server.encodeCursor(after)
+				require.NoError(t, err)
+				return cursor
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsAfterParams{
+					Owner:          user.Username,
+					AfterCreatedAt: after.CreatedAt,
+					AfterID:        after.ID,
+					Limit:          int32(n),
+				}
+
+				store.EXPECT().
+					ListAccountsAfter(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(accounts[:1], nil)
+
+				store.EXPECT().
+					GetHeldAmount(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listAccountsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Len(t, rsp.Accounts, 1)
+				require.Empty(t, rsp.NextCursor)
+			},
+		},
+		{
+			name: "InvalidCursor",
+			cursor: func(server *Server) string {
+				return "forged.cursor"
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountsAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := "/accounts"
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			//add query parameters to request URL
+			q := request.URL.Query()
+			q.Add("page_size", fmt.Sprintf("%d", n))
+			if cursor := tc.cursor(server); cursor != "" {
+				q.Add("cursor", cursor)
+			}
+			request.URL.RawQuery = q.Encode()
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+func TestFreezeAccountAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	admin, _ := createRandomUser(t)
+
+	account := createRandomAccount(user.Username)
+
+	frozenAccount := account
+	frozenAccount.Status = util.FrozenAccount
+	frozenAccount.Version = account.Version + 1
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			ifMatch: accountETag(account),
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.UpdateAccountStatusParams{
+					Status:     util.FrozenAccount,
+					ID:         account.ID,
+					FromStatus: util.ActiveAccount,
+					Version:    account.Version,
+				}
+
 				store.EXPECT().
-					ListAccounts(gomock.Any(), gomock.Any()).
-					Times(0)
+					UpdateAccountStatusTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(frozenAccount, nil)
+
+				store.EXPECT().
+					GetHeldAmount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(int64(0), nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, accountETag(frozenAccount), recorder.Header().Get("ETag"))
+				requireBodyMatchAccount(t, recorder.Body, frozenAccount)
 			},
 		},
 		{
-			name: "InvalidPageSize",
-			query: Query{
-				pageID:   1,
-				pageSize: -n,
+			name: "MissingIfMatch",
+			role: util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:    "StaleETag",
+			ifMatch: accountETag(account),
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(frozenAccount, nil)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeVersionMismatch)
+			},
+		},
+		{
+			name:    "NotAdmin",
+			ifMatch: accountETag(account),
+			role:    util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
+		},
+		{
+			name:    "AlreadyFrozen",
+			ifMatch: accountETag(frozenAccount),
+			role:    util.AdminRole,
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(frozenAccount, nil)
+				store.EXPECT().UpdateAccountStatusTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeAccountTransition)
+			},
+		},
+		{
+			name:    "ConcurrentChange",
+			ifMatch: accountETag(account),
+			role:    util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
 				store.EXPECT().
-					ListAccounts(gomock.Any(), gomock.Any()).
-					Times(0)
+					UpdateAccountStatusTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.Account{}, sql.ErrNoRows)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeVersionMismatch)
 			},
 		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/accounts/%d/freeze", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			if tc.ifMatch != "" {
+				request.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, tc.role, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/accounts/:id/freeze")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCloseAccountAPI(t *testing.T) {
+	user1, _ := createRandomUser(t)
+	user2, _ := createRandomUser(t)
+
+	account := createRandomAccount(user1.Username)
+	sweepAccount := createRandomAccount(user1.Username)
+	otherAccount := createRandomAccount(user2.Username)
+
+	closedAccount := account
+	closedAccount.Status = util.ClosedAccount
+
+	testCases := []struct {
+		name          string
+		ifMatch       string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
 		{
-			name: "InternalError",
-			query: Query{
-				pageID:   1,
-				pageSize: n,
+			name:    "OK",
+			ifMatch: accountETag(account),
+			body: gin.H{
+				"sweep_to_account_id": sweepAccount.ID,
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sweepAccount.ID)).Times(1).Return(sweepAccount, nil)
+
+				arg := db.CloseAccountTxParam{
+					AccountID:        account.ID,
+					SweepToAccountID: sweepAccount.ID,
+					Version:          account.Version,
+				}
+
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CloseAccountTxResult{Account: closedAccount}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp db.CloseAccountTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, closedAccount, rsp.Account)
+				require.Equal(t, accountETag(closedAccount), recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
 			},
+		},
+		{
+			name:    "ConcurrentChange",
+			ifMatch: accountETag(account),
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
 				store.EXPECT().
-					ListAccounts(gomock.Any(), gomock.Any()).
+					CloseAccountTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return([]db.Account{}, sql.ErrConnDone)
+					Return(db.CloseAccountTxResult{}, db.ErrVersionMismatch)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeVersionMismatch)
 			},
 		},
 		{
-			name: "NotFound",
-			query: Query{
-				pageID:   1,
-				pageSize: n,
+			name:    "HasBalance",
+			ifMatch: accountETag(account),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.CloseAccountTxParam{
+					AccountID: account.ID,
+					Version:   account.Version,
+				}
+
+				store.EXPECT().
+					CloseAccountTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.CloseAccountTxResult{}, db.ErrAccountHasBalance)
 			},
-			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeAccountHasBalance)
+			},
+		},
+		{
+			name:    "SweepToOtherOwner",
+			ifMatch: accountETag(account),
+			body: gin.H{
+				"sweep_to_account_id": otherAccount.ID,
 			},
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:    "SweepToItself",
+			ifMatch: accountETag(account),
+			body: gin.H{
+				"sweep_to_account_id": account.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "AlreadyClosed",
+			ifMatch: accountETag(account),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(closedAccount, nil)
 				store.EXPECT().
-					ListAccounts(gomock.Any(), gomock.Any()).
+					CloseAccountTx(gomock.Any(), gomock.Any()).
 					Times(1).
-					Return([]db.Account{}, sql.ErrNoRows)
+					Return(db.CloseAccountTxResult{}, db.ErrAccountTransition)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeAccountTransition)
 			},
 		},
 	}
@@ -556,18 +939,26 @@ func TestListAccountsAPI(t *testing.T) {
 			//start HTTP server and build request
 			server := newTestServer(t, store)
 			recorder := httptest.NewRecorder()
-			url := "/accounts"
-			request, err := http.NewRequest(http.MethodGet, url, nil)
+			url := fmt.Sprintf("/accounts/%d/close", account.ID)
+
+			var body io.Reader
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = bytes.NewReader(data)
+			}
+
+			request, err := http.NewRequest(http.MethodPost, url, body)
 			require.NoError(t, err)
 
-			//add query parameters to request URL
-			q := request.URL.Query()
-			q.Add("page_id", fmt.Sprintf("%d", tc.query.pageID))
-			q.Add("page_size", fmt.Sprintf("%d", tc.query.pageSize))
-			request.URL.RawQuery = q.Encode()
+			if tc.ifMatch != "" {
+				request.Header.Set("If-Match", tc.ifMatch)
+			}
 
-			tc.setupAuth(t, request, server.tokenMaker)
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, util.DepositorRole, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/accounts/:id/close")
 			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
 			tc.checkResponse(t, recorder)
 		})
 	}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+type adjustmentRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// createAdjustmentRequest takes the signed amount either in minor units or as a decimal string in major units
+type createAdjustmentRequest struct {
+	Amount        int64  `json:"amount"`
+	AmountDecimal string `json:"amount_decimal"`
+	Reason        string `json:"reason" binding:"required,adjustment_reason"`
+	Reference     string `json:"reference" binding:"required,max=255"`
+}
+
+// adjustmentResponse is an adjustment with its amount as a decimal string in major units of the account currency
+type adjustmentResponse struct {
+	db.Adjustment
+	AmountDecimal string `json:"amount_decimal"`
+}
+
+// adjustBalanceResponse shows the adjustment together with the amount and the new balance as decimal strings
+type adjustBalanceResponse struct {
+	db.AdjustBalanceTxResult
+	AmountDecimal  string `json:"amount_decimal"`
+	BalanceDecimal string `json:"balance_decimal"`
+}
+
+// createAdjustment adds the amount to the balance of the account on behalf of the admin
+// The opposite entry is booked to the suspense account, so the ledger stays balanced
+func (server *Server) createAdjustment(ctx *gin.Context) {
+	var reqID adjustmentRequestID
+	var req createAdjustmentRequest
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Amount == 0 && req.AmountDecimal == "" {
+		err := errors.New("amount or amount_decimal is required")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	amount, err := optionalRequestAmount(req.Amount, req.AmountDecimal, account.Currency)
+	if err == nil && amount == 0 {
+		err = errors.New("amount must not be zero")
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !ifMatchAccount(ctx, account) {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.AdjustBalanceTxParam{
+		AccountID:  account.ID,
+		Amount:     amount,
+		Reason:     req.Reason,
+		Reference:  req.Reference,
+		AdjustedBy: authPayload.Username,
+		Version:    account.Version,
+	}
+
+	adjustTx, err := server.store.AdjustBalanceTx(ctx, arg)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrVersionMismatch):
+			ctx.JSON(http.StatusPreconditionFailed, errorCodeResponse(errCodeVersionMismatch, err))
+			return
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, err))
+			return
+		case errors.Is(err, db.ErrSuspenseAdjustment):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeSuspenseAdjustment, err))
+			return
+		case errors.Is(err, util.ErrAmountOverflow):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInvalidAmount, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	setAccountETag(ctx, adjustTx.Account)
+	ctx.JSON(http.StatusOK, adjustBalanceResponse{
+		AdjustBalanceTxResult: adjustTx,
+		AmountDecimal:         decimal(adjustTx.Adjustment.Amount, account.Currency),
+		BalanceDecimal:        decimal(adjustTx.Account.Balance, account.Currency),
+	})
+}
+
+type listAdjustmentsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listAdjustments(ctx *gin.Context) {
+	var reqID adjustmentRequestID
+	var req listAdjustmentsRequest
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	arg := db.ListAccountAdjustmentsParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	}
+
+	adjustments, err := server.store.ListAccountAdjustments(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]adjustmentResponse, len(adjustments))
+	for i, adjustment := range adjustments {
+		rsp[i] = adjustmentResponse{
+			Adjustment:    adjustment,
+			AmountDecimal: decimal(adjustment.Amount, account.Currency),
+		}
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
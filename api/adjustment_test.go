@@ -0,0 +1,394 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAdjustmentAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	admin, _ := createRandomUser(t)
+
+	account := createRandomAccount(user.Username)
+
+	adjustedAccount := account
+	adjustedAccount.Balance += 100
+	adjustedAccount.Version++
+
+	adjustment := db.Adjustment{
+		ID:         util.RandomInt(1, 1000),
+		AccountID:  account.ID,
+		Amount:     100,
+		Reason:     util.ChargebackAdjustment,
+		Reference:  "CASE-42",
+		AdjustedBy: admin.Username,
+	}
+
+	body := gin.H{
+		"amount":    100,
+		"reason":    util.ChargebackAdjustment,
+		"reference": "CASE-42",
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		ifMatch       string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:    "OK",
+			role:    util.AdminRole,
+			ifMatch: accountETag(account),
+			body:    body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.AdjustBalanceTxParam{
+					AccountID:  account.ID,
+					Amount:     100,
+					Reason:     util.ChargebackAdjustment,
+					Reference:  "CASE-42",
+					AdjustedBy: admin.Username,
+					Version:    account.Version,
+				}
+
+				store.EXPECT().
+					AdjustBalanceTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.AdjustBalanceTxResult{Adjustment: adjustment, Account: adjustedAccount}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, accountETag(adjustedAccount), recorder.Header().Get("ETag"))
+
+				var rsp db.AdjustBalanceTxResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, adjustment, rsp.Adjustment)
+				require.Equal(t, adjustedAccount, rsp.Account)
+			},
+		},
+		{
+			name:    "AmountDecimal",
+			role:    util.AdminRole,
+			ifMatch: accountETag(account),
+			body: gin.H{
+				"amount_decimal": "-1.50",
+				"reason":         util.ChargebackAdjustment,
+				"reference":      "CASE-42",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				debitedAccount := account
+				debitedAccount.Balance -= 150
+
+				store.EXPECT().
+					AdjustBalanceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.AdjustBalanceTxParam) (db.AdjustBalanceTxResult, error) {
+						require.Equal(t, int64(-150), arg.Amount)
+
+						debit := adjustment
+						debit.Amount = arg.Amount
+						return db.AdjustBalanceTxResult{Adjustment: debit, Account: debitedAccount}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp adjustBalanceResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, "-1.50", rsp.AmountDecimal)
+				require.Equal(t, decimal(account.Balance-150, account.Currency), rsp.BalanceDecimal)
+			},
+		},
+		{
+			name:    "NotAdmin",
+			role:    util.DepositorRole,
+			ifMatch: accountETag(account),
+			body:    body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:    "MissingReference",
+			role:    util.AdminRole,
+			ifMatch: accountETag(account),
+			body: gin.H{
+				"amount": 100,
+				"reason": util.ChargebackAdjustment,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "InvalidReason",
+			role:    util.AdminRole,
+			ifMatch: accountETag(account),
+			body: gin.H{
+				"amount":    100,
+				"reason":    "because",
+				"reference": "CASE-42",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:    "ZeroAmount",
+			role:    util.AdminRole,
+			ifMatch: accountETag(account),
+			body: gin.H{
+				"amount":    0,
+				"reason":    util.CorrectionAdjustment,
+				"reference": "CASE-42",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MissingIfMatch",
+			role: util.AdminRole,
+			body: body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:    "ConcurrentChange",
+			role:    util.AdminRole,
+			ifMatch: accountETag(account),
+			body:    body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					AdjustBalanceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdjustBalanceTxResult{}, db.ErrVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeVersionMismatch)
+			},
+		},
+		{
+			name:    "InsufficientFunds",
+			role:    util.AdminRole,
+			ifMatch: accountETag(account),
+			body: gin.H{
+				"amount":    -account.Balance - 1,
+				"reason":    util.WriteOffAdjustment,
+				"reference": "CASE-42",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					AdjustBalanceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdjustBalanceTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeInsufficientFunds)
+			},
+		},
+		{
+			name:    "AccountNotFound",
+			role:    util.AdminRole,
+			ifMatch: accountETag(account),
+			body:    body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().AdjustBalanceTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:    "InternalError",
+			role:    util.AdminRole,
+			ifMatch: accountETag(account),
+			body:    body,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					AdjustBalanceTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AdjustBalanceTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/adjustments", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			if tc.ifMatch != "" {
+				request.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, tc.role, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/accounts/:id/adjustments")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAdjustmentsAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	admin, _ := createRandomUser(t)
+
+	account := createRandomAccount(user.Username)
+
+	adjustments := []db.Adjustment{
+		{ID: 2, AccountID: account.ID, Amount: -10, Reason: util.WriteOffAdjustment, Reference: "CASE-2", AdjustedBy: admin.Username},
+		{ID: 1, AccountID: account.ID, Amount: 10, Reason: util.GoodwillAdjustment, Reference: "CASE-1", AdjustedBy: admin.Username},
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			role:  util.AdminRole,
+			query: "page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.ListAccountAdjustmentsParams{
+					AccountID: account.ID,
+					Limit:     5,
+					Offset:    5,
+				}
+
+				store.EXPECT().
+					ListAccountAdjustments(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(adjustments, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []db.Adjustment
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Equal(t, adjustments, rsp)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			role:  util.DepositorRole,
+			query: "page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountAdjustments(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPageSize",
+			role:  util.AdminRole,
+			query: "page_id=1&page_size=50",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccountAdjustments(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/adjustments?%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+)
+
+// maxAuditBodySize is the largest request body written to the audit log, larger bodies are replaced by a marker
+const maxAuditBodySize = 64 << 10
+
+// auditWriteTimeout bounds writing of an audit log row, which doesn't depend on the client still waiting for the response
+const auditWriteTimeout = 5 * time.Second
+
+// Markers written to the audit log instead of values which must not or can't be recorded
+const (
+	auditRedacted = "[REDACTED]"
+	auditTooLarge = "[TOO LARGE]"
+)
+
+// sensitiveFields are parts of names of body fields which values are never written to the audit log
+var sensitiveFields = []string{"password", "secret", "token", "authorization"}
+
+// isMutatingMethod returns true for methods of requests which may change state
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// auditResourceID returns the path parameters of the request joined by slashes, like 42 or USD/EUR
+func auditResourceID(ctx *gin.Context) string {
+	values := make([]string, len(ctx.Params))
+	for i, param := range ctx.Params {
+		values[i] = param.Value
+	}
+
+	return strings.Join(values, "/")
+}
+
+// redactBody returns the JSON body with values of sensitive fields replaced
+// A body which is not JSON may hide a secret anywhere, so it is replaced as a whole
+func redactBody(body []byte) json.RawMessage {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return json.RawMessage("null")
+	}
+
+	if len(body) > maxAuditBodySize {
+		return auditMarker(auditTooLarge)
+	}
+
+	var value interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&value); err != nil {
+		return auditMarker(auditRedacted)
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return auditMarker(auditRedacted)
+	}
+
+	return redacted
+}
+
+// redactValue replaces values of sensitive fields in the decoded JSON value and all values nested in it
+func redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if isSensitiveField(key) {
+				v[key] = auditRedacted
+				continue
+			}
+			v[key] = redactValue(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i])
+		}
+	}
+
+	return value
+}
+
+// isSensitiveField returns true if the name of the field contains any of the sensitive parts
+func isSensitiveField(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range sensitiveFields {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// auditMarker returns the marker as a JSON string
+func auditMarker(marker string) json.RawMessage {
+	return json.RawMessage(`"` + marker + `"`)
+}
+
+type listAuditLogsRequest struct {
+	Username   string    `form:"username"`
+	Method     string    `form:"method" binding:"omitempty,oneof=POST PUT PATCH DELETE"`
+	Route      string    `form:"route"`
+	ResourceID string    `form:"resource_id"`
+	Status     int32     `form:"status" binding:"omitempty,min=100,max=599"`
+	From       time.Time `form:"from"`
+	To         time.Time `form:"to"`
+	pageRequest
+}
+
+// listAuditLogsResponse is returned in keyset mode, page mode returns the bare list
+type listAuditLogsResponse struct {
+	AuditLogs  []db.AuditLog `json:"audit_logs"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// listAuditLogs returns audit log rows matching all the filters, newest first
+func (server *Server) listAuditLogs(ctx *gin.Context) {
+	var req listAuditLogsRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && !req.To.After(req.From) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errors.New("to must be after from")))
+		return
+	}
+
+	before, err := server.after(req.pageRequest)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListAuditLogsParams{
+		Username:        nullString(req.Username),
+		Method:          nullString(req.Method),
+		Route:           nullString(req.Route),
+		ResourceID:      nullString(req.ResourceID),
+		Status:          nullInt32(req.Status),
+		CreatedFrom:     nullTime(req.From),
+		CreatedTo:       nullTime(req.To),
+		BeforeCreatedAt: nullTime(before.CreatedAt),
+		BeforeID:        nullInt64(before.ID),
+		Limit:           req.PageSize,
+		Offset:          req.offset(),
+	}
+
+	auditLogs, err := server.store.ListAuditLogs(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if !req.keyset() {
+		ctx.JSON(http.StatusOK, auditLogs)
+		return
+	}
+
+	var last pageCursor
+	if len(auditLogs) > 0 {
+		last = pageCursor{CreatedAt: auditLogs[len(auditLogs)-1].CreatedAt, ID: auditLogs[len(auditLogs)-1].ID}
+	}
+
+	nextCursor, err := server.nextCursor(req.pageRequest, len(auditLogs), last)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listAuditLogsResponse{AuditLogs: auditLogs, NextCursor: nextCursor})
+}
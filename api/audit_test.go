@@ -0,0 +1,502 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditMiddleware(t *testing.T) {
+	username := util.RandomOwner()
+	userAgent := "simplebank-test/1.0"
+
+	testCases := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		setupAuth  func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs func(store *mockdb.MockStore)
+	}{
+		{
+			name:   "OK",
+			method: http.MethodPost,
+			url:    "/items/42",
+			body:   `{"amount":10,"currency":"USD"}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAuditLogParams{
+					Username:    username,
+					Method:      http.MethodPost,
+					Route:       "/items/:id",
+					ResourceID:  "42",
+					ClientIp:    "192.0.2.1",
+					UserAgent:   userAgent,
+					Status:      http.StatusCreated,
+					RequestBody: json.RawMessage(`{"amount":10,"currency":"USD"}`),
+				}
+
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+		},
+		{
+			name:   "RedactedBody",
+			method: http.MethodPost,
+			url:    "/users",
+			body:   `{"username":"alice","password":"secret123","profile":{"api_token":"abc"},"items":[{"Secret":"x"}]}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAuditLogParams{
+					Method:      http.MethodPost,
+					Route:       "/users",
+					ClientIp:    "192.0.2.1",
+					UserAgent:   userAgent,
+					Status:      http.StatusCreated,
+					RequestBody: json.RawMessage(`{"items":[{"Secret":"[REDACTED]"}],"password":"[REDACTED]","profile":{"api_token":"[REDACTED]"},"username":"alice"}`),
+				}
+
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+		},
+		{
+			name:   "NotJSONBody",
+			method: http.MethodPost,
+			url:    "/users",
+			body:   "username=alice&password=secret123",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Do(func(_ interface{}, arg db.CreateAuditLogParams) {
+						require.JSONEq(t, `"[REDACTED]"`, string(arg.RequestBody))
+					})
+			},
+		},
+		{
+			name:   "Unauthorized",
+			method: http.MethodDelete,
+			url:    "/items/42",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateAuditLogParams{
+					Method:      http.MethodDelete,
+					Route:       "/items/:id",
+					ResourceID:  "42",
+					ClientIp:    "192.0.2.1",
+					UserAgent:   userAgent,
+					Status:      http.StatusUnauthorized,
+					RequestBody: json.RawMessage("null"),
+				}
+
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Eq(arg)).Times(1)
+			},
+		},
+		{
+			name:   "NotMutating",
+			method: http.MethodGet,
+			url:    "/items/42",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:   "LargeBody",
+			method: http.MethodPost,
+			url:    "/items/42",
+			body:   fmt.Sprintf(`{"note":"%s"}`, util.RandomString(maxAuditBodySize)),
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Do(func(_ interface{}, arg db.CreateAuditLogParams) {
+						require.Equal(t, int32(http.StatusCreated), arg.Status)
+						require.JSONEq(t, `"[TOO LARGE]"`, string(arg.RequestBody))
+					})
+			},
+		},
+		{
+			name:   "StoreError",
+			method: http.MethodPost,
+			url:    "/items/42",
+			body:   `{}`,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					CreateAuditLog(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AuditLog{}, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			tokenMaker, err := token.NewPasetoMaker(util.RandomString(32))
+			require.NoError(t, err)
+
+			// handlers read the body, so the middleware must hand it over untouched
+			handler := func(ctx *gin.Context) {
+				var body json.RawMessage
+				if ctx.Request.ContentLength > 0 {
+					require.NoError(t, ctx.ShouldBindJSON(&body))
+				}
+				ctx.JSON(http.StatusCreated, body)
+			}
+
+			router := gin.New()
+			router.Use(auditMiddleware(store))
+			router.POST("/users", func(ctx *gin.Context) {
+				ctx.JSON(http.StatusCreated, gin.H{})
+			})
+			authRoutes := router.Group("/").Use(authMiddleware(tokenMaker))
+			authRoutes.GET("/items/:id", handler)
+			authRoutes.POST("/items/:id", handler)
+			authRoutes.DELETE("/items/:id", handler)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			request.RemoteAddr = "192.0.2.1:1234"
+			request.Header.Set("User-Agent", userAgent)
+
+			tc.setupAuth(t, request, tokenMaker)
+			router.ServeHTTP(recorder, request)
+
+			if tc.body != "" && tc.url != "/users" {
+				require.JSONEq(t, tc.body, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuditMiddlewareCanceledRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().
+		CreateAuditLog(gomock.Any(), gomock.Any()).
+		Times(1).
+		Do(func(ctx context.Context, arg db.CreateAuditLogParams) {
+			require.NoError(t, ctx.Err())
+			require.Equal(t, int32(http.StatusCreated), arg.Status)
+		})
+
+	requestCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	router := gin.New()
+	router.Use(auditMiddleware(store))
+	router.POST("/users", func(ctx *gin.Context) {
+		//the client goes away after the change is committed
+		ctx.JSON(http.StatusCreated, gin.H{})
+		cancel()
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequestWithContext(requestCtx, http.MethodPost, "/users", bytes.NewBufferString(`{}`))
+	require.NoError(t, err)
+
+	router.ServeHTTP(recorder, request)
+	require.Error(t, requestCtx.Err())
+}
+
+func TestAuditMiddlewareBodyReadError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateAuditLog(gomock.Any(), gomock.Any()).Times(0)
+
+	router := gin.New()
+	router.Use(auditMiddleware(store))
+	router.POST("/users", func(ctx *gin.Context) {
+		require.Fail(t, "handler must not run when the body cannot be read")
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/users", io.NopCloser(iotest.ErrReader(errors.New("connection reset"))))
+	require.NoError(t, err)
+
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRedactBody(t *testing.T) {
+	require.JSONEq(t, "null", string(redactBody(nil)))
+	require.JSONEq(t, "null", string(redactBody([]byte("  \n"))))
+	require.JSONEq(t, `[1,{"refresh_token":"[REDACTED]"}]`, string(redactBody([]byte(`[1,{"refresh_token":"abc"}]`))))
+	require.JSONEq(t, `{"amount":12345678901234567890}`, string(redactBody([]byte(`{"amount":12345678901234567890}`))))
+
+	large := fmt.Sprintf(`{"note":"%s"}`, util.RandomString(maxAuditBodySize))
+	require.JSONEq(t, `"[TOO LARGE]"`, string(redactBody([]byte(large))))
+}
+
+func randomAuditLog(username string) db.AuditLog {
+	return db.AuditLog{
+		ID:          util.RandomInt(1, 1000),
+		Username:    username,
+		Method:      http.MethodPost,
+		Route:       "/transfers",
+		ClientIp:    "192.0.2.1",
+		UserAgent:   "curl/7.88.1",
+		Status:      http.StatusOK,
+		RequestBody: json.RawMessage(`{"amount":10}`),
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestListAuditLogsAPI(t *testing.T) {
+	admin, _ := createRandomUser(t)
+	user, _ := createRandomUser(t)
+
+	auditLogs := []db.AuditLog{
+		randomAuditLog(user.Username),
+		randomAuditLog(user.Username),
+	}
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			role:  util.AdminRole,
+			query: "page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditLogsParams{
+					Limit:  5,
+					Offset: 5,
+				}
+
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(auditLogs, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp []db.AuditLog
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Len(t, rsp, len(auditLogs))
+				for i := range rsp {
+					require.Equal(t, auditLogs[i].ID, rsp[i].ID)
+					require.JSONEq(t, string(auditLogs[i].RequestBody), string(rsp[i].RequestBody))
+				}
+			},
+		},
+		{
+			name: "Filters",
+			role: util.AdminRole,
+			query: fmt.Sprintf(
+				"page_id=1&page_size=5&username=%s&method=DELETE&route=/webhooks/:id&resource_id=7&status=200&from=%s&to=%s",
+				user.Username, from.Format(time.RFC3339), to.Format(time.RFC3339),
+			),
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditLogsParams{
+					Username:    sql.NullString{String: user.Username, Valid: true},
+					Method:      sql.NullString{String: http.MethodDelete, Valid: true},
+					Route:       sql.NullString{String: "/webhooks/:id", Valid: true},
+					ResourceID:  sql.NullString{String: "7", Valid: true},
+					Status:      sql.NullInt32{Int32: http.StatusOK, Valid: true},
+					CreatedFrom: sql.NullTime{Time: from, Valid: true},
+					CreatedTo:   sql.NullTime{Time: to, Valid: true},
+					Limit:       5,
+				}
+
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.AuditLog{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Keyset",
+			role:  util.AdminRole,
+			query: "page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditLogsParams{
+					Limit: 5,
+				}
+
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(auditLogs, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp listAuditLogsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+				require.Len(t, rsp.AuditLogs, len(auditLogs))
+				require.Empty(t, rsp.NextCursor)
+			},
+		},
+		{
+			name:  "KeysetLargePage",
+			role:  util.AdminRole,
+			query: "page_size=100",
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAuditLogsParams{
+					Limit: 100,
+				}
+
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(auditLogs, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "PageModeLargePage",
+			role:  util.AdminRole,
+			query: "page_id=1&page_size=100",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			role:  util.DepositorRole,
+			query: "page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidMethod",
+			role:  util.AdminRole,
+			query: "page_id=1&page_size=5&method=GET",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidStatus",
+			role:  util.AdminRole,
+			query: "page_id=1&page_size=5&status=42",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidPeriod",
+			role:  util.AdminRole,
+			query: fmt.Sprintf("page_id=1&page_size=5&from=%s&to=%s", to.Format(time.RFC3339), from.Format(time.RFC3339)),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditLogs(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			role:  util.AdminRole,
+			query: "page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListAuditLogs(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]db.AuditLog{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := "/audit-logs?" + tc.query
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
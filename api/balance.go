@@ -0,0 +1,96 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+type getAccountBalanceRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getAccountBalanceRequestQuery takes the moment in RFC 3339, entries created before it are counted
+type getAccountBalanceRequestQuery struct {
+	At time.Time `form:"at"`
+}
+
+// accountBalanceResponse shows the balance at the moment and the snapshot it is computed from, if there is one
+type accountBalanceResponse struct {
+	AccountID      int64      `json:"account_id"`
+	Currency       string     `json:"currency"`
+	At             time.Time  `json:"at"`
+	Balance        int64      `json:"balance"`
+	BalanceDecimal string     `json:"balance_decimal"`
+	SnapshotAt     *time.Time `json:"snapshot_at"`
+}
+
+// getAccountBalance returns the historical balance of the account for its owner or for an admin
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	var reqID getAccountBalanceRequestID
+	var req getAccountBalanceRequestQuery
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	//balance without moment is the current one
+	now := time.Now()
+	at := req.At
+	if at.IsZero() {
+		at = now
+	}
+
+	if at.After(now) {
+		err := errors.New("balance cannot be requested for the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner && authPayload.Role != util.AdminRole {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	balanceTx, err := server.store.BalanceAsOfTx(ctx, db.BalanceAsOfTxParam{
+		AccountID: account.ID,
+		At:        at,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := accountBalanceResponse{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		At:             balanceTx.At,
+		Balance:        balanceTx.Balance,
+		BalanceDecimal: util.NewMoney(balanceTx.Balance, account.Currency).Decimal(),
+	}
+
+	if balanceTx.Snapshot != nil {
+		rsp.SnapshotAt = &balanceTx.Snapshot.SnapshotAt
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,71 @@
+package api
+
+import "sync"
+
+// AccountBroker wakes up event streams of an account when activity of the account is committed
+// Notifications carry no data, streams read what changed from the database, so a dropped notification only delays them
+type AccountBroker struct {
+	mutex       sync.Mutex
+	subscribers map[int64]map[chan struct{}]struct{}
+}
+
+// NewAccountBroker creates a broker without subscribers
+func NewAccountBroker() *AccountBroker {
+	return &AccountBroker{subscribers: map[int64]map[chan struct{}]struct{}{}}
+}
+
+// Subscribe returns a channel which receives a value after activity of the account and a function to unsubscribe
+// Notifications coming while the previous one is not received yet are merged into it
+func (broker *AccountBroker) Subscribe(accountID int64) (<-chan struct{}, func()) {
+	notifications := make(chan struct{}, 1)
+
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	if broker.subscribers[accountID] == nil {
+		broker.subscribers[accountID] = map[chan struct{}]struct{}{}
+	}
+	broker.subscribers[accountID][notifications] = struct{}{}
+
+	unsubscribe := func() {
+		broker.mutex.Lock()
+		defer broker.mutex.Unlock()
+
+		delete(broker.subscribers[accountID], notifications)
+		if len(broker.subscribers[accountID]) == 0 {
+			delete(broker.subscribers, accountID)
+		}
+	}
+
+	return notifications, unsubscribe
+}
+
+// Notify wakes up all subscribers of the account
+func (broker *AccountBroker) Notify(accountID int64) {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	for notifications := range broker.subscribers[accountID] {
+		wakeUp(notifications)
+	}
+}
+
+// NotifyAll wakes up subscribers of every account, it is used when notifications may have been lost
+func (broker *AccountBroker) NotifyAll() {
+	broker.mutex.Lock()
+	defer broker.mutex.Unlock()
+
+	for _, subscribers := range broker.subscribers {
+		for notifications := range subscribers {
+			wakeUp(notifications)
+		}
+	}
+}
+
+// wakeUp sends a notification unless one is already waiting
+func wakeUp(notifications chan struct{}) {
+	select {
+	case notifications <- struct{}{}:
+	default:
+	}
+}
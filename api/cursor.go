@@ -0,0 +1,126 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// Largest page sizes, keyset mode reads large lists so it allows bigger pages than page mode
+const (
+	maxPageSize       = 10
+	maxKeysetPageSize = 100
+)
+
+// pageCursor is the position of the last item of a page in (created_at, id) order
+type pageCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
+// pageRequest selects a page either by page_id or by cursor
+// Without page_id the list is read in keyset mode, starting after the cursor if there is one
+// Pages of up to maxKeysetPageSize items are allowed in keyset mode and up to maxPageSize items in page mode
+type pageRequest struct {
+	PageID   *int32 `form:"page_id" binding:"omitempty,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=5"`
+	Cursor   string `form:"cursor"`
+}
+
+// keyset returns true if the page is selected by cursor
+func (req pageRequest) keyset() bool {
+	return req.PageID == nil
+}
+
+// offset returns the number of items before the page in page mode
+func (req pageRequest) offset() int32 {
+	if req.PageID == nil {
+		return 0
+	}
+
+	return (*req.PageID - 1) * req.PageSize
+}
+
+// after validates the request and returns the decoded cursor, zero cursor means the first page
+func (server *Server) after(req pageRequest) (pageCursor, error) {
+	if req.PageSize > maxKeysetPageSize {
+		return pageCursor{}, fmt.Errorf("page_size must be at most %d", maxKeysetPageSize)
+	}
+
+	if !req.keyset() && req.PageSize > maxPageSize {
+		return pageCursor{}, fmt.Errorf("page_size must be at most %d with page_id", maxPageSize)
+	}
+
+	if req.Cursor == "" {
+		return pageCursor{}, nil
+	}
+
+	if !req.keyset() {
+		return pageCursor{}, errors.New("page_id and cursor can't be used together")
+	}
+
+	return server.decodeCursor(req.Cursor)
+}
+
+// nextCursor returns the cursor of the following page or empty string if the page is the last one
+func (server *Server) nextCursor(req pageRequest, count int, last pageCursor) (string, error) {
+	if count < int(req.PageSize) {
+		return "", nil
+	}
+
+	return server.encodeCursor(last)
+}
+
+// encodeCursor signs the cursor so clients can't forge positions
+func (server *Server) encodeCursor(cursor pageCursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	signature := base64.RawURLEncoding.EncodeToString(server.signCursor(encoded))
+
+	return encoded + "." + signature, nil
+}
+
+// decodeCursor checks the signature and decodes the cursor
+func (server *Server) decodeCursor(value string) (pageCursor, error) {
+	var cursor pageCursor
+
+	encoded, encodedSignature, found := strings.Cut(value, ".")
+	if !found {
+		return cursor, errInvalidCursor
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || !hmac.Equal(signature, server.signCursor(encoded)) {
+		return cursor, errInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return cursor, errInvalidCursor
+	}
+
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID <= 0 {
+		return cursor, errInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// signCursor computes HMAC-SHA256 of the encoded cursor with the token key
+func (server *Server) signCursor(encoded string) []byte {
+	mac := hmac.New(sha256.New, []byte(server.config.TokenSymmetricKey))
+	mac.Write([]byte("cursor:" + encoded))
+
+	return mac.Sum(nil)
+}
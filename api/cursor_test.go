@@ -0,0 +1,100 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCursor(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	cursor := pageCursor{
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		ID:        42,
+	}
+
+	encoded, err := server.encodeCursor(cursor)
+	require.NoError(t, err)
+	require.NotEmpty(t, encoded)
+
+	decoded, err := server.decodeCursor(encoded)
+	require.NoError(t, err)
+	require.Equal(t, cursor, decoded)
+
+	//cursor signed with another key is rejected
+	otherServer := newTestServer(t, nil)
+	_, err = otherServer.decodeCursor(encoded)
+	require.ErrorIs(t, err, errInvalidCursor)
+
+	//forged position is rejected
+	forged, err := otherServer.encodeCursor(pageCursor{CreatedAt: cursor.CreatedAt, ID: 1})
+	require.NoError(t, err)
+	_, err = server.decodeCursor(forged)
+	require.ErrorIs(t, err, errInvalidCursor)
+
+	for _, value := range []string{"", "abc", "abc.def", encoded + "x"} {
+		_, err = server.decodeCursor(value)
+		require.ErrorIs(t, err, errInvalidCursor)
+	}
+}
+
+func TestPageRequest(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	pageID := int32(3)
+	req := pageRequest{PageID: &pageID, PageSize: 5}
+	require.False(t, req.keyset())
+	require.Equal(t, int32(10), req.offset())
+
+	after, err := server.after(req)
+	require.NoError(t, err)
+	require.Zero(t, after)
+
+	//page_id and cursor are exclusive
+	req.Cursor, err = server.encodeCursor(pageCursor{CreatedAt: time.Now(), ID: 1})
+	require.NoError(t, err)
+	_, err = server.after(req)
+	require.Error(t, err)
+
+	req.PageID = nil
+	require.True(t, req.keyset())
+	require.Zero(t, req.offset())
+
+	after, err = server.after(req)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), after.ID)
+
+	//only a full page has next cursor
+	next, err := server.nextCursor(req, 4, after)
+	require.NoError(t, err)
+	require.Empty(t, next)
+
+	next, err = server.nextCursor(req, 5, after)
+	require.NoError(t, err)
+	require.NotEmpty(t, next)
+}
+
+func TestPageRequestSize(t *testing.T) {
+	server := newTestServer(t, nil)
+
+	//keyset mode allows large pages
+	req := pageRequest{PageSize: maxKeysetPageSize + 1}
+	_, err := server.after(req)
+	require.Error(t, err)
+
+	req.PageSize = maxKeysetPageSize
+	_, err = server.after(req)
+	require.NoError(t, err)
+
+	//page mode keeps small pages
+	pageID := int32(1)
+	req.PageID = &pageID
+	_, err = server.after(req)
+	require.Error(t, err)
+
+	req.PageSize = maxPageSize
+	_, err = server.after(req)
+	require.NoError(t, err)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+)
+
+// Different types of error returned by precondition checks
+var (
+	errIfMatchRequired = errors.New("If-Match header with the account ETag is required")
+	errInvalidIfMatch  = errors.New("If-Match header must be a list of quoted ETags or *")
+)
+
+// accountETag returns the strong ETag of the account, which changes together with its version
+func accountETag(account db.Account) string {
+	return `"` + strconv.FormatInt(account.Version, 10) + `"`
+}
+
+// setAccountETag sends the ETag of the account so the client can use it in If-Match of the next change
+func setAccountETag(ctx *gin.Context, account db.Account) {
+	ctx.Header("ETag", accountETag(account))
+}
+
+// ifMatchAccount checks the If-Match header of a request changing the account against its current version
+// A request without the header is answered with 428, one with a stale ETag with 412
+func ifMatchAccount(ctx *gin.Context, account db.Account) bool {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" {
+		ctx.JSON(http.StatusPreconditionRequired, errorResponse(errIfMatchRequired))
+		return false
+	}
+
+	etag := accountETag(account)
+
+	for _, value := range strings.Split(header, ",") {
+		value = strings.TrimSpace(value)
+
+		//If-Match uses strong comparison, so weak ETags never match
+		if strings.HasPrefix(value, "W/") {
+			continue
+		}
+
+		if value != "*" && (len(value) < 2 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`)) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidIfMatch))
+			return false
+		}
+
+		if value == "*" || value == etag {
+			return true
+		}
+	}
+
+	ctx.JSON(http.StatusPreconditionFailed, errorCodeResponse(errCodeVersionMismatch, db.ErrVersionMismatch))
+	return false
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+type exchangeRateRequestCurrencies struct {
+	FromCurrency string `uri:"from" binding:"required,currency"`
+	ToCurrency   string `uri:"to" binding:"required,currency"`
+}
+
+type exchangeRateRequestRate struct {
+	Rate string `json:"rate" binding:"required"`
+}
+
+func (server *Server) setExchangeRate(ctx *gin.Context) {
+	var reqCurrencies exchangeRateRequestCurrencies
+	var reqRate exchangeRateRequestRate
+
+	if err := ctx.ShouldBindUri(&reqCurrencies); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&reqRate); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if reqCurrencies.FromCurrency == reqCurrencies.ToCurrency {
+		err := errors.New("exchange rate must be set between different currencies")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := util.ParseExchangeRate(reqRate.Rate); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.UpsertExchangeRateParams{
+		FromCurrency: reqCurrencies.FromCurrency,
+		ToCurrency:   reqCurrencies.ToCurrency,
+		Rate:         reqRate.Rate,
+	}
+
+	exchangeRate, err := server.store.UpsertExchangeRate(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, exchangeRate)
+}
+
+func (server *Server) listExchangeRates(ctx *gin.Context) {
+	exchangeRates, err := server.store.ListExchangeRates(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, exchangeRates)
+}
+
+func (server *Server) deleteExchangeRate(ctx *gin.Context) {
+	var req exchangeRateRequestCurrencies
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.DeleteExchangeRateParams{
+		FromCurrency: req.FromCurrency,
+		ToCurrency:   req.ToCurrency,
+	}
+
+	err := server.store.DeleteExchangeRate(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
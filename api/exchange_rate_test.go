@@ -0,0 +1,284 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func requireBodyMatchExchangeRate(t *testing.T, body *bytes.Buffer, exchangeRate db.ExchangeRate) {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var gotExchangeRate db.ExchangeRate
+
+	err = json.Unmarshal(data, &gotExchangeRate)
+	require.NoError(t, err)
+
+	require.Equal(t, exchangeRate, gotExchangeRate)
+}
+
+func TestSetExchangeRateAPI(t *testing.T) {
+	admin, _ := createRandomUser(t)
+	user, _ := createRandomUser(t)
+
+	exchangeRate := db.ExchangeRate{
+		FromCurrency: util.USD,
+		ToCurrency:   util.EUR,
+		Rate:         "0.9215",
+	}
+
+	testCases := []struct {
+		name          string
+		fromCurrency  string
+		toCurrency    string
+		body          gin.H
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:         "OK",
+			fromCurrency: exchangeRate.FromCurrency,
+			toCurrency:   exchangeRate.ToCurrency,
+			body: gin.H{
+				"rate": exchangeRate.Rate,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertExchangeRateParams{
+					FromCurrency: exchangeRate.FromCurrency,
+					ToCurrency:   exchangeRate.ToCurrency,
+					Rate:         exchangeRate.Rate,
+				}
+
+				store.EXPECT().
+					UpsertExchangeRate(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(exchangeRate, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchExchangeRate(t, recorder.Body, exchangeRate)
+			},
+		},
+		{
+			name:         "NotAdmin",
+			fromCurrency: exchangeRate.FromCurrency,
+			toCurrency:   exchangeRate.ToCurrency,
+			body: gin.H{
+				"rate": exchangeRate.Rate,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertExchangeRate(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:         "SameCurrency",
+			fromCurrency: util.USD,
+			toCurrency:   util.USD,
+			body: gin.H{
+				"rate": "1",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertExchangeRate(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidCurrency",
+			fromCurrency: "XYZ",
+			toCurrency:   exchangeRate.ToCurrency,
+			body: gin.H{
+				"rate": exchangeRate.Rate,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertExchangeRate(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InvalidRate",
+			fromCurrency: exchangeRate.FromCurrency,
+			toCurrency:   exchangeRate.ToCurrency,
+			body: gin.H{
+				"rate": "-0.5",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertExchangeRate(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:         "InternalError",
+			fromCurrency: exchangeRate.FromCurrency,
+			toCurrency:   exchangeRate.ToCurrency,
+			body: gin.H{
+				"rate": exchangeRate.Rate,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, admin.Username, util.AdminRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					UpsertExchangeRate(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ExchangeRate{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//marshal body data to JSON
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/exchange-rates/%s/%s", tc.fromCurrency, tc.toCurrency)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			checkAudit := expectAuditLog(t, store, "/exchange-rates/:from/:to")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListExchangeRatesAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+
+	exchangeRates := []db.ExchangeRate{
+		{FromCurrency: util.EUR, ToCurrency: util.USD, Rate: "1.0851"},
+		{FromCurrency: util.USD, ToCurrency: util.EUR, Rate: "0.9215"},
+	}
+
+	testCases := []struct {
+		name          string
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListExchangeRates(gomock.Any()).
+					Times(1).
+					Return(exchangeRates, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotExchangeRates []db.ExchangeRate
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotExchangeRates)
+				require.NoError(t, err)
+				require.Equal(t, exchangeRates, gotExchangeRates)
+			},
+		},
+		{
+			name: "NoAuthorization",
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ListExchangeRates(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := "/exchange-rates"
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenMaker)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
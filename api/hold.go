@@ -0,0 +1,282 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// holdResponse is a hold with its amounts as decimal strings in major units of the account currency
+type holdResponse struct {
+	db.Hold
+	AmountDecimal         string `json:"amount_decimal"`
+	CapturedAmountDecimal string `json:"captured_amount_decimal"`
+}
+
+func newHoldResponse(hold db.Hold, currency string) holdResponse {
+	return holdResponse{
+		Hold:                  hold,
+		AmountDecimal:         decimal(hold.Amount, currency),
+		CapturedAmountDecimal: decimal(hold.CapturedAmount, currency),
+	}
+}
+
+// captureHoldResponse shows the captured hold and its transfer
+type captureHoldResponse struct {
+	Hold holdResponse `json:"hold"`
+	transferResponse
+}
+
+type authorizeHoldRequest struct {
+	AccountID     int64     `json:"account_id" binding:"required,min=1"`
+	Amount        int64     `json:"amount" binding:"min=0"`
+	AmountDecimal string    `json:"amount_decimal"`
+	Currency      string    `json:"currency" binding:"required,currency"`
+	ExpiredAt     time.Time `json:"expired_at"`
+}
+
+func (server *Server) authorizeHold(ctx *gin.Context) {
+	var req authorizeHoldRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	amount, err := requestAmount(req.Amount, req.AmountDecimal, req.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	//hold without expiry from the client lives for the configured duration
+	expiredAt := req.ExpiredAt
+	if expiredAt.IsZero() {
+		expiredAt = time.Now().Add(server.config.HoldDuration)
+	}
+
+	if !expiredAt.After(time.Now()) {
+		err := errors.New("hold must expire in the future")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccountCurrency(ctx, req.AccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if account.Owner != authPayload.Username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	//captured money is transferred without approval, so the hold can't be larger than the threshold
+	if server.approvalRequired(ctx, amount, account.Currency) {
+		return
+	}
+
+	arg := db.AuthorizeHoldTxParam{
+		AccountID: req.AccountID,
+		Amount:    amount,
+		ExpiredAt: expiredAt,
+	}
+
+	hold, err := server.store.AuthorizeHoldTx(ctx, arg)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResponse(hold, account.Currency))
+}
+
+type holdRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getHold(ctx *gin.Context) {
+	var req holdRequestID
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, account, valid := server.validHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResponse(hold, account.Currency))
+}
+
+// captureHoldRequest takes the amount in the currency of the held account, without amount the whole hold is captured
+type captureHoldRequest struct {
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"min=0"`
+	AmountDecimal string `json:"amount_decimal"`
+}
+
+func (server *Server) captureHold(ctx *gin.Context) {
+	var reqID holdRequestID
+	var req captureHoldRequest
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, account, valid := server.validHold(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	amount, err := optionalRequestAmount(req.Amount, req.AmountDecimal, account.Currency)
+	if err == nil && amount < 0 {
+		err = errors.New("amount must be positive")
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	//receiver may hold another currency, the amount is converted by CaptureHoldTx
+	_, valid = server.validAccount(ctx, req.ToAccountID)
+	if !valid {
+		return
+	}
+
+	//the threshold may have been lowered since the hold was authorized
+	capturedAmount := amount
+	if capturedAmount == 0 {
+		capturedAmount = hold.Amount
+	}
+	if server.approvalRequired(ctx, capturedAmount, account.Currency) {
+		return
+	}
+
+	arg := db.CaptureHoldTxParam{
+		HoldID:      hold.ID,
+		ToAccountID: req.ToAccountID,
+		Amount:      amount,
+	}
+
+	captureTx, err := server.store.CaptureHoldTx(ctx, arg)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrHoldNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeHoldNotActive, err))
+			return
+		case errors.Is(err, db.ErrHoldExpired):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeHoldExpired, err))
+			return
+		case errors.Is(err, db.ErrCaptureExceedsHold):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeCaptureExceedsHold, err))
+			return
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, err))
+			return
+		case errors.Is(err, db.ErrTransferLimitExceeded):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeTransferLimitExceeded, err))
+			return
+		case errors.Is(err, db.ErrExchangeRateNotFound):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeExchangeRateNotFound, err))
+			return
+		case errors.Is(err, db.ErrAmountTooSmall), errors.Is(err, util.ErrAmountOverflow):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInvalidAmount, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, captureHoldResponse{
+		Hold:             newHoldResponse(captureTx.Hold, account.Currency),
+		transferResponse: newTransferResponse(captureTx.TransferTxResult),
+	})
+}
+
+func (server *Server) releaseHold(ctx *gin.Context) {
+	var req holdRequestID
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	hold, account, valid := server.validHold(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	hold, err := server.store.ReleaseHold(ctx, hold.ID)
+
+	if err != nil {
+		//only active hold is updated
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeHoldNotActive, db.ErrHoldNotActive))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newHoldResponse(hold, account.Currency))
+}
+
+// validHold loads the hold and its account and checks the account belongs to the authenticated user
+func (server *Server) validHold(ctx *gin.Context, id int64) (db.Hold, db.Account, bool) {
+	hold, err := server.store.GetHold(ctx, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return hold, db.Account{}, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return hold, db.Account{}, false
+	}
+
+	account, valid := server.validAccount(ctx, hold.AccountID)
+	if !valid {
+		return hold, account, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner {
+		err := errors.New("hold doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return hold, account, false
+	}
+
+	return hold, account, true
+}
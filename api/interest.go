@@ -0,0 +1,225 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/lib/pq"
+)
+
+type createInterestProductRequest struct {
+	Name        string `json:"name" binding:"required,max=255"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	AnnualRate  string `json:"annual_rate" binding:"required"`
+	Compounding string `json:"compounding" binding:"required,oneof=daily monthly"`
+}
+
+func (server *Server) createInterestProduct(ctx *gin.Context) {
+	var req createInterestProductRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, err := util.ParseInterestRate(req.AnnualRate); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateInterestProductParams{
+		Name:        req.Name,
+		Currency:    req.Currency,
+		AnnualRate:  req.AnnualRate,
+		Compounding: req.Compounding,
+	}
+
+	product, err := server.store.CreateInterestProduct(ctx, arg)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, product)
+}
+
+func (server *Server) listInterestProducts(ctx *gin.Context) {
+	products, err := server.store.ListInterestProducts(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, products)
+}
+
+type setAccountInterestRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type setAccountInterestRequestProduct struct {
+	ProductID int64 `json:"product_id" binding:"required,min=1"`
+}
+
+// setAccountInterest makes the account earn interest of the product from today on
+// Changing the product of an account keeps interest accrued with the previous one
+// The request needs If-Match with the account ETag like other changes of the account and the response carries the new one
+func (server *Server) setAccountInterest(ctx *gin.Context) {
+	var reqID setAccountInterestRequestID
+	var reqProduct setAccountInterestRequestProduct
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&reqProduct); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	if !ifMatchAccount(ctx, account) {
+		return
+	}
+
+	if account.Status == util.ClosedAccount {
+		ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, db.ErrAccountNotActive))
+		return
+	}
+
+	product, err := server.store.GetInterestProduct(ctx, reqProduct.ProductID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if product.Currency != account.Currency {
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, product.Currency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	//today is not accrued yet
+	year, month, day := time.Now().UTC().Date()
+
+	arg := db.SetAccountInterestTxParam{
+		AccountID:      account.ID,
+		ProductID:      product.ID,
+		AccruedThrough: time.Date(year, month, day-1, 0, 0, 0, 0, time.UTC),
+		Version:        account.Version,
+	}
+
+	interestTx, err := server.store.SetAccountInterestTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrVersionMismatch):
+			ctx.JSON(http.StatusPreconditionFailed, errorCodeResponse(errCodeVersionMismatch, err))
+			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	setAccountETag(ctx, interestTx.Account)
+	ctx.JSON(http.StatusOK, interestTx.AccountInterest)
+}
+
+type getAccountInterestRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getAccountInterestRequestQuery struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// accountInterestResponse shows the product of the account, interest accrued and not posted yet and the latest accruals
+type accountInterestResponse struct {
+	Interest db.AccountInterest   `json:"interest"`
+	Product  db.InterestProduct   `json:"product"`
+	Accruals []db.InterestAccrual `json:"accruals"`
+}
+
+func (server *Server) getAccountInterest(ctx *gin.Context) {
+	var reqID getAccountInterestRequestID
+	var req getAccountInterestRequestQuery
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	var rsp accountInterestResponse
+	var err error
+
+	rsp.Interest, err = server.store.GetAccountInterest(ctx, account.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp.Product, err = server.store.GetInterestProduct(ctx, rsp.Interest.ProductID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp.Accruals, err = server.store.ListInterestAccruals(ctx, db.ListInterestAccrualsParams{
+		AccountID: account.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	})
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
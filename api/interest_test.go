@@ -0,0 +1,298 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomInterestProduct(currency string) db.InterestProduct {
+	return db.InterestProduct{
+		ID:          util.RandomInt(1, 1000),
+		Name:        util.RandomString(8),
+		Currency:    currency,
+		AnnualRate:  "0.035",
+		Compounding: util.MonthlyCompounding,
+		CreatedAt:   time.Now(),
+	}
+}
+
+func TestCreateInterestProductAPI(t *testing.T) {
+	admin, _ := createRandomUser(t)
+	product := randomInterestProduct(util.USD)
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name":        product.Name,
+				"currency":    product.Currency,
+				"annual_rate": product.AnnualRate,
+				"compounding": product.Compounding,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.CreateInterestProductParams{
+					Name:        product.Name,
+					Currency:    product.Currency,
+					AnnualRate:  product.AnnualRate,
+					Compounding: product.Compounding,
+				}
+
+				store.EXPECT().
+					CreateInterestProduct(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(product, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidRate",
+			body: gin.H{
+				"name":        product.Name,
+				"currency":    product.Currency,
+				"annual_rate": "3.5",
+				"compounding": product.Compounding,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInterestProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCompounding",
+			body: gin.H{
+				"name":        product.Name,
+				"currency":    product.Currency,
+				"annual_rate": product.AnnualRate,
+				"compounding": "yearly",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateInterestProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/interest-products", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, util.AdminRole, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/interest-products")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetAccountInterestAPI(t *testing.T) {
+	admin, _ := createRandomUser(t)
+	user, _ := createRandomUser(t)
+
+	account := createRandomAccount(user.Username)
+	product := randomInterestProduct(account.Currency)
+	otherProduct := randomInterestProduct(account.Currency + "X")
+
+	closedAccount := account
+	closedAccount.Status = util.ClosedAccount
+
+	changedAccount := account
+	changedAccount.Version++
+
+	testCases := []struct {
+		name          string
+		productID     int64
+		ifMatch       string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "OK",
+			productID: product.ID,
+			ifMatch:   accountETag(account),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetInterestProduct(gomock.Any(), gomock.Eq(product.ID)).Times(1).Return(product, nil)
+
+				store.EXPECT().
+					SetAccountInterestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.SetAccountInterestTxParam) (db.SetAccountInterestTxResult, error) {
+						require.Equal(t, account.ID, arg.AccountID)
+						require.Equal(t, product.ID, arg.ProductID)
+						require.Equal(t, account.Version, arg.Version)
+						require.True(t, arg.AccruedThrough.Before(time.Now()))
+						require.WithinDuration(t, time.Now().AddDate(0, 0, -1), arg.AccruedThrough, 24*time.Hour)
+
+						return db.SetAccountInterestTxResult{
+							AccountInterest: db.AccountInterest{AccountID: arg.AccountID, ProductID: arg.ProductID, Accrued: "0", AccruedThrough: arg.AccruedThrough},
+							Account:         changedAccount,
+						}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, accountETag(changedAccount), recorder.Header().Get("ETag"))
+			},
+		},
+		{
+			name:      "NoIfMatch",
+			productID: product.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetInterestProduct(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().SetAccountInterestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionRequired, recorder.Code)
+			},
+		},
+		{
+			name:      "StaleIfMatch",
+			productID: product.ID,
+			ifMatch:   accountETag(changedAccount),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetInterestProduct(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().SetAccountInterestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeVersionMismatch)
+			},
+		},
+		{
+			name:      "ChangedConcurrently",
+			productID: product.ID,
+			ifMatch:   accountETag(account),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetInterestProduct(gomock.Any(), gomock.Eq(product.ID)).Times(1).Return(product, nil)
+				store.EXPECT().
+					SetAccountInterestTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.SetAccountInterestTxResult{}, db.ErrVersionMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeVersionMismatch)
+			},
+		},
+		{
+			name:      "CurrencyMismatch",
+			productID: otherProduct.ID,
+			ifMatch:   accountETag(account),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetInterestProduct(gomock.Any(), gomock.Eq(otherProduct.ID)).Times(1).Return(otherProduct, nil)
+				store.EXPECT().SetAccountInterestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:      "ProductNotFound",
+			productID: product.ID,
+			ifMatch:   accountETag(account),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetInterestProduct(gomock.Any(), gomock.Eq(product.ID)).Times(1).Return(db.InterestProduct{}, sql.ErrNoRows)
+				store.EXPECT().SetAccountInterestTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:      "ClosedAccount",
+			productID: product.ID,
+			ifMatch:   accountETag(account),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(closedAccount, nil)
+				store.EXPECT().GetInterestProduct(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeAccountNotActive)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"product_id": tc.productID})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/accounts/%d/interest", account.ID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			if tc.ifMatch != "" {
+				request.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, util.AdminRole, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/accounts/:id/interest")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -1,20 +1,34 @@
 package api
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
 	db "github.com/gu3sswho/simplebank/db/sqlc"
 	"github.com/gu3sswho/simplebank/util"
 	"github.com/stretchr/testify/require"
 )
 
+// testApprovalThreshold is above any random amount, so only tests of approvals create pending transfers
+const testApprovalThreshold = 100000
+
 func newTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config{
-		TokenSymmetricKey:   util.RandomString(32),
-		AccessTokenDuration: time.Minute,
+		TokenSymmetricKey:      util.RandomString(32),
+		AccessTokenDuration:    time.Minute,
+		IdempotencyKeyDuration: time.Minute,
+		HoldDuration:           time.Minute,
+		ApprovalThresholds: map[string]int64{
+			util.USD: testApprovalThreshold,
+			util.EUR: testApprovalThreshold,
+			util.RUB: testApprovalThreshold,
+		},
 	}
 
 	server, err := NewServer(config, store)
@@ -23,6 +37,25 @@ func newTestServer(t *testing.T, store db.Store) *Server {
 	return server
 }
 
+// expectAuditLog expects one audit log row written for a request to the route
+// The returned check makes sure the row records the method of the request and the status of its response
+func expectAuditLog(t *testing.T, store *mockdb.MockStore, route string) func(request *http.Request, recorder *httptest.ResponseRecorder) {
+	var arg db.CreateAuditLogParams
+
+	store.EXPECT().
+		CreateAuditLog(gomock.Any(), gomock.Any()).
+		Times(1).
+		Do(func(_ interface{}, got db.CreateAuditLogParams) {
+			arg = got
+		})
+
+	return func(request *http.Request, recorder *httptest.ResponseRecorder) {
+		require.Equal(t, request.Method, arg.Method)
+		require.Equal(t, route, arg.Route)
+		require.Equal(t, int32(recorder.Code), arg.Status)
+	}
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 
@@ -1,13 +1,19 @@
 package api
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
 	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
 )
 
 const (
@@ -51,3 +57,77 @@ func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
 		ctx.Next()
 	}
 }
+
+// adminMiddleware lets through only users with admin role, it must be used after authMiddleware
+func adminMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		if authPayload.Role != util.AdminRole {
+			err := errors.New("only admin is allowed to perform this action")
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// auditedBody hands the part of the request body read by auditMiddleware back to the handler before the unread rest
+type auditedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// auditMiddleware writes an audit log row for every request which may change state once the request is handled
+// It must be used before authMiddleware, so it sees requests rejected by authentication too
+func auditMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !isMutatingMethod(ctx.Request.Method) {
+			ctx.Next()
+			return
+		}
+
+		var body []byte
+		if ctx.Request.Body != nil {
+			//one byte over the limit is enough to tell the body is too large for the audit log, the handler reads the rest
+			var err error
+			body, err = io.ReadAll(io.LimitReader(ctx.Request.Body, maxAuditBodySize+1))
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+				return
+			}
+			ctx.Request.Body = auditedBody{
+				Reader: io.MultiReader(bytes.NewReader(body), ctx.Request.Body),
+				Closer: ctx.Request.Body,
+			}
+		}
+
+		ctx.Next()
+
+		arg := db.CreateAuditLogParams{
+			Method:      ctx.Request.Method,
+			Route:       ctx.FullPath(),
+			ResourceID:  auditResourceID(ctx),
+			ClientIp:    ctx.ClientIP(),
+			UserAgent:   ctx.Request.UserAgent(),
+			Status:      int32(ctx.Writer.Status()),
+			RequestBody: redactBody(body),
+		}
+
+		if arg.Route == "" {
+			arg.Route = ctx.Request.URL.Path
+		}
+
+		if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+			arg.Username = payload.(*token.Payload).Username
+		}
+
+		//the handler may have committed a change after the client went away, so the row is written regardless of the request context
+		auditCtx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+		defer cancel()
+
+		if _, err := store.CreateAuditLog(auditCtx, arg); err != nil {
+			log.Printf("cannot write audit log of %s %s: %v", arg.Method, arg.Route, err)
+		}
+	}
+}
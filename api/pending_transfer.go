@@ -0,0 +1,244 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// approvalRequired responds with 422 if the amount is above the approval threshold of its currency
+// It guards money movements which have no pending state, only single transfers can wait for approval
+func (server *Server) approvalRequired(ctx *gin.Context, amount int64, currency string) bool {
+	if !server.config.RequiresApproval(amount, currency) {
+		return false
+	}
+
+	err := fmt.Errorf("amount above %d %s needs approval, send it as a single transfer", server.config.ApprovalThresholds[currency], currency)
+	ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeApprovalRequired, err))
+	return true
+}
+
+// pendingTransferIdempotentRequest is the fingerprinted part of a transfer which waits for approval
+// Its shape differs from transferRequest, so a key can't replay a pending transfer as an executed one after the threshold changes
+type pendingTransferIdempotentRequest struct {
+	transferRequest
+	PendingApproval bool `json:"pending_approval"`
+}
+
+// createPendingTransfer saves a transfer which is executed only after another user approves it
+func (server *Server) createPendingTransfer(ctx *gin.Context, createdBy string, idempotencyKey string, req transferRequest) {
+	arg := db.CreatePendingTransferTxParam{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		CreatedBy:     createdBy,
+	}
+
+	if idempotencyKey != "" {
+		requestHash, err := hashRequest(createdBy, pendingTransferIdempotentRequest{
+			transferRequest: req,
+			PendingApproval: true,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		arg.IdempotencyKey = idempotencyKey
+		arg.KeyUsername = createdBy
+		arg.RequestHash = requestHash
+		arg.KeyExpiredAt = time.Now().Add(server.config.IdempotencyKeyDuration)
+	}
+
+	pendingTransfer, err := server.store.CreatePendingTransferTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrIdempotencyKeyReused) {
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, pendingTransfer)
+}
+
+type listPendingTransfersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listPendingTransfers(ctx *gin.Context) {
+	var req listPendingTransfersRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.ListPendingTransfersParams{
+		Status: util.PendingApproval,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	pendingTransfers, err := server.store.ListPendingTransfers(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pendingTransfers)
+}
+
+type pendingTransferRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getPendingTransfer shows the transfer to its creator, who waits for the decision, and to admins
+func (server *Server) getPendingTransfer(ctx *gin.Context) {
+	var req pendingTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pendingTransfer, err := server.store.GetPendingTransfer(ctx, req.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if pendingTransfer.CreatedBy != authPayload.Username && authPayload.Role != util.AdminRole {
+		err := errors.New("pending transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pendingTransfer)
+}
+
+// approveTransfer executes the pending transfer, the ID is the one POST /transfers returns for transfers waiting for approval
+func (server *Server) approveTransfer(ctx *gin.Context) {
+	var req pendingTransferRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ApproveTransferTxParam{
+		ID:         req.ID,
+		ApprovedBy: authPayload.Username,
+	}
+
+	approveTx, err := server.store.ApproveTransferTx(ctx, arg)
+
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrSelfApproval):
+			ctx.JSON(http.StatusForbidden, errorCodeResponse(errCodeSelfApproval, err))
+			return
+		case errors.Is(err, db.ErrTransferNotPending):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeTransferNotPending, err))
+			return
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, err))
+			return
+		case errors.Is(err, db.ErrTransferLimitExceeded):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeTransferLimitExceeded, err))
+			return
+		case errors.Is(err, db.ErrExchangeRateNotFound):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeExchangeRateNotFound, err))
+			return
+		case errors.Is(err, db.ErrAmountTooSmall), errors.Is(err, util.ErrAmountOverflow):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInvalidAmount, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, approveTx)
+}
+
+type rejectTransferRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// rejectTransfer closes the pending transfer without moving money
+func (server *Server) rejectTransfer(ctx *gin.Context) {
+	var reqID pendingTransferRequest
+	var req rejectTransferRequest
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	//reason of the rejection is optional
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	pendingTransfer, err := server.store.GetPendingTransfer(ctx, reqID.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if pendingTransfer.CreatedBy == authPayload.Username {
+		err := errors.New("transfer cannot be rejected by its creator")
+		ctx.JSON(http.StatusForbidden, errorCodeResponse(errCodeSelfApproval, err))
+		return
+	}
+
+	arg := db.DecidePendingTransferParams{
+		ID:        pendingTransfer.ID,
+		Status:    util.RejectedTransfer,
+		DecidedBy: sql.NullString{String: authPayload.Username, Valid: true},
+		Reason:    req.Reason,
+	}
+
+	//the transfer is decided only if nobody has decided it since it was read
+	pendingTransfer, err = server.store.DecidePendingTransfer(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeTransferNotPending, db.ErrTransferNotPending))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pendingTransfer)
+}
@@ -0,0 +1,508 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPendingTransfer(createdBy string, fromAccount db.Account, toAccount db.Account) db.PendingTransfer {
+	return db.PendingTransfer{
+		ID:            util.RandomInt(1, 1000),
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        testApprovalThreshold + 1,
+		Status:        util.PendingApproval,
+		CreatedBy:     createdBy,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func TestCreateTransferApprovalAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+
+	account1 := createRandomAccount(user.Username)
+	account2 := createRandomAccount(user.Username)
+	account1.Currency = util.USD
+
+	pendingTransfer := createRandomPendingTransfer(user.Username, account1, account2)
+
+	testCases := []struct {
+		name          string
+		header        map[string]string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.CreatePendingTransferTxParam{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        pendingTransfer.Amount,
+					CreatedBy:     user.Username,
+				}
+
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(pendingTransfer, nil)
+
+				//transfer above the threshold is never executed straight away
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var gotPendingTransfer db.PendingTransfer
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotPendingTransfer)
+				require.NoError(t, err)
+				require.Equal(t, pendingTransfer.ID, gotPendingTransfer.ID)
+				require.Equal(t, util.PendingApproval, gotPendingTransfer.Status)
+			},
+		},
+		{
+			name: "IdempotencyKey",
+			header: map[string]string{
+				idempotencyKeyHeader: "pending-1",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ interface{}, arg db.CreatePendingTransferTxParam) (db.PendingTransfer, error) {
+						require.Equal(t, "pending-1", arg.IdempotencyKey)
+						require.Equal(t, user.Username, arg.KeyUsername)
+						require.NotEmpty(t, arg.RequestHash)
+						require.WithinDuration(t, time.Now().Add(time.Minute), arg.KeyExpiredAt, time.Second)
+						return pendingTransfer, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "IdempotencyKeyReused",
+			header: map[string]string{
+				idempotencyKeyHeader: "pending-1",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				store.EXPECT().
+					CreatePendingTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PendingTransfer{}, db.ErrIdempotencyKeyReused)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          pendingTransfer.Amount,
+				"currency":        util.USD,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			for key, value := range tc.header {
+				request.Header.Set(key, value)
+			}
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, util.DepositorRole, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/transfers")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetPendingTransferAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	other, _ := createRandomUser(t)
+
+	account1 := createRandomAccount(user.Username)
+	account2 := createRandomAccount(user.Username)
+
+	pendingTransfer := createRandomPendingTransfer(user.Username, account1, account2)
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Creator",
+			username: user.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(pendingTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotPendingTransfer db.PendingTransfer
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotPendingTransfer)
+				require.NoError(t, err)
+				require.Equal(t, pendingTransfer.ID, gotPendingTransfer.ID)
+				require.Equal(t, pendingTransfer.Status, gotPendingTransfer.Status)
+			},
+		},
+		{
+			name:     "Admin",
+			username: other.Username,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(pendingTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: other.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(pendingTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(db.PendingTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/pending-transfers/%d", pendingTransfer.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApproveTransferAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	admin, _ := createRandomUser(t)
+
+	account1 := createRandomAccount(user.Username)
+	account2 := createRandomAccount(user.Username)
+
+	pendingTransfer := createRandomPendingTransfer(user.Username, account1, account2)
+
+	approvedTransfer := pendingTransfer
+	approvedTransfer.Status = util.ApprovedTransfer
+	approvedTransfer.DecidedBy = sql.NullString{String: admin.Username, Valid: true}
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: admin.Username,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ApproveTransferTxParam{
+					ID:         pendingTransfer.ID,
+					ApprovedBy: admin.Username,
+				}
+
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.ApproveTransferTxResult{PendingTransfer: approvedTransfer}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotAdmin",
+			username: admin.Username,
+			role:     util.DepositorRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ApproveTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "SelfApproval",
+			username: user.Username,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrSelfApproval)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeSelfApproval)
+			},
+		},
+		{
+			name:     "NotPending",
+			username: admin.Username,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrTransferNotPending)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeTransferNotPending)
+			},
+		},
+		{
+			name:     "InsufficientFunds",
+			username: admin.Username,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeInsufficientFunds)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: admin.Username,
+			role:     util.AdminRole,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					ApproveTransferTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ApproveTransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/transfers/%d/approve", pendingTransfer.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, tc.role, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/transfers/:id/approve")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRejectTransferAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	admin, _ := createRandomUser(t)
+
+	account1 := createRandomAccount(user.Username)
+	account2 := createRandomAccount(user.Username)
+
+	pendingTransfer := createRandomPendingTransfer(user.Username, account1, account2)
+
+	rejectedTransfer := pendingTransfer
+	rejectedTransfer.Status = util.RejectedTransfer
+	rejectedTransfer.DecidedBy = sql.NullString{String: admin.Username, Valid: true}
+	rejectedTransfer.Reason = "suspicious"
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: admin.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(pendingTransfer, nil)
+
+				arg := db.DecidePendingTransferParams{
+					ID:        pendingTransfer.ID,
+					Status:    util.RejectedTransfer,
+					DecidedBy: sql.NullString{String: admin.Username, Valid: true},
+					Reason:    rejectedTransfer.Reason,
+				}
+
+				store.EXPECT().
+					DecidePendingTransfer(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(rejectedTransfer, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var gotPendingTransfer db.PendingTransfer
+				err := json.Unmarshal(recorder.Body.Bytes(), &gotPendingTransfer)
+				require.NoError(t, err)
+				require.Equal(t, util.RejectedTransfer, gotPendingTransfer.Status)
+				require.Equal(t, rejectedTransfer.Reason, gotPendingTransfer.Reason)
+			},
+		},
+		{
+			name:     "SelfRejection",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(pendingTransfer, nil)
+				store.EXPECT().DecidePendingTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeSelfApproval)
+			},
+		},
+		{
+			name:     "AlreadyDecided",
+			username: admin.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pendingTransfer.ID)).Times(1).Return(pendingTransfer, nil)
+				store.EXPECT().
+					DecidePendingTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.PendingTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchErrorCode(t, recorder.Body, errCodeTransferNotPending)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: admin.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.PendingTransfer{}, sql.ErrNoRows)
+				store.EXPECT().DecidePendingTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"reason": rejectedTransfer.Reason})
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/transfers/%d/reject", pendingTransfer.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.AdminRole, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/transfers/:id/reject")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
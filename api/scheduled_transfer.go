@@ -0,0 +1,341 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+var errScheduledTransferNotActive = errors.New("scheduled transfer is not active")
+
+// scheduledTransferResponse is a scheduled transfer with its amount as a decimal string in major units of the sender currency
+type scheduledTransferResponse struct {
+	db.ScheduledTransfer
+	AmountDecimal string `json:"amount_decimal"`
+}
+
+func newScheduledTransferResponse(scheduledTransfer db.ScheduledTransfer, currency string) scheduledTransferResponse {
+	return scheduledTransferResponse{
+		ScheduledTransfer: scheduledTransfer,
+		AmountDecimal:     decimal(scheduledTransfer.Amount, currency),
+	}
+}
+
+type createScheduledTransferRequest struct {
+	FromAccountID int64     `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64     `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64     `json:"amount" binding:"min=0"`
+	AmountDecimal string    `json:"amount_decimal"`
+	Currency      string    `json:"currency" binding:"required,currency"`
+	Recurrence    string    `json:"recurrence" binding:"omitempty,recurrence"`
+	NextRunAt     time.Time `json:"next_run_at" binding:"required"`
+	MaxRetries    int32     `json:"max_retries" binding:"min=0,max=10"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	amount, err := requestAmount(req.Amount, req.AmountDecimal, req.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	fromAccount, valid := server.validAccountCurrency(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	_, valid = server.validAccount(ctx, req.ToAccountID)
+	if !valid {
+		return
+	}
+
+	//scheduled transfers are executed by the scheduler, nobody can approve them
+	if server.approvalRequired(ctx, amount, fromAccount.Currency) {
+		return
+	}
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        amount,
+		Recurrence:    req.Recurrence,
+		NextRunAt:     req.NextRunAt,
+		MaxRetries:    req.MaxRetries,
+		AnchorDay:     util.AnchorDay(req.NextRunAt),
+	}
+
+	scheduledTransfer, err := server.store.CreateScheduledTransfer(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduledTransfer, fromAccount.Currency))
+}
+
+type scheduledTransferRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var req scheduledTransferRequestID
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.validScheduledTransfer(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, scheduledTransfer.FromAccountID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduledTransfer, fromAccount.Currency))
+}
+
+type listScheduledTransfersRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ListScheduledTransfersParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	scheduledTransfers, err := server.store.ListScheduledTransfers(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	//schedules of a user usually share a few accounts, so load each one only once
+	currencies := make(map[int64]string)
+
+	rsp := make([]scheduledTransferResponse, len(scheduledTransfers))
+	for i, scheduledTransfer := range scheduledTransfers {
+		currency, ok := currencies[scheduledTransfer.FromAccountID]
+		if !ok {
+			fromAccount, valid := server.validAccount(ctx, scheduledTransfer.FromAccountID)
+			if !valid {
+				return
+			}
+
+			currency = fromAccount.Currency
+			currencies[fromAccount.ID] = currency
+		}
+
+		rsp[i] = newScheduledTransferResponse(scheduledTransfer, currency)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// updateScheduledTransferRequest takes the amount in the currency of the sender
+type updateScheduledTransferRequest struct {
+	Amount        int64     `json:"amount" binding:"min=0"`
+	AmountDecimal string    `json:"amount_decimal"`
+	Recurrence    string    `json:"recurrence" binding:"omitempty,recurrence"`
+	NextRunAt     time.Time `json:"next_run_at" binding:"required"`
+	MaxRetries    int32     `json:"max_retries" binding:"min=0,max=10"`
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var reqID scheduledTransferRequestID
+	var req updateScheduledTransferRequest
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.validScheduledTransfer(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	if scheduledTransfer.Status != util.ActiveSchedule {
+		ctx.JSON(http.StatusConflict, errorResponse(errScheduledTransferNotActive))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, scheduledTransfer.FromAccountID)
+	if !valid {
+		return
+	}
+
+	amount, err := requestAmount(req.Amount, req.AmountDecimal, fromAccount.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if server.approvalRequired(ctx, amount, fromAccount.Currency) {
+		return
+	}
+
+	arg := db.UpdateScheduledTransferParams{
+		ID:         scheduledTransfer.ID,
+		Amount:     amount,
+		Recurrence: req.Recurrence,
+		NextRunAt:  req.NextRunAt,
+		MaxRetries: req.MaxRetries,
+		AnchorDay:  util.AnchorDay(req.NextRunAt),
+	}
+
+	scheduledTransfer, err = server.store.UpdateScheduledTransfer(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduledTransfer, fromAccount.Currency))
+}
+
+func (server *Server) cancelScheduledTransfer(ctx *gin.Context) {
+	var req scheduledTransferRequestID
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.validScheduledTransfer(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	if scheduledTransfer.Status != util.ActiveSchedule {
+		ctx.JSON(http.StatusConflict, errorResponse(errScheduledTransferNotActive))
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, scheduledTransfer.FromAccountID)
+	if !valid {
+		return
+	}
+
+	arg := db.UpdateScheduledTransferStatusParams{
+		ID:     scheduledTransfer.ID,
+		Status: util.CancelledSchedule,
+	}
+
+	scheduledTransfer, err := server.store.UpdateScheduledTransferStatus(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newScheduledTransferResponse(scheduledTransfer, fromAccount.Currency))
+}
+
+type listScheduledTransferRunsRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var reqID scheduledTransferRequestID
+	var req listScheduledTransferRunsRequest
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduledTransfer, valid := server.validScheduledTransfer(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	arg := db.ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               req.PageSize,
+		Offset:              (req.PageID - 1) * req.PageSize,
+	}
+
+	runs, err := server.store.ListScheduledTransferRuns(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+// validScheduledTransfer loads the scheduled transfer and checks it belongs to the authenticated user
+func (server *Server) validScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	scheduledTransfer, err := server.store.GetScheduledTransfer(ctx, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduledTransfer, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != scheduledTransfer.Owner {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return scheduledTransfer, false
+	}
+
+	return scheduledTransfer, true
+}
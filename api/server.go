@@ -16,6 +16,7 @@ type Server struct {
 	config     util.Config
 	store      db.Store
 	tokenMaker token.Maker
+	broker     *AccountBroker
 	router     *gin.Engine
 }
 
@@ -30,10 +31,15 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 		config:     config,
 		store:      store,
 		tokenMaker: tokenMaker,
+		broker:     NewAccountBroker(),
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("recurrence", validRecurrence)
+		v.RegisterValidation("adjustment_reason", validAdjustmentReason)
+		v.RegisterValidation("event_type", validEventType)
+		v.RegisterValidation("webhook_url", validWebhookURL(config))
 	}
 
 	server.setupRouter()
@@ -44,6 +50,7 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 // setupRouter sets all routers for the server
 func (server *Server) setupRouter() {
 	router := gin.Default()
+	router.Use(auditMiddleware(server.store))
 
 	router.POST("/users", server.createUser)
 	router.POST("/users/login", server.loginUser)
@@ -55,19 +62,111 @@ func (server *Server) setupRouter() {
 	authRoutes.POST("/accounts", server.createAccount)
 	authRoutes.GET("/accounts", server.listAccounts)
 	authRoutes.GET("/accounts/:id", server.getAccount)
-	authRoutes.PUT("/accounts/:id", server.updateAccount)
-
+	authRoutes.GET("/accounts/:id/entries", server.getAccountStatement)
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
+	authRoutes.GET("/accounts/:id/transfers", server.listAccountTransfers)
+	authRoutes.POST("/accounts/:id/close", server.closeAccount)
+	authRoutes.GET("/accounts/:id/limits", server.getAccountLimits)
+	authRoutes.GET("/accounts/:id/interest", server.getAccountInterest)
+	authRoutes.GET("/accounts/:id/events", server.streamAccountEvents)
+
+	authRoutes.GET("/transfers", server.listTransfers)
 	authRoutes.POST("/transfers", server.createTransfer)
+	authRoutes.POST("/transfers/batch", server.createBatchTransfer)
+	authRoutes.GET("/pending-transfers/:id", server.getPendingTransfer)
+
+	authRoutes.POST("/holds", server.authorizeHold)
+	authRoutes.GET("/holds/:id", server.getHold)
+	authRoutes.POST("/holds/:id/capture", server.captureHold)
+	authRoutes.POST("/holds/:id/release", server.releaseHold)
+
+	authRoutes.POST("/scheduled-transfers", server.createScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers", server.listScheduledTransfers)
+	authRoutes.GET("/scheduled-transfers/:id", server.getScheduledTransfer)
+	authRoutes.PUT("/scheduled-transfers/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled-transfers/:id", server.cancelScheduledTransfer)
+	authRoutes.GET("/scheduled-transfers/:id/runs", server.listScheduledTransferRuns)
+
+	authRoutes.GET("/exchange-rates", server.listExchangeRates)
+
+	authRoutes.GET("/interest-products", server.listInterestProducts)
+
+	authRoutes.POST("/webhooks", server.createWebhook)
+	authRoutes.GET("/webhooks", server.listWebhooks)
+	authRoutes.GET("/webhooks/:id", server.getWebhook)
+	authRoutes.DELETE("/webhooks/:id", server.deleteWebhook)
+	authRoutes.POST("/webhooks/:id/test", server.testWebhook)
+	authRoutes.GET("/webhooks/:id/deliveries", server.listWebhookDeliveries)
+	authRoutes.GET("/webhooks/:id/deliveries/:delivery_id/attempts", server.listWebhookAttempts)
+
+	adminRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker), adminMiddleware())
+
+	adminRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	adminRoutes.POST("/accounts/:id/unfreeze", server.unfreezeAccount)
+	adminRoutes.PUT("/accounts/:id/interest", server.setAccountInterest)
+	adminRoutes.POST("/accounts/:id/adjustments", server.createAdjustment)
+	adminRoutes.GET("/accounts/:id/adjustments", server.listAdjustments)
+
+	adminRoutes.POST("/transfers/:id/reverse", server.reverseTransfer)
+	adminRoutes.GET("/pending-transfers", server.listPendingTransfers)
+	adminRoutes.POST("/transfers/:id/approve", server.approveTransfer)
+	adminRoutes.POST("/transfers/:id/reject", server.rejectTransfer)
+
+	adminRoutes.PUT("/transfer-limits", server.setTransferLimit)
+	adminRoutes.DELETE("/transfer-limits/:id", server.deleteTransferLimit)
+
+	adminRoutes.GET("/transfer-fees", server.listTransferFees)
+	adminRoutes.PUT("/transfer-fees", server.setTransferFee)
+	adminRoutes.DELETE("/transfer-fees/:id", server.deleteTransferFee)
+
+	adminRoutes.PUT("/exchange-rates/:from/:to", server.setExchangeRate)
+	adminRoutes.DELETE("/exchange-rates/:from/:to", server.deleteExchangeRate)
+
+	adminRoutes.POST("/interest-products", server.createInterestProduct)
+
+	adminRoutes.GET("/audit-logs", server.listAuditLogs)
 
 	server.router = router
 }
 
+// Broker returns the broker which wakes up event streams of accounts
+func (server *Server) Broker() *AccountBroker {
+	return server.broker
+}
+
 // Start run HTTP server on special address and port
 func (server *Server) Start() error {
 	return server.router.Run(server.config.ServerAddr)
 }
 
+// Machine-readable error codes returned together with the error message
+const (
+	errCodeInsufficientFunds     = "insufficient_funds"
+	errCodeExchangeRateNotFound  = "exchange_rate_not_found"
+	errCodeInvalidAmount         = "invalid_amount"
+	errCodeTransferNotReversible = "transfer_not_reversible"
+	errCodeReversalExceedsAmount = "reversal_exceeds_amount"
+	errCodeHoldNotActive         = "hold_not_active"
+	errCodeHoldExpired           = "hold_expired"
+	errCodeCaptureExceedsHold    = "capture_exceeds_hold"
+	errCodeAccountNotActive      = "account_not_active"
+	errCodeAccountTransition     = "account_transition_not_allowed"
+	errCodeAccountHasBalance     = "account_has_balance"
+	errCodeAccountHasHolds       = "account_has_holds"
+	errCodeTransferLimitExceeded = "transfer_limit_exceeded"
+	errCodeTransferNotPending    = "transfer_not_pending"
+	errCodeSelfApproval          = "self_approval"
+	errCodeApprovalRequired      = "approval_required"
+	errCodeVersionMismatch       = "version_mismatch"
+	errCodeSuspenseAdjustment    = "suspense_adjustment"
+)
+
 // errorResponse is error wrapper
 func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
+
+// errorCodeResponse is error wrapper with a code clients can rely on
+func errorCodeResponse(code string, err error) gin.H {
+	return gin.H{"code": code, "error": err.Error()}
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+)
+
+type getAccountStatementRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type getAccountStatementRequestQuery struct {
+	From time.Time `form:"from"`
+	To   time.Time `form:"to"`
+	pageRequest
+}
+
+// accountStatementResponse adds decimal strings in major units of the account currency to balances and entries
+type accountStatementResponse struct {
+	db.AccountStatementTxResult
+	OpeningBalanceDecimal string                  `json:"opening_balance_decimal"`
+	ClosingBalanceDecimal string                  `json:"closing_balance_decimal"`
+	Entries               []statementLineResponse `json:"entries"`
+	NextCursor            string                  `json:"next_cursor,omitempty"`
+}
+
+type statementLineResponse struct {
+	db.StatementLine
+	AmountDecimal  string `json:"amount_decimal"`
+	BalanceDecimal string `json:"balance_decimal"`
+}
+
+func newAccountStatementResponse(statement db.AccountStatementTxResult, currency string, nextCursor string) accountStatementResponse {
+	rsp := accountStatementResponse{
+		AccountStatementTxResult: statement,
+		OpeningBalanceDecimal:    decimal(statement.OpeningBalance, currency),
+		ClosingBalanceDecimal:    decimal(statement.ClosingBalance, currency),
+		Entries:                  make([]statementLineResponse, 0, len(statement.Entries)),
+		NextCursor:               nextCursor,
+	}
+
+	for _, line := range statement.Entries {
+		rsp.Entries = append(rsp.Entries, statementLineResponse{
+			StatementLine:  line,
+			AmountDecimal:  decimal(line.Amount, currency),
+			BalanceDecimal: decimal(line.Balance, currency),
+		})
+	}
+
+	return rsp
+}
+
+func (server *Server) getAccountStatement(ctx *gin.Context) {
+	var reqID getAccountStatementRequestID
+	var req getAccountStatementRequestQuery
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	after, err := server.after(req.pageRequest)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	//statement without end runs up to now
+	to := req.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	if !to.After(req.From) {
+		err := errors.New("statement must end after it starts")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	arg := db.AccountStatementTxParam{
+		AccountID:      account.ID,
+		From:           req.From,
+		To:             to,
+		Limit:          req.PageSize,
+		Offset:         req.offset(),
+		AfterCreatedAt: after.CreatedAt,
+		AfterID:        after.ID,
+	}
+
+	statement, err := server.store.AccountStatementTx(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	var last pageCursor
+	if len(statement.Entries) > 0 {
+		entry := statement.Entries[len(statement.Entries)-1]
+		last = pageCursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+	}
+
+	nextCursor, err := server.nextCursor(req.pageRequest, len(statement.Entries), last)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountStatementResponse(statement, account.Currency, nextCursor))
+}
@@ -1,23 +1,108 @@
 package api
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/gu3sswho/simplebank/db/sqlc"
 	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
 )
 
+const (
+	idempotencyKeyHeader    = "Idempotency-Key"
+	idempotencyKeyMaxLength = 255
+)
+
+// maxBatchLegs limits the number of transfers in one batch
+const maxBatchLegs = 1000
+
+// transferRequest takes the amount either in minor units of the currency or as a decimal string in major units
 type transferRequest struct {
 	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
 	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
+	Amount        int64  `json:"amount" binding:"min=0"`
+	AmountDecimal string `json:"amount_decimal,omitempty"`
 	Currency      string `json:"currency" binding:"required,currency"`
 }
 
+// transferResponse shows the result of a transfer together with its amounts as decimal strings in major units
+type transferResponse struct {
+	db.TransferTxResult
+	AmountDecimal   string `json:"amount_decimal"`
+	FeeDecimal      string `json:"fee_decimal"`
+	ToAmountDecimal string `json:"to_amount_decimal"`
+}
+
+func newTransferResponse(result db.TransferTxResult) transferResponse {
+	return transferResponse{
+		TransferTxResult: result,
+		AmountDecimal:    decimal(result.Transfer.Amount, result.FromAccount.Currency),
+		FeeDecimal:       decimal(result.Transfer.Fee, result.FromAccount.Currency),
+		ToAmountDecimal:  decimal(result.Transfer.ToAmount, result.ToAccount.Currency),
+	}
+}
+
+// Every request which takes an amount accepts it either in minor units of the currency or as a decimal string
+// in major units, and responses with accounts, transfers, holds, schedules, adjustments, limits, statements
+// and currency specific fees add the decimal amounts.
+// Lists of transfers are shown in minor units only, their rows don't carry the currencies of the accounts.
+
+// requestAmount returns the positive amount of a request in minor units of the currency
+// The amount is given either in minor units or as a decimal string like "12.34", but not both
+func requestAmount(amount int64, amountDecimal string, currency string) (int64, error) {
+	amount, err := optionalRequestAmount(amount, amountDecimal, currency)
+	if err != nil {
+		return 0, err
+	}
+
+	if amount <= 0 {
+		return 0, errors.New("amount must be positive")
+	}
+
+	return amount, nil
+}
+
+// optionalRequestAmount returns the amount of a request in minor units of the currency, zero if none is given
+// The sign is not checked, callers which take only positive amounts have to check it
+func optionalRequestAmount(amount int64, amountDecimal string, currency string) (int64, error) {
+	if amountDecimal == "" {
+		return amount, nil
+	}
+
+	if amount != 0 {
+		return 0, errors.New("amount and amount_decimal cannot be set together")
+	}
+
+	money, err := util.ParseMoney(amountDecimal, currency)
+	if err != nil {
+		return 0, err
+	}
+
+	return money.Amount, nil
+}
+
+// decimal formats the amount in minor units of the currency as a decimal string in major units
+func decimal(amount int64, currency string) string {
+	return util.NewMoney(amount, currency).Decimal()
+}
+
+// optionalDecimal formats an amount which may be absent, like an unlimited limit
+func optionalDecimal(amount *int64, currency string) *string {
+	if amount == nil {
+		return nil
+	}
+	formatted := decimal(*amount, currency)
+	return &formatted
+}
+
 func (server *Server) createTransfer(ctx *gin.Context) {
 	var req transferRequest
 
@@ -26,6 +111,21 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
+	amount, err := requestAmount(req.Amount, req.AmountDecimal, req.Currency)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	//the same transfer has the same idempotency fingerprint however its amount is given
+	req.Amount = amount
+	req.AmountDecimal = ""
+
+	idempotencyKey, valid := requestIdempotencyKey(ctx)
+	if !valid {
+		return
+	}
+
 	fromAccount, valid := server.validAccountCurrency(ctx, req.FromAccountID, req.Currency)
 	if !valid {
 		return
@@ -39,28 +139,502 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	_, valid = server.validAccountCurrency(ctx, req.ToAccountID, req.Currency)
+	//receiver may hold another currency, the amount is converted by TransferTx
+	_, valid = server.validAccount(ctx, req.ToAccountID)
 	if !valid {
 		return
 	}
 
+	if server.config.RequiresApproval(req.Amount, fromAccount.Currency) {
+		server.createPendingTransfer(ctx, authPayload.Username, idempotencyKey, req)
+		return
+	}
+
 	arg := db.TransferTxParam{
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
 	}
 
+	if idempotencyKey != "" {
+		requestHash, err := hashRequest(authPayload.Username, req)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		arg.IdempotencyKey = idempotencyKey
+		arg.KeyUsername = authPayload.Username
+		arg.RequestHash = requestHash
+		arg.KeyExpiredAt = time.Now().Add(server.config.IdempotencyKeyDuration)
+	}
+
 	transferTx, err := server.store.TransferTx(ctx, arg)
 
 	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrIdempotencyKeyReused):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, err))
+			return
+		case errors.Is(err, db.ErrTransferLimitExceeded):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeTransferLimitExceeded, err))
+			return
+		case errors.Is(err, db.ErrExchangeRateNotFound):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeExchangeRateNotFound, err))
+			return
+		case errors.Is(err, db.ErrAmountTooSmall), errors.Is(err, util.ErrAmountOverflow):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInvalidAmount, err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, transferTx)
+	ctx.JSON(http.StatusOK, newTransferResponse(transferTx))
 }
 
-func (server *Server) validAccountCurrency(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+type batchTransferLegRequest struct {
+	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
+	Amount        int64  `json:"amount" binding:"min=0"`
+	AmountDecimal string `json:"amount_decimal"`
+	Currency      string `json:"currency" binding:"required,currency"`
+}
+
+type batchTransferRequest struct {
+	Legs []batchTransferLegRequest `json:"legs" binding:"required,min=1,dive"`
+}
+
+// batchTransferResponse shows the result of every leg in the order of the legs
+type batchTransferResponse struct {
+	Transfers []transferResponse `json:"transfers"`
+}
+
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	var req batchTransferRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if len(req.Legs) > maxBatchLegs {
+		err := fmt.Errorf("batch must have at most %d legs", maxBatchLegs)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	//the same account usually appears in many legs, so load each one only once
+	accounts := make(map[int64]db.Account)
+
+	loadAccount := func(accountID int64) (db.Account, bool) {
+		if account, ok := accounts[accountID]; ok {
+			return account, true
+		}
+
+		account, valid := server.validAccount(ctx, accountID)
+		if valid {
+			accounts[accountID] = account
+		}
+
+		return account, valid
+	}
+
+	arg := db.BatchTransferTxParam{
+		Legs: make([]db.BatchTransferLeg, len(req.Legs)),
+	}
+
+	for i, leg := range req.Legs {
+		amount, err := requestAmount(leg.Amount, leg.AmountDecimal, leg.Currency)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("leg %d: %w", i, err)))
+			return
+		}
+
+		fromAccount, valid := loadAccount(leg.FromAccountID)
+		if !valid {
+			return
+		}
+
+		if fromAccount.Currency != leg.Currency {
+			err := fmt.Errorf("leg %d: account [%d] currency mismatch: %s vs %s", i, leg.FromAccountID, fromAccount.Currency, leg.Currency)
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		if fromAccount.Owner != authPayload.Username {
+			err := fmt.Errorf("leg %d: from account doesn't belong to the authenticated user", i)
+			ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		//receiver may hold another currency, the amount is converted by BatchTransferTx
+		if _, valid := loadAccount(leg.ToAccountID); !valid {
+			return
+		}
+
+		//batch is executed at once, so it can't wait for approval of one leg
+		if server.config.RequiresApproval(amount, fromAccount.Currency) {
+			err := fmt.Errorf("leg %d: amount above %d %s needs approval, send it as a single transfer",
+				i, server.config.ApprovalThresholds[fromAccount.Currency], fromAccount.Currency)
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeApprovalRequired, err))
+			return
+		}
+
+		arg.Legs[i] = db.BatchTransferLeg{
+			FromAccountID: leg.FromAccountID,
+			ToAccountID:   leg.ToAccountID,
+			Amount:        amount,
+		}
+	}
+
+	batchTx, err := server.store.BatchTransferTx(ctx, arg)
+
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, err))
+			return
+		case errors.Is(err, db.ErrTransferLimitExceeded):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeTransferLimitExceeded, err))
+			return
+		case errors.Is(err, db.ErrExchangeRateNotFound):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeExchangeRateNotFound, err))
+			return
+		case errors.Is(err, db.ErrAmountTooSmall), errors.Is(err, util.ErrAmountOverflow):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInvalidAmount, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := batchTransferResponse{Transfers: make([]transferResponse, len(batchTx.Transfers))}
+	for i, transfer := range batchTx.Transfers {
+		rsp.Transfers[i] = newTransferResponse(transfer)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type reverseTransferRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// reverseTransferRequestAmount takes the amount in the currency of the sender of the reversed transfer
+type reverseTransferRequestAmount struct {
+	Amount        int64  `json:"amount" binding:"min=0"`
+	AmountDecimal string `json:"amount_decimal"`
+}
+
+// reverseTransferResponse shows the reversal with its amounts as decimal strings in major units
+type reverseTransferResponse struct {
+	OriginalTransfer db.Transfer `json:"original_transfer"`
+	transferResponse
+}
+
+// reverseTransferIdempotentRequest is the fingerprinted part of a reversal, its shape differs from transferRequest
+type reverseTransferIdempotentRequest struct {
+	ReversalOf int64 `json:"reversal_of"`
+	Amount     int64 `json:"amount"`
+}
+
+func (server *Server) reverseTransfer(ctx *gin.Context) {
+	var reqID reverseTransferRequestID
+	var reqAmount reverseTransferRequestAmount
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	//request without body reverses the whole amount left
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&reqAmount); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	//decimal amount is in the currency of the reversed transfer, which is loaded only when it is needed
+	if reqAmount.AmountDecimal != "" {
+		currency, valid := server.transferCurrency(ctx, reqID.ID)
+		if !valid {
+			return
+		}
+
+		amount, err := optionalRequestAmount(reqAmount.Amount, reqAmount.AmountDecimal, currency)
+		if err == nil && amount <= 0 {
+			err = errors.New("amount must be positive")
+		}
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		reqAmount.Amount = amount
+		reqAmount.AmountDecimal = ""
+	}
+
+	idempotencyKey, valid := requestIdempotencyKey(ctx)
+	if !valid {
+		return
+	}
+
+	arg := db.ReverseTransferTxParam{
+		TransferID: reqID.ID,
+		Amount:     reqAmount.Amount,
+	}
+
+	if idempotencyKey != "" {
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+		requestHash, err := hashRequest(authPayload.Username, reverseTransferIdempotentRequest{
+			ReversalOf: reqID.ID,
+			Amount:     reqAmount.Amount,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		arg.IdempotencyKey = idempotencyKey
+		arg.KeyUsername = authPayload.Username
+		arg.RequestHash = requestHash
+		arg.KeyExpiredAt = time.Now().Add(server.config.IdempotencyKeyDuration)
+	}
+
+	reverseTx, err := server.store.ReverseTransferTx(ctx, arg)
+
+	if err != nil {
+		switch {
+		case err == sql.ErrNoRows:
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrIdempotencyKeyReused):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		case errors.Is(err, db.ErrTransferNotReversible):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeTransferNotReversible, err))
+			return
+		case errors.Is(err, db.ErrReversalExceedsAmount):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeReversalExceedsAmount, err))
+			return
+		case errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInsufficientFunds, err))
+			return
+		case errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeAccountNotActive, err))
+			return
+		case errors.Is(err, db.ErrAmountTooSmall), errors.Is(err, util.ErrAmountOverflow):
+			ctx.JSON(http.StatusUnprocessableEntity, errorCodeResponse(errCodeInvalidAmount, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reverseTransferResponse{
+		OriginalTransfer: reverseTx.OriginalTransfer,
+		transferResponse: newTransferResponse(reverseTx.TransferTxResult),
+	})
+}
+
+// transferCurrency returns the currency of the sender of the transfer, it responds with 404 if the transfer doesn't exist
+func (server *Server) transferCurrency(ctx *gin.Context, transferID int64) (string, bool) {
+	transfer, err := server.store.GetTransfer(ctx, transferID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return "", false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return "", false
+	}
+
+	fromAccount, valid := server.validAccount(ctx, transfer.FromAccountID)
+	if !valid {
+		return "", false
+	}
+
+	return fromAccount.Currency, true
+}
+
+// transferFilterRequest contain optional filters of transfer history
+// Direction is relative to the listed account or to all accounts of the user
+type transferFilterRequest struct {
+	Direction      string    `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+	CounterpartyID int64     `form:"counterparty_id" binding:"omitempty,min=1"`
+	MinAmount      int64     `form:"min_amount" binding:"omitempty,gt=0"`
+	MaxAmount      int64     `form:"max_amount" binding:"omitempty,gt=0"`
+	From           time.Time `form:"from"`
+	To             time.Time `form:"to"`
+	pageRequest
+}
+
+// listTransfersResponse is returned in keyset mode, page mode keeps returning the bare list
+type listTransfersResponse struct {
+	Transfers  []db.Transfer `json:"transfers"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// validate checks the filters which depend on each other
+func (req transferFilterRequest) validate() error {
+	if req.MinAmount != 0 && req.MaxAmount != 0 && req.MinAmount > req.MaxAmount {
+		return errors.New("min_amount must not be greater than max_amount")
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && !req.To.After(req.From) {
+		return errors.New("to must be after from")
+	}
+
+	return nil
+}
+
+func (server *Server) listTransfers(ctx *gin.Context) {
+	var req transferFilterRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	after, err := server.after(req.pageRequest)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ListOwnerTransfersParams{
+		Direction:      req.Direction,
+		Owner:          authPayload.Username,
+		CounterpartyID: nullInt64(req.CounterpartyID),
+		MinAmount:      nullInt64(req.MinAmount),
+		MaxAmount:      nullInt64(req.MaxAmount),
+		CreatedFrom:    nullTime(req.From),
+		CreatedTo:      nullTime(req.To),
+		AfterCreatedAt: nullTime(after.CreatedAt),
+		AfterID:        nullInt64(after.ID),
+		Limit:          req.PageSize,
+		Offset:         req.offset(),
+	}
+
+	transfers, err := server.store.ListOwnerTransfers(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.respondTransfers(ctx, req.pageRequest, transfers)
+}
+
+type listAccountTransfersRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) listAccountTransfers(ctx *gin.Context) {
+	var reqID listAccountTransfersRequestID
+	var req transferFilterRequest
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := req.validate(); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	after, err := server.after(req.pageRequest)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	arg := db.ListAccountTransfersParams{
+		Direction:      req.Direction,
+		AccountID:      account.ID,
+		CounterpartyID: nullInt64(req.CounterpartyID),
+		MinAmount:      nullInt64(req.MinAmount),
+		MaxAmount:      nullInt64(req.MaxAmount),
+		CreatedFrom:    nullTime(req.From),
+		CreatedTo:      nullTime(req.To),
+		AfterCreatedAt: nullTime(after.CreatedAt),
+		AfterID:        nullInt64(after.ID),
+		Limit:          req.PageSize,
+		Offset:         req.offset(),
+	}
+
+	transfers, err := server.store.ListAccountTransfers(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.respondTransfers(ctx, req.pageRequest, transfers)
+}
+
+// respondTransfers writes the bare list in page mode and the list with next cursor in keyset mode
+func (server *Server) respondTransfers(ctx *gin.Context, req pageRequest, transfers []db.Transfer) {
+	if !req.keyset() {
+		ctx.JSON(http.StatusOK, transfers)
+		return
+	}
+
+	var last pageCursor
+	if len(transfers) > 0 {
+		last = pageCursor{CreatedAt: transfers[len(transfers)-1].CreatedAt, ID: transfers[len(transfers)-1].ID}
+	}
+
+	nextCursor, err := server.nextCursor(req, len(transfers), last)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, listTransfersResponse{Transfers: transfers, NextCursor: nextCursor})
+}
+
+func (server *Server) validAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 
 	if err != nil {
@@ -73,6 +647,15 @@ func (server *Server) validAccountCurrency(ctx *gin.Context, accountID int64, cu
 		return account, false
 	}
 
+	return account, true
+}
+
+func (server *Server) validAccountCurrency(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
+	account, valid := server.validAccount(ctx, accountID)
+	if !valid {
+		return account, false
+	}
+
 	if account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
@@ -81,3 +664,50 @@ func (server *Server) validAccountCurrency(ctx *gin.Context, accountID int64, cu
 
 	return account, true
 }
+
+// requestIdempotencyKey returns the optional idempotency key of the request, it responds with 400 if the key is too long
+func requestIdempotencyKey(ctx *gin.Context) (string, bool) {
+	idempotencyKey := ctx.GetHeader(idempotencyKeyHeader)
+	if len(idempotencyKey) > idempotencyKeyMaxLength {
+		err := fmt.Errorf("%s header must be at most %d characters", idempotencyKeyHeader, idempotencyKeyMaxLength)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return "", false
+	}
+
+	return idempotencyKey, true
+}
+
+// hashRequest returns a fingerprint of the request used to detect reuse of an idempotency key
+// Requests of different endpoints must have different shapes, so the same key can't replay a response of another endpoint
+func hashRequest(username string, req interface{}) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(username))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// nullInt64 treats zero as an absent filter
+func nullInt64(value int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: value, Valid: value != 0}
+}
+
+// nullInt32 treats zero as an absent filter
+func nullInt32(value int32) sql.NullInt32 {
+	return sql.NullInt32{Int32: value, Valid: value != 0}
+}
+
+// nullString treats empty string as an absent filter
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+// nullTime treats zero time as an absent filter
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// setTransferFeeRequest sets the fee of transfers from accounts in the currency,
+// or the default fee of every currency which has no own fee when currency is empty
+// Zero MaxFee means no maximum
+type setTransferFeeRequest struct {
+	Currency string `json:"currency" binding:"omitempty,currency"`
+	Flat     int64  `json:"flat" binding:"min=0"`
+	Percent  string `json:"percent"`
+	MinFee   int64  `json:"min_fee" binding:"min=0"`
+	MaxFee   int64  `json:"max_fee" binding:"min=0"`
+}
+
+// transferFeeResponse adds decimal strings in major units to fees of one currency
+// The default fee applies to every currency, so its amounts stay in minor units only
+type transferFeeResponse struct {
+	db.TransferFee
+	FlatDecimal   string `json:"flat_decimal,omitempty"`
+	MinFeeDecimal string `json:"min_fee_decimal,omitempty"`
+	MaxFeeDecimal string `json:"max_fee_decimal,omitempty"`
+}
+
+func newTransferFeeResponse(fee db.TransferFee) transferFeeResponse {
+	rsp := transferFeeResponse{TransferFee: fee}
+	if fee.Currency != "" {
+		rsp.FlatDecimal = decimal(fee.Flat, fee.Currency)
+		rsp.MinFeeDecimal = decimal(fee.MinFee, fee.Currency)
+		rsp.MaxFeeDecimal = decimal(fee.MaxFee, fee.Currency)
+	}
+	return rsp
+}
+
+func (server *Server) setTransferFee(ctx *gin.Context) {
+	var req setTransferFeeRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.Percent == "" {
+		req.Percent = "0"
+	}
+
+	if _, err := util.ParseFeePercent(req.Percent); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.MaxFee > 0 && req.MaxFee < req.MinFee {
+		err := errors.New("max_fee must not be below min_fee")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.SetTransferFeeParams{
+		Currency: req.Currency,
+		Flat:     req.Flat,
+		Percent:  req.Percent,
+		MinFee:   req.MinFee,
+		MaxFee:   req.MaxFee,
+	}
+
+	fee, err := server.store.SetTransferFee(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTransferFeeResponse(fee))
+}
+
+func (server *Server) listTransferFees(ctx *gin.Context) {
+	fees, err := server.store.ListTransferFees(ctx)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]transferFeeResponse, 0, len(fees))
+	for _, fee := range fees {
+		rsp = append(rsp, newTransferFeeResponse(fee))
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+type deleteTransferFeeRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteTransferFee(ctx *gin.Context) {
+	var req deleteTransferFeeRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	deleted, err := server.store.DeleteTransferFee(ctx, req.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTransferFeeAPI(t *testing.T) {
+	admin, _ := createRandomUser(t)
+
+	testCases := []struct {
+		name          string
+		role          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "CurrencyFee",
+			role: util.AdminRole,
+			body: gin.H{
+				"currency": util.EUR,
+				"flat":     10,
+				"percent":  "1.5",
+				"min_fee":  20,
+				"max_fee":  500,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SetTransferFeeParams{
+					Currency: util.EUR,
+					Flat:     10,
+					Percent:  "1.5",
+					MinFee:   20,
+					MaxFee:   500,
+				}
+
+				store.EXPECT().
+					SetTransferFee(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferFee{ID: 1, Currency: arg.Currency, Flat: arg.Flat, Percent: arg.Percent, MinFee: arg.MinFee, MaxFee: arg.MaxFee}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var fee transferFeeResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &fee)
+				require.NoError(t, err)
+				require.Equal(t, "1.5", fee.Percent)
+				require.Equal(t, "0.10", fee.FlatDecimal)
+				require.Equal(t, "0.20", fee.MinFeeDecimal)
+				require.Equal(t, "5.00", fee.MaxFeeDecimal)
+			},
+		},
+		{
+			name: "DefaultFlatFee",
+			role: util.AdminRole,
+			body: gin.H{
+				"flat": 25,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SetTransferFeeParams{
+					Flat:    25,
+					Percent: "0",
+				}
+
+				store.EXPECT().
+					SetTransferFee(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferFee{ID: 2, Flat: arg.Flat, Percent: arg.Percent}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				//the default fee has no currency to format its amounts in
+				var fee transferFeeResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &fee)
+				require.NoError(t, err)
+				require.Equal(t, int64(25), fee.Flat)
+				require.Empty(t, fee.FlatDecimal)
+			},
+		},
+		{
+			name: "InvalidPercent",
+			role: util.AdminRole,
+			body: gin.H{
+				"percent": "150",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetTransferFee(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "MaxBelowMin",
+			role: util.AdminRole,
+			body: gin.H{
+				"percent": "1",
+				"min_fee": 100,
+				"max_fee": 50,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetTransferFee(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			role: util.AdminRole,
+			body: gin.H{
+				"currency": "XYZ",
+				"flat":     10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetTransferFee(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			role: util.DepositorRole,
+			body: gin.H{
+				"flat": 10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetTransferFee(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/transfer-fees", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, tc.role, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/transfer-fees")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
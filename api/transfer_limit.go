@@ -0,0 +1,241 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/lib/pq"
+)
+
+// setTransferLimitRequest sets limit of one account, of all accounts of a user in the currency,
+// or the default limit of accounts in the currency when neither account nor owner is given
+// Absent values mean unlimited
+type setTransferLimitRequest struct {
+	AccountID   int64  `json:"account_id" binding:"omitempty,min=1"`
+	Owner       string `json:"owner"`
+	Currency    string `json:"currency" binding:"required,currency"`
+	MaxAmount   *int64 `json:"max_amount" binding:"omitempty,gt=0"`
+	DailyAmount *int64 `json:"daily_amount" binding:"omitempty,gt=0"`
+	DailyCount  *int32 `json:"daily_count" binding:"omitempty,gt=0"`
+}
+
+func (server *Server) setTransferLimit(ctx *gin.Context) {
+	var req setTransferLimitRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.AccountID != 0 && req.Owner != "" {
+		err := errors.New("limit must be set either for an account or for an owner")
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if req.AccountID != 0 {
+		if _, valid := server.validAccountCurrency(ctx, req.AccountID, req.Currency); !valid {
+			return
+		}
+	}
+
+	arg := db.SetTransferLimitParams{
+		AccountID: nullInt64(req.AccountID),
+		Owner:     sql.NullString{String: req.Owner, Valid: req.Owner != ""},
+		Currency:  req.Currency,
+	}
+
+	if req.MaxAmount != nil {
+		arg.MaxAmount = sql.NullInt64{Int64: *req.MaxAmount, Valid: true}
+	}
+
+	if req.DailyAmount != nil {
+		arg.DailyAmount = sql.NullInt64{Int64: *req.DailyAmount, Valid: true}
+	}
+
+	if req.DailyCount != nil {
+		arg.DailyCount = sql.NullInt32{Int32: *req.DailyCount, Valid: true}
+	}
+
+	limit, err := server.store.SetTransferLimit(ctx, arg)
+
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, limit)
+}
+
+type deleteTransferLimitRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteTransferLimit(ctx *gin.Context) {
+	var req deleteTransferLimitRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	deleted, err := server.store.DeleteTransferLimit(ctx, req.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// transferLimitResponse shows one limit of the account with its usage, nil values are unlimited
+// Amounts are in the currency of the account, also as decimal strings in major units
+type transferLimitResponse struct {
+	ID                          int64   `json:"id"`
+	Scope                       string  `json:"scope"`
+	MaxAmount                   *int64  `json:"max_amount"`
+	MaxAmountDecimal            *string `json:"max_amount_decimal"`
+	DailyAmount                 *int64  `json:"daily_amount"`
+	DailyAmountDecimal          *string `json:"daily_amount_decimal"`
+	DailyAmountUsed             int64   `json:"daily_amount_used"`
+	DailyAmountUsedDecimal      string  `json:"daily_amount_used_decimal"`
+	DailyAmountRemaining        *int64  `json:"daily_amount_remaining"`
+	DailyAmountRemainingDecimal *string `json:"daily_amount_remaining_decimal"`
+	DailyCount                  *int64  `json:"daily_count"`
+	DailyCountUsed              int64   `json:"daily_count_used"`
+	DailyCountRemaining         *int64  `json:"daily_count_remaining"`
+	// transfers in these currencies have no rate to the limit currency, so their amounts are not in DailyAmountUsed
+	UnconvertedCurrencies []string `json:"unconverted_currencies,omitempty"`
+}
+
+// accountLimitsResponse shows limits of the account and the headroom left by all of them together
+type accountLimitsResponse struct {
+	AccountID int64                   `json:"account_id"`
+	Currency  string                  `json:"currency"`
+	Since     time.Time               `json:"since"`
+	Limits    []transferLimitResponse `json:"limits"`
+	// largest transfer the account can make now
+	MaxTransferAmount        *int64  `json:"max_transfer_amount"`
+	MaxTransferAmountDecimal *string `json:"max_transfer_amount_decimal"`
+	// number of transfers the account can make now
+	RemainingCount *int64 `json:"remaining_count"`
+}
+
+type getAccountLimitsRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getAccountLimits(ctx *gin.Context) {
+	var req getAccountLimitsRequest
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.validAccount(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != account.Owner {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return
+	}
+
+	limitsTx, err := server.store.TransferLimitsTx(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountLimitsResponse(limitsTx))
+}
+
+// newAccountLimitsResponse computes remaining headroom of every limit and the tightest of them
+func newAccountLimitsResponse(limitsTx db.TransferLimitsTxResult) accountLimitsResponse {
+	rsp := accountLimitsResponse{
+		AccountID: limitsTx.Account.ID,
+		Currency:  limitsTx.Account.Currency,
+		Since:     limitsTx.Since,
+		Limits:    make([]transferLimitResponse, 0, len(limitsTx.Limits)),
+	}
+
+	for _, usage := range limitsTx.Limits {
+		limit := transferLimitResponse{
+			ID:                     usage.Limit.ID,
+			Scope:                  usage.Scope,
+			DailyAmountUsed:        usage.UsedAmount,
+			DailyAmountUsedDecimal: decimal(usage.UsedAmount, rsp.Currency),
+			DailyCountUsed:         usage.UsedCount,
+			UnconvertedCurrencies:  usage.UnconvertedCurrencies,
+		}
+
+		if usage.Limit.MaxAmount.Valid {
+			limit.MaxAmount = &usage.Limit.MaxAmount.Int64
+			limit.MaxAmountDecimal = optionalDecimal(limit.MaxAmount, rsp.Currency)
+			rsp.MaxTransferAmount = lowerLimit(rsp.MaxTransferAmount, usage.Limit.MaxAmount.Int64)
+		}
+
+		if usage.Limit.DailyAmount.Valid {
+			remaining := remainingLimit(usage.Limit.DailyAmount.Int64, usage.UsedAmount)
+			limit.DailyAmount = &usage.Limit.DailyAmount.Int64
+			limit.DailyAmountRemaining = &remaining
+			limit.DailyAmountDecimal = optionalDecimal(limit.DailyAmount, rsp.Currency)
+			limit.DailyAmountRemainingDecimal = optionalDecimal(limit.DailyAmountRemaining, rsp.Currency)
+			rsp.MaxTransferAmount = lowerLimit(rsp.MaxTransferAmount, remaining)
+		}
+
+		if usage.Limit.DailyCount.Valid {
+			dailyCount := int64(usage.Limit.DailyCount.Int32)
+			remaining := remainingLimit(dailyCount, usage.UsedCount)
+			limit.DailyCount = &dailyCount
+			limit.DailyCountRemaining = &remaining
+			rsp.RemainingCount = lowerLimit(rsp.RemainingCount, remaining)
+		}
+
+		rsp.Limits = append(rsp.Limits, limit)
+	}
+
+	//no transfers are left so no amount can be sent either
+	if rsp.RemainingCount != nil && *rsp.RemainingCount == 0 {
+		rsp.MaxTransferAmount = lowerLimit(rsp.MaxTransferAmount, 0)
+	}
+
+	rsp.MaxTransferAmountDecimal = optionalDecimal(rsp.MaxTransferAmount, rsp.Currency)
+
+	return rsp
+}
+
+// remainingLimit returns the part of the limit which is not used, it is never negative
+func remainingLimit(limit int64, used int64) int64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+// lowerLimit returns the lower of two limits where nil is unlimited
+func lowerLimit(current *int64, value int64) *int64 {
+	if current != nil && *current <= value {
+		return current
+	}
+	return &value
+}
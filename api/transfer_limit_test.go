@@ -0,0 +1,336 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountLimitsAPI(t *testing.T) {
+	user, _ := createRandomUser(t)
+	otherUser, _ := createRandomUser(t)
+
+	account := createRandomAccount(user.Username)
+	account.Currency = util.USD
+
+	limitsTx := db.TransferLimitsTxResult{
+		Account: account,
+		Since:   time.Now().Add(-db.TransferLimitWindow),
+		Limits: []db.TransferLimitUsage{
+			{
+				Scope: db.AccountLimitScope,
+				Limit: db.TransferLimit{
+					ID:          util.RandomInt(1, 1000),
+					AccountID:   sql.NullInt64{Int64: account.ID, Valid: true},
+					Currency:    account.Currency,
+					MaxAmount:   sql.NullInt64{Int64: 500, Valid: true},
+					DailyAmount: sql.NullInt64{Int64: 1000, Valid: true},
+				},
+				UsedAmount: 700,
+				UsedCount:  3,
+			},
+			{
+				Scope: db.UserLimitScope,
+				Limit: db.TransferLimit{
+					ID:         util.RandomInt(1, 1000),
+					Owner:      sql.NullString{String: user.Username, Valid: true},
+					Currency:   account.Currency,
+					DailyCount: sql.NullInt32{Int32: 5, Valid: true},
+				},
+				UsedAmount:            900,
+				UsedCount:             4,
+				UnconvertedCurrencies: []string{"XYZ"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					TransferLimitsTx(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(limitsTx, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp accountLimitsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+
+				require.Equal(t, account.ID, rsp.AccountID)
+				require.Len(t, rsp.Limits, 2)
+
+				require.Equal(t, db.AccountLimitScope, rsp.Limits[0].Scope)
+				require.Equal(t, int64(300), *rsp.Limits[0].DailyAmountRemaining)
+				require.Equal(t, "3.00", *rsp.Limits[0].DailyAmountRemainingDecimal)
+				require.Equal(t, "5.00", *rsp.Limits[0].MaxAmountDecimal)
+				require.Equal(t, "7.00", rsp.Limits[0].DailyAmountUsedDecimal)
+				require.Nil(t, rsp.Limits[0].DailyCountRemaining)
+
+				require.Equal(t, db.UserLimitScope, rsp.Limits[1].Scope)
+				require.Nil(t, rsp.Limits[1].MaxAmount)
+				require.Nil(t, rsp.Limits[1].MaxAmountDecimal)
+				require.Equal(t, int64(1), *rsp.Limits[1].DailyCountRemaining)
+				require.Equal(t, []string{"XYZ"}, rsp.Limits[1].UnconvertedCurrencies)
+				require.Empty(t, rsp.Limits[0].UnconvertedCurrencies)
+
+				//the remaining daily amount is lower than the max amount
+				require.Equal(t, int64(300), *rsp.MaxTransferAmount)
+				require.Equal(t, "3.00", *rsp.MaxTransferAmountDecimal)
+				require.Equal(t, int64(1), *rsp.RemainingCount)
+			},
+		},
+		{
+			name:     "NoLimits",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().
+					TransferLimitsTx(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(db.TransferLimitsTxResult{Account: account, Limits: []db.TransferLimitUsage{}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var rsp accountLimitsResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &rsp)
+				require.NoError(t, err)
+
+				require.Empty(t, rsp.Limits)
+				require.Nil(t, rsp.MaxTransferAmount)
+				require.Nil(t, rsp.MaxTransferAmountDecimal)
+				require.Nil(t, rsp.RemainingCount)
+			},
+		},
+		{
+			name:     "UnauthorizedUser",
+			username: otherUser.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().TransferLimitsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:     "AccountNotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferLimitsTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+			url := fmt.Sprintf("/accounts/%d/limits", account.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, util.DepositorRole, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetTransferLimitAPI(t *testing.T) {
+	admin, _ := createRandomUser(t)
+	user, _ := createRandomUser(t)
+
+	account := createRandomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		role          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "AccountLimit",
+			role: util.AdminRole,
+			body: gin.H{
+				"account_id": account.ID,
+				"currency":   account.Currency,
+				"max_amount": 500,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+
+				arg := db.SetTransferLimitParams{
+					AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+					Currency:  account.Currency,
+					MaxAmount: sql.NullInt64{Int64: 500, Valid: true},
+				}
+
+				store.EXPECT().
+					SetTransferLimit(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferLimit{ID: 1, AccountID: arg.AccountID, Currency: arg.Currency, MaxAmount: arg.MaxAmount}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UserLimit",
+			role: util.AdminRole,
+			body: gin.H{
+				"owner":        user.Username,
+				"currency":     util.USD,
+				"daily_amount": 1000,
+				"daily_count":  10,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.SetTransferLimitParams{
+					Owner:       sql.NullString{String: user.Username, Valid: true},
+					Currency:    util.USD,
+					DailyAmount: sql.NullInt64{Int64: 1000, Valid: true},
+					DailyCount:  sql.NullInt32{Int32: 10, Valid: true},
+				}
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().
+					SetTransferLimit(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return(db.TransferLimit{ID: 2}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			role: util.AdminRole,
+			body: gin.H{
+				"owner":    "unknown",
+				"currency": util.USD,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().
+					SetTransferLimit(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.TransferLimit{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "AccountAndOwner",
+			role: util.AdminRole,
+			body: gin.H{
+				"account_id": account.ID,
+				"owner":      user.Username,
+				"currency":   account.Currency,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeLimit",
+			role: util.AdminRole,
+			body: gin.H{
+				"currency":    util.USD,
+				"daily_count": -1,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			role: util.DepositorRole,
+			body: gin.H{
+				"currency":   util.USD,
+				"max_amount": 500,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			//build stubs
+			tc.buildStubs(store)
+
+			//start HTTP server and build request
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/transfer-limits", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, tc.role, time.Minute)
+			checkAudit := expectAuditLog(t, store, "/transfer-limits")
+			server.router.ServeHTTP(recorder, request)
+			checkAudit(request, recorder)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
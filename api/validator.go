@@ -11,3 +11,34 @@ var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
 	}
 	return false
 }
+
+var validRecurrence validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if recurrence, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedRecurrence(recurrence)
+	}
+	return false
+}
+
+var validAdjustmentReason validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if reason, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedAdjustmentReason(reason)
+	}
+	return false
+}
+
+var validEventType validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if eventType, ok := fieldLevel.Field().Interface().(string); ok {
+		return util.IsSupportedEventType(eventType)
+	}
+	return false
+}
+
+// validWebhookURL accepts absolute https URLs of public hosts, plain http is accepted in development only
+func validWebhookURL(config util.Config) validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		if rawURL, ok := fieldLevel.Field().Interface().(string); ok {
+			return util.ValidateWebhookURL(rawURL, config.IsDevelopment()) == nil
+		}
+		return false
+	}
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/token"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// webhookResponse shows the webhook without its secret, which is only returned when the webhook is created
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	Owner      string    `json:"owner"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"eventTypes"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func newWebhookResponse(webhook db.Webhook) webhookResponse {
+	return webhookResponse{
+		ID:         webhook.ID,
+		Owner:      webhook.Owner,
+		URL:        webhook.Url,
+		EventTypes: webhook.EventTypes,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+type createWebhookResponse struct {
+	webhookResponse
+	Secret string `json:"secret"`
+}
+
+type createWebhookRequest struct {
+	URL        string   `json:"url" binding:"required,max=2048,webhook_url"`
+	EventTypes []string `json:"event_types" binding:"omitempty,max=10,dive,event_type"`
+}
+
+// createWebhook subscribes the URL to events of accounts of the authenticated user, no event types means all of them
+func (server *Server) createWebhook(ctx *gin.Context) {
+	var req createWebhookRequest
+
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret, err := util.NewWebhookSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	eventTypes := req.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.CreateWebhookParams{
+		Owner:      authPayload.Username,
+		Url:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	}
+
+	webhook, err := server.store.CreateWebhook(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createWebhookResponse{
+		webhookResponse: newWebhookResponse(webhook),
+		Secret:          webhook.Secret,
+	})
+}
+
+type webhookRequestID struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getWebhook(ctx *gin.Context) {
+	var req webhookRequestID
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	webhook, valid := server.validWebhook(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(webhook))
+}
+
+type listWebhooksRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+func (server *Server) listWebhooks(ctx *gin.Context) {
+	var req listWebhooksRequest
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+
+	arg := db.ListWebhooksParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	}
+
+	webhooks, err := server.store.ListWebhooks(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := make([]webhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		rsp[i] = newWebhookResponse(webhook)
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
+
+// deleteWebhook unsubscribes the webhook, its pending deliveries and the delivery log are deleted with it
+func (server *Server) deleteWebhook(ctx *gin.Context) {
+	var req webhookRequestID
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	webhook, valid := server.validWebhook(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	deleted, err := server.store.DeleteWebhook(ctx, webhook.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if deleted == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{})
+}
+
+// testWebhook queues a webhook.test delivery carrying the webhook, so the owner can check the receiver and its signature verification
+// The delivery is sent and retried like any other, its outcome shows up in the delivery log
+func (server *Server) testWebhook(ctx *gin.Context) {
+	var req webhookRequestID
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	webhook, valid := server.validWebhook(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	payload, err := json.Marshal(newWebhookResponse(webhook))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateWebhookDeliveryParams{
+		WebhookID: webhook.ID,
+		EventType: util.WebhookTestEvent,
+		Payload:   payload,
+	}
+
+	delivery, err := server.store.CreateWebhookDelivery(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, delivery)
+}
+
+type listWebhookDeliveriesRequest struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listWebhookDeliveries returns the delivery log of the webhook, newest deliveries first
+func (server *Server) listWebhookDeliveries(ctx *gin.Context) {
+	var reqID webhookRequestID
+	var req listWebhookDeliveriesRequest
+
+	if err := ctx.ShouldBindUri(&reqID); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	webhook, valid := server.validWebhook(ctx, reqID.ID)
+	if !valid {
+		return
+	}
+
+	arg := db.ListWebhookDeliveriesParams{
+		WebhookID: webhook.ID,
+		Limit:     req.PageSize,
+		Offset:    (req.PageID - 1) * req.PageSize,
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, arg)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+type webhookDeliveryRequestID struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+// listWebhookAttempts returns every attempt to send the delivery with the response of the receiver
+func (server *Server) listWebhookAttempts(ctx *gin.Context) {
+	var req webhookDeliveryRequestID
+
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	webhook, valid := server.validWebhook(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	delivery, err := server.store.GetWebhookDelivery(ctx, req.DeliveryID)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if delivery.WebhookID != webhook.ID {
+		ctx.JSON(http.StatusNotFound, errorResponse(sql.ErrNoRows))
+		return
+	}
+
+	attempts, err := server.store.ListWebhookAttempts(ctx, delivery.ID)
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attempts)
+}
+
+// validWebhook loads the webhook and checks it belongs to the authenticated user
+func (server *Server) validWebhook(ctx *gin.Context, id int64) (db.Webhook, bool) {
+	webhook, err := server.store.GetWebhook(ctx, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return webhook, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return webhook, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if authPayload.Username != webhook.Owner {
+		err := errors.New("webhook doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		return webhook, false
+	}
+
+	return webhook, true
+}
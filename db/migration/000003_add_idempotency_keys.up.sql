@@ -0,0 +1,11 @@
+CREATE TABLE "idempotency_keys" (
+  "key" varchar PRIMARY KEY,
+  "request_hash" varchar NOT NULL,
+  "response" jsonb NOT NULL DEFAULT '{}',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "idempotency_keys" ("expired_at");
+
+COMMENT ON COLUMN "idempotency_keys"."request_hash" IS 'hash of the request the key was first used with';
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "balance_non_negative";
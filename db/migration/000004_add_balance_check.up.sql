@@ -0,0 +1 @@
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_non_negative" CHECK ("balance" >= 0);
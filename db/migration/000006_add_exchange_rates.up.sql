@@ -0,0 +1,25 @@
+CREATE TABLE "exchange_rates" (
+  "from_currency" varchar NOT NULL,
+  "to_currency" varchar NOT NULL,
+  "rate" numeric NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("from_currency", "to_currency")
+);
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS 'amount of to_currency for one unit of from_currency';
+
+ALTER TABLE "exchange_rates" ADD CONSTRAINT "rate_positive" CHECK ("rate" > 0);
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+
+UPDATE "transfers" SET "to_amount" = "amount";
+
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" numeric NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD COLUMN "rounding" numeric NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "transfers"."to_amount" IS 'amount credited to the target account in its currency';
+
+COMMENT ON COLUMN "transfers"."rounding" IS 'exact converted amount minus to_amount';
@@ -0,0 +1,13 @@
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint;
+
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'transfer reversed by this one';
+
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'part of amount returned by reversals';
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD CONSTRAINT "reversed_amount_within_amount" CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "amount");
+
+CREATE INDEX ON "transfers" ("reversal_of");
@@ -0,0 +1,14 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer which created the entry, not a foreign key so reconciliation can report dangling links';
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+-- entries and their transfer are created in one transaction, so they share created_at
+UPDATE "entries" e
+  SET "transfer_id" = t."id"
+FROM "transfers" t
+WHERE e."transfer_id" IS NULL
+  AND e."created_at" = t."created_at"
+  AND ((e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+    OR (e."account_id" = t."to_account_id" AND e."amount" = t."to_amount"));
@@ -0,0 +1,29 @@
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint,
+  "owner" varchar,
+  "currency" varchar NOT NULL,
+  "max_amount" bigint,
+  "daily_amount" bigint,
+  "daily_count" int,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "transfer_limit_single_scope" CHECK ("account_id" IS NULL OR "owner" IS NULL)
+);
+
+CREATE UNIQUE INDEX ON "transfer_limits" ((COALESCE("account_id", 0)), (COALESCE("owner", '')), "currency");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "transfer_limits"."account_id" IS 'limit of one account, null for a user limit or a currency default';
+
+COMMENT ON COLUMN "transfer_limits"."owner" IS 'limit of all accounts of the user in the currency';
+
+COMMENT ON COLUMN "transfer_limits"."max_amount" IS 'largest single transfer, null means unlimited';
+
+COMMENT ON COLUMN "transfer_limits"."daily_amount" IS 'outgoing total over the last 24 hours, null means unlimited';
+
+COMMENT ON COLUMN "transfer_limits"."daily_count" IS 'number of outgoing transfers over the last 24 hours, null means unlimited';
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
@@ -0,0 +1,33 @@
+CREATE TABLE "pending_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending_approval',
+  "created_by" varchar NOT NULL,
+  "decided_by" varchar,
+  "decided_at" timestamptz,
+  "reason" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "pending_transfers" ("status", "id");
+
+COMMENT ON COLUMN "pending_transfers"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "pending_transfers"."status" IS 'pending_approval, approved or rejected';
+
+COMMENT ON COLUMN "pending_transfers"."decided_by" IS 'user who approved or rejected the transfer, never the creator';
+
+COMMENT ON COLUMN "pending_transfers"."transfer_id" IS 'transfer made on approval';
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("created_by") REFERENCES "users" ("username");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("decided_by") REFERENCES "users" ("username");
+
+ALTER TABLE "pending_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
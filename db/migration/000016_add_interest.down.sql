@@ -0,0 +1,9 @@
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "balance_non_negative";
+
+ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "balance_non_negative" CHECK ("balance" >= 0) NOT VALID;
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "account_interest";
+
+DROP TABLE IF EXISTS "interest_products";
@@ -0,0 +1,62 @@
+CREATE TABLE "interest_products" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar UNIQUE NOT NULL,
+  "currency" varchar NOT NULL,
+  "annual_rate" varchar NOT NULL,
+  "compounding" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_interest" (
+  "account_id" bigint PRIMARY KEY,
+  "product_id" bigint NOT NULL,
+  "accrued" varchar NOT NULL DEFAULT '0',
+  "accrued_through" date NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "basis" bigint NOT NULL,
+  "amount" varchar NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE INDEX ON "account_interest" ("accrued_through");
+
+COMMENT ON COLUMN "interest_products"."annual_rate" IS 'decimal fraction, 0.05 is 5% a year';
+
+COMMENT ON COLUMN "interest_products"."compounding" IS 'daily or monthly';
+
+COMMENT ON COLUMN "account_interest"."accrued" IS 'exact interest accrued and not posted yet';
+
+COMMENT ON COLUMN "account_interest"."accrued_through" IS 'last day interest is accrued for';
+
+COMMENT ON COLUMN "interest_accruals"."basis" IS 'amount interest is accrued on at the end of the day';
+
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'exact interest of the day';
+
+COMMENT ON COLUMN "interest_accruals"."transfer_id" IS 'transfer which posted interest at the end of the month';
+
+ALTER TABLE "account_interest" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_interest" ADD FOREIGN KEY ("product_id") REFERENCES "interest_products" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- system users own the bank accounts of the ledger, their names can't be registered through the API
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('system:interest_expense', '', 'Interest expense', 'interest_expense@system.simplebank')
+ON CONFLICT DO NOTHING;
+
+-- expense accounts pay out more than they receive, so only system accounts may go below zero
+ALTER TABLE "accounts" DROP CONSTRAINT "balance_non_negative";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "balance_non_negative" CHECK ("balance" >= 0 OR "owner" LIKE 'system:%');
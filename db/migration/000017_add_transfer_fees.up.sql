@@ -0,0 +1,31 @@
+CREATE TABLE "transfer_fees" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar UNIQUE NOT NULL,
+  "flat" bigint NOT NULL DEFAULT 0,
+  "percent" varchar NOT NULL DEFAULT '0',
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD COLUMN "fee_account_id" bigint;
+
+COMMENT ON COLUMN "transfer_fees"."currency" IS 'currency of the sender, empty for the default of every currency';
+
+COMMENT ON COLUMN "transfer_fees"."percent" IS 'percent of the amount, 1.5 is 1.5%';
+
+COMMENT ON COLUMN "transfer_fees"."max_fee" IS '0 means no maximum';
+
+COMMENT ON COLUMN "transfers"."fee" IS 'debited from the sender on top of the amount';
+
+COMMENT ON COLUMN "transfers"."fee_account_id" IS 'account the fee is credited to';
+
+ALTER TABLE "transfer_fees" ADD CONSTRAINT "transfer_fee_non_negative" CHECK ("flat" >= 0 AND "min_fee" >= 0 AND "max_fee" >= 0);
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("fee_account_id") REFERENCES "accounts" ("id");
+
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('system:fee_income', '', 'Fee income', 'fee_income@system.simplebank')
+ON CONFLICT DO NOTHING;
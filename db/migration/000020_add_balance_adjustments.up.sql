@@ -0,0 +1,38 @@
+CREATE TABLE "adjustments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "suspense_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reason" varchar NOT NULL,
+  "reference" varchar NOT NULL,
+  "adjusted_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entries" ADD COLUMN "adjustment_id" bigint;
+
+CREATE INDEX ON "adjustments" ("account_id");
+
+CREATE INDEX ON "entries" ("adjustment_id");
+
+COMMENT ON COLUMN "adjustments"."suspense_account_id" IS 'account the balancing entry is booked to';
+
+COMMENT ON COLUMN "adjustments"."amount" IS 'added to the balance of the account, negative to take money away';
+
+COMMENT ON COLUMN "adjustments"."reason" IS 'correction, chargeback, goodwill or write_off';
+
+COMMENT ON COLUMN "adjustments"."reference" IS 'ticket or document which explains the adjustment';
+
+COMMENT ON COLUMN "entries"."adjustment_id" IS 'adjustment which created the entry, not a foreign key so reconciliation can report dangling links';
+
+ALTER TABLE "adjustments" ADD CONSTRAINT "adjustment_non_zero" CHECK ("amount" <> 0);
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("suspense_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "adjustments" ADD FOREIGN KEY ("adjusted_by") REFERENCES "users" ("username");
+
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('system:suspense', '', 'Suspense', 'suspense@system.simplebank')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,19 @@
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "aggregate_type" varchar NOT NULL,
+  "aggregate_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "sent_at" timestamptz
+);
+
+CREATE INDEX ON "outbox" ("id") WHERE "sent_at" IS NULL;
+
+COMMENT ON COLUMN "outbox"."aggregate_type" IS 'transfer or account';
+
+COMMENT ON COLUMN "outbox"."event_type" IS 'like transfer.created or account.status_changed';
+
+COMMENT ON COLUMN "outbox"."payload" IS 'state of the aggregate after the change';
+
+COMMENT ON COLUMN "outbox"."sent_at" IS 'null until the relay has published the event';
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS "holds_notify_account_activity" ON "holds";
+DROP TRIGGER IF EXISTS "accounts_notify_account_activity" ON "accounts";
+DROP TRIGGER IF EXISTS "entries_notify_account_activity" ON "entries";
+DROP FUNCTION IF EXISTS notify_account_activity();
+DROP INDEX IF EXISTS "entries_account_id_id_idx";
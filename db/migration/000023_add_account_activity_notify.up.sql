@@ -0,0 +1,19 @@
+CREATE INDEX ON "entries" ("account_id", "id");
+
+CREATE FUNCTION notify_account_activity() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('account_activity', to_jsonb(NEW) ->> TG_ARGV[0]);
+  RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMENT ON FUNCTION notify_account_activity() IS 'notifies listeners with the ID of the changed account, which is read from the column named by the trigger argument';
+
+CREATE TRIGGER "entries_notify_account_activity" AFTER INSERT ON "entries"
+FOR EACH ROW EXECUTE FUNCTION notify_account_activity('account_id');
+
+CREATE TRIGGER "accounts_notify_account_activity" AFTER UPDATE ON "accounts"
+FOR EACH ROW EXECUTE FUNCTION notify_account_activity('id');
+
+CREATE TRIGGER "holds_notify_account_activity" AFTER INSERT OR UPDATE ON "holds"
+FOR EACH ROW EXECUTE FUNCTION notify_account_activity('account_id');
@@ -0,0 +1,40 @@
+CREATE TABLE "audit_log" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL DEFAULT '',
+  "method" varchar NOT NULL,
+  "route" varchar NOT NULL,
+  "resource_id" varchar NOT NULL DEFAULT '',
+  "client_ip" varchar NOT NULL,
+  "user_agent" varchar NOT NULL,
+  "status" int NOT NULL,
+  "request_body" jsonb NOT NULL DEFAULT 'null',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_log" ("created_at", "id");
+
+CREATE INDEX ON "audit_log" ("username", "created_at");
+
+CREATE INDEX ON "audit_log" ("route", "resource_id");
+
+COMMENT ON COLUMN "audit_log"."username" IS 'user of the access token, empty for requests without one';
+
+COMMENT ON COLUMN "audit_log"."route" IS 'route pattern like /accounts/:id/freeze, the path if no route matched';
+
+COMMENT ON COLUMN "audit_log"."resource_id" IS 'path parameters of the route joined by slashes, empty for routes without them';
+
+COMMENT ON COLUMN "audit_log"."status" IS 'HTTP status of the response';
+
+COMMENT ON COLUMN "audit_log"."request_body" IS 'JSON body with secrets redacted, null without a body';
+
+CREATE FUNCTION reject_audit_log_change() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_log_append_only" BEFORE UPDATE OR DELETE ON "audit_log"
+FOR EACH ROW EXECUTE FUNCTION reject_audit_log_change();
+
+CREATE TRIGGER "audit_log_no_truncate" BEFORE TRUNCATE ON "audit_log"
+FOR EACH STATEMENT EXECUTE FUNCTION reject_audit_log_change();
@@ -0,0 +1,10 @@
+ALTER TABLE "idempotency_keys" DROP CONSTRAINT "idempotency_keys_pkey";
+
+-- keys shared by several users can't be kept under a global key
+DELETE FROM "idempotency_keys" a
+USING "idempotency_keys" b
+WHERE a.key = b.key AND a.username > b.username;
+
+ALTER TABLE "idempotency_keys" ADD PRIMARY KEY ("key");
+
+ALTER TABLE "idempotency_keys" DROP COLUMN "username";
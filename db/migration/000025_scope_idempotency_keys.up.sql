@@ -0,0 +1,7 @@
+ALTER TABLE "idempotency_keys" ADD COLUMN "username" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "idempotency_keys" DROP CONSTRAINT "idempotency_keys_pkey";
+
+ALTER TABLE "idempotency_keys" ADD PRIMARY KEY ("username", "key");
+
+COMMENT ON COLUMN "idempotency_keys"."username" IS 'user the key belongs to, keys of different users never collide';
@@ -0,0 +1,5 @@
+ALTER TABLE "scheduled_transfers" ADD COLUMN "anchor_day" int NOT NULL DEFAULT 0;
+
+UPDATE "scheduled_transfers" SET "anchor_day" = extract(day FROM "next_run_at" AT TIME ZONE 'UTC');
+
+COMMENT ON COLUMN "scheduled_transfers"."anchor_day" IS 'day of month in UTC monthly occurrences fall on, shorter months use their last day';
@@ -0,0 +1 @@
+COMMENT ON COLUMN "transfer_limits"."owner" IS 'limit of all accounts of the user in the currency';
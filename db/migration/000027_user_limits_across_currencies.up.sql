@@ -0,0 +1 @@
+COMMENT ON COLUMN "transfer_limits"."owner" IS 'limit of all accounts of the user, transfers in other currencies are converted to currency of the limit';
@@ -0,0 +1,3 @@
+ALTER TABLE "outbox" ADD COLUMN "locked_until" timestamptz;
+
+COMMENT ON COLUMN "outbox"."locked_until" IS 'relays skip the event until this time while another one publishes it';
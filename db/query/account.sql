@@ -13,6 +13,10 @@ WHERE id = $1 LIMIT 1;
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1 FOR NO KEY UPDATE;
 
+-- name: GetAccountByOwner :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2 LIMIT 1;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
 WHERE owner = $1
@@ -20,18 +24,29 @@ ORDER BY id
 LIMIT $2
 OFFSET $3;
 
--- name: UpdateAccount :one
-UPDATE accounts
-  set balance = $2
-WHERE id = $1
-RETURNING *;
+-- name: ListAccountsAfter :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
 
 -- name: AddAccountBalance :one
 UPDATE accounts
-  set balance = balance + sqlc.arg(amount)
+  set balance = balance + sqlc.arg(amount),
+      version = version + 1
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: DeleteAccount :exec
-DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+-- name: IncrementAccountVersion :one
+UPDATE accounts
+  set version = version + 1
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+  set status = sqlc.arg(status),
+      version = version + 1
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status) AND version = sqlc.arg(version)
+RETURNING *;
@@ -0,0 +1,17 @@
+-- name: CreateAdjustment :one
+INSERT INTO adjustments (
+  account_id, suspense_account_id, amount, reason, reference, adjusted_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetAdjustment :one
+SELECT * FROM adjustments
+WHERE id = $1 LIMIT 1;
+
+-- name: ListAccountAdjustments :many
+SELECT * FROM adjustments
+WHERE account_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
@@ -0,0 +1,21 @@
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+  username, method, route, resource_id, client_ip, user_agent, status, request_body
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListAuditLogs :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg(username)::varchar IS NULL OR username = sqlc.narg(username))
+  AND (sqlc.narg(method)::varchar IS NULL OR method = sqlc.narg(method))
+  AND (sqlc.narg(route)::varchar IS NULL OR route = sqlc.narg(route))
+  AND (sqlc.narg(resource_id)::varchar IS NULL OR resource_id = sqlc.narg(resource_id))
+  AND (sqlc.narg(status)::int IS NULL OR status = sqlc.narg(status))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(before_created_at)::timestamptz IS NULL
+    OR (created_at, id) < (sqlc.narg(before_created_at), sqlc.narg(before_id)::bigint))
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
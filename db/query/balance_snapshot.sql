@@ -0,0 +1,36 @@
+-- name: CreateBalanceSnapshot :one
+INSERT INTO balance_snapshots (
+  account_id, snapshot_at, balance
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetLatestBalanceSnapshot :one
+SELECT * FROM balance_snapshots
+WHERE account_id = $1 AND snapshot_at <= $2
+ORDER BY snapshot_at DESC
+LIMIT 1;
+
+-- name: GetOldestTransactionStart :one
+SELECT
+  COALESCE(MIN(xact_start), now())::timestamptz AS oldest_start,
+  COUNT(*) FILTER (
+    WHERE usesysid IS NOT NULL
+      AND NOT pg_has_role('pg_read_all_stats', 'USAGE')
+      AND NOT pg_has_role(usesysid, 'USAGE')
+  ) AS hidden_sessions
+FROM pg_stat_activity
+WHERE datname = current_database() AND pid <> pg_backend_pid();
+
+-- name: ListAccountBalanceSnapshots :many
+SELECT * FROM balance_snapshots
+WHERE account_id = $1
+ORDER BY snapshot_at DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListDueBalanceSnapshots :many
+SELECT a.id FROM accounts a
+LEFT JOIN balance_snapshots s ON s.account_id = a.id AND s.snapshot_at >= sqlc.arg(snapshot_at)
+WHERE a.created_at < sqlc.arg(snapshot_at) AND a.status <> 'closed' AND s.account_id IS NULL
+ORDER BY a.id;
@@ -1,8 +1,8 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id, adjustment_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3, $4
 ) RETURNING *;
 
 -- name: GetEntry :one
@@ -23,4 +23,48 @@ RETURNING *;
 
 -- name: DeleteEntry :exec
 DELETE FROM entries
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: GetAccountEntriesSum :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS entries_sum FROM entries
+WHERE account_id = $1 AND created_at < $2;
+
+-- name: GetAccountEntriesSumBetween :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS entries_sum FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= sqlc.arg(created_from)
+  AND created_at < sqlc.arg(created_to);
+
+-- name: GetAccountEntriesSumSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS entries_sum FROM entries
+WHERE account_id = $1 AND created_at >= $2;
+
+-- name: GetAccountEntriesSumThrough :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS entries_sum FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND (created_at, id) <= (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint);
+
+-- name: ListAccountEntries :many
+SELECT *, (SUM(amount) OVER (ORDER BY created_at, id))::bigint AS running_total FROM entries
+WHERE account_id = $1 AND created_at >= $2 AND created_at < $3
+ORDER BY created_at, id
+LIMIT $4
+OFFSET $5;
+
+-- name: ListAccountEntriesAfter :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+  AND created_at < sqlc.arg(created_to)
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit');
+
+-- name: ListAccountEntriesSince :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id) AND id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: GetAccountLastEntryID :one
+SELECT COALESCE(MAX(id), 0)::bigint AS last_entry_id FROM entries
+WHERE account_id = $1;
@@ -0,0 +1,38 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  account_id, amount, expired_at
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetHeldAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS held_amount FROM holds
+WHERE account_id = $1 AND status = 'active' AND expired_at > now();
+
+-- name: CaptureHold :one
+UPDATE holds
+  set status = 'captured',
+      captured_amount = $2,
+      transfer_id = $3
+WHERE id = $1
+RETURNING *;
+
+-- name: ReleaseHold :one
+UPDATE holds
+  set status = 'released'
+WHERE id = $1 AND status = 'active'
+RETURNING *;
+
+-- name: ExpireHolds :execrows
+UPDATE holds
+  set status = 'expired'
+WHERE status = 'active' AND expired_at <= now();
@@ -0,0 +1,27 @@
+-- name: CreateIdempotencyKey :one
+INSERT INTO idempotency_keys (
+  username, key, request_hash, expired_at
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (username, key) DO UPDATE
+  set request_hash = EXCLUDED.request_hash,
+      response = '{}',
+      created_at = now(),
+      expired_at = EXCLUDED.expired_at
+WHERE idempotency_keys.expired_at <= now()
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys
+WHERE username = $1 AND key = $2 LIMIT 1;
+
+-- name: UpdateIdempotencyKeyResponse :one
+UPDATE idempotency_keys
+  set response = $3
+WHERE username = $1 AND key = $2
+RETURNING *;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expired_at <= $1;
@@ -0,0 +1,59 @@
+-- name: CreateInterestProduct :one
+INSERT INTO interest_products (
+  name, currency, annual_rate, compounding
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetInterestProduct :one
+SELECT * FROM interest_products
+WHERE id = $1 LIMIT 1;
+
+-- name: ListInterestProducts :many
+SELECT * FROM interest_products
+ORDER BY id;
+
+-- name: SetAccountInterest :one
+INSERT INTO account_interest (
+  account_id, product_id, accrued_through
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (account_id) DO UPDATE SET product_id = EXCLUDED.product_id
+RETURNING *;
+
+-- name: GetAccountInterest :one
+SELECT * FROM account_interest
+WHERE account_id = $1 LIMIT 1;
+
+-- name: GetAccountInterestForUpdate :one
+SELECT * FROM account_interest
+WHERE account_id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListDueAccountInterest :many
+SELECT i.account_id FROM account_interest i
+JOIN accounts a ON a.id = i.account_id
+WHERE i.accrued_through < $1 AND a.status <> 'closed'
+ORDER BY i.account_id;
+
+-- name: UpdateAccountInterest :one
+UPDATE account_interest
+  set accrued = $2,
+      accrued_through = $3
+WHERE account_id = $1
+RETURNING *;
+
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+  account_id, accrual_date, basis, amount, transfer_id
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date DESC
+LIMIT $2
+OFFSET $3;
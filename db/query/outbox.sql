@@ -0,0 +1,33 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+  aggregate_type, aggregate_id, event_type, payload
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ClaimPendingOutboxEvents :many
+WITH claimed AS (
+  UPDATE outbox
+    set locked_until = sqlc.arg(locked_until)
+  WHERE id IN (
+    SELECT id FROM outbox
+    WHERE sent_at IS NULL AND (locked_until IS NULL OR locked_until <= now())
+    ORDER BY id
+    LIMIT sqlc.arg('limit')
+    FOR NO KEY UPDATE SKIP LOCKED
+  )
+  RETURNING *
+)
+SELECT * FROM claimed
+ORDER BY id;
+
+-- name: ReleaseOutboxEvents :exec
+UPDATE outbox
+  set locked_until = NULL
+WHERE id = ANY(sqlc.arg(ids)::bigint[]) AND sent_at IS NULL;
+
+-- name: MarkOutboxEventSent :exec
+UPDATE outbox
+  set sent_at = now(),
+  locked_until = NULL
+WHERE id = $1 AND sent_at IS NULL;
@@ -0,0 +1,32 @@
+-- name: CreatePendingTransfer :one
+INSERT INTO pending_transfers (
+  from_account_id, to_account_id, amount, created_by
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetPendingTransfer :one
+SELECT * FROM pending_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPendingTransferForUpdate :one
+SELECT * FROM pending_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPendingTransfers :many
+SELECT * FROM pending_transfers
+WHERE status = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: DecidePendingTransfer :one
+UPDATE pending_transfers
+  set status = $2,
+      decided_by = $3,
+      decided_at = now(),
+      reason = $4,
+      transfer_id = $5
+WHERE id = $1 AND status = 'pending_approval'
+RETURNING *;
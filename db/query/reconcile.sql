@@ -0,0 +1,41 @@
+-- name: ListTransferEntryMismatches :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.to_amount, t.fee,
+  COUNT(e.id) AS entry_count,
+  COALESCE(SUM(e.amount), 0)::bigint AS entry_sum
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COUNT(e.id) <> CASE WHEN t.fee > 0 THEN 3 ELSE 2 END
+  OR COUNT(e.id) FILTER (WHERE e.account_id = t.from_account_id AND e.amount = -(t.amount + t.fee)) <> 1
+  OR COUNT(e.id) FILTER (WHERE e.account_id = t.to_account_id AND e.amount = t.to_amount) <> 1
+  OR (t.fee > 0 AND COUNT(e.id) FILTER (WHERE e.account_id = t.fee_account_id AND e.amount = t.fee) <> 1)
+ORDER BY t.id;
+
+-- name: ListAccountBalanceMismatches :many
+SELECT a.id, a.balance,
+  COALESCE(SUM(e.amount), 0)::bigint AS entry_balance
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListAdjustmentEntryMismatches :many
+SELECT a.id, a.account_id, a.suspense_account_id, a.amount,
+  COUNT(e.id) AS entry_count,
+  COALESCE(SUM(e.amount), 0)::bigint AS entry_sum
+FROM adjustments a
+LEFT JOIN entries e ON e.adjustment_id = a.id
+GROUP BY a.id
+HAVING COUNT(e.id) <> 2
+  OR COUNT(e.id) FILTER (WHERE e.account_id = a.account_id AND e.amount = a.amount) <> 1
+  OR COUNT(e.id) FILTER (WHERE e.account_id = a.suspense_account_id AND e.amount = -a.amount) <> 1
+ORDER BY a.id;
+
+-- name: ListOrphanEntries :many
+SELECT e.* FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+LEFT JOIN adjustments a ON a.id = e.adjustment_id
+WHERE (e.transfer_id IS NOT NULL AND t.id IS NULL)
+  OR (e.adjustment_id IS NOT NULL AND a.id IS NULL)
+ORDER BY e.id;
@@ -0,0 +1,74 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner, from_account_id, to_account_id, amount, recurrence, next_run_at, max_retries, anchor_day
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ClaimDueScheduledTransfer :one
+UPDATE scheduled_transfers
+  set locked_until = $1
+WHERE id = (
+  SELECT id FROM scheduled_transfers
+  WHERE status = 'active' AND next_run_at <= now() AND locked_until <= now()
+  ORDER BY next_run_at
+  LIMIT 1
+  FOR NO KEY UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+  set amount = $2,
+      recurrence = $3,
+      next_run_at = $4,
+      max_retries = $5,
+      anchor_day = $6,
+      retry_count = 0
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateScheduledTransferNextRun :one
+UPDATE scheduled_transfers
+  set next_run_at = $2,
+      retry_count = $3,
+      locked_until = $4,
+      status = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateScheduledTransferStatus :one
+UPDATE scheduled_transfers
+  set status = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+  scheduled_transfer_id, transfer_id, status, error
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
@@ -1,26 +1,82 @@
 -- name: CreateTransfer :one
 INSERT INTO transfers (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, to_amount, exchange_rate, rounding, fee, fee_account_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: CreateReverseTransfer :one
+INSERT INTO transfers (
+  from_account_id, to_account_id, amount, to_amount, exchange_rate, rounding, reversal_of
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 ORDER BY id
 LIMIT $1
 OFFSET $2;
 
+-- name: ListAccountTransfers :many
+SELECT * FROM transfers
+WHERE (
+    (sqlc.arg(direction)::varchar <> 'incoming' AND from_account_id = sqlc.arg(account_id)
+      AND (sqlc.narg(counterparty_id)::bigint IS NULL OR to_account_id = sqlc.narg(counterparty_id)))
+    OR (sqlc.arg(direction)::varchar <> 'outgoing' AND to_account_id = sqlc.arg(account_id)
+      AND (sqlc.narg(counterparty_id)::bigint IS NULL OR from_account_id = sqlc.narg(counterparty_id)))
+  )
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
+-- name: ListOwnerTransfers :many
+SELECT * FROM transfers
+WHERE (
+    (sqlc.arg(direction)::varchar <> 'incoming'
+      AND from_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner))
+      AND (sqlc.narg(counterparty_id)::bigint IS NULL OR to_account_id = sqlc.narg(counterparty_id)))
+    OR (sqlc.arg(direction)::varchar <> 'outgoing'
+      AND to_account_id IN (SELECT id FROM accounts WHERE owner = sqlc.arg(owner))
+      AND (sqlc.narg(counterparty_id)::bigint IS NULL OR from_account_id = sqlc.narg(counterparty_id)))
+  )
+  AND (sqlc.narg(min_amount)::bigint IS NULL OR amount >= sqlc.narg(min_amount))
+  AND (sqlc.narg(max_amount)::bigint IS NULL OR amount <= sqlc.narg(max_amount))
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(after_created_at)::timestamptz IS NULL
+    OR (created_at, id) > (sqlc.narg(after_created_at), sqlc.narg(after_id)::bigint))
+ORDER BY created_at, id
+LIMIT sqlc.arg('limit')
+OFFSET sqlc.arg('offset');
+
 -- name: UpdateTransfer :one
 UPDATE transfers
   set amount = $2
 WHERE id = $1
 RETURNING *;
 
+-- name: AddTransferReversedAmount :one
+UPDATE transfers
+  set reversed_amount = reversed_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
 -- name: DeleteTransfer :exec
 DELETE FROM transfers
 WHERE id = $1;
\ No newline at end of file
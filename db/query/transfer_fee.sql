@@ -0,0 +1,28 @@
+-- name: SetTransferFee :one
+INSERT INTO transfer_fees (
+  currency, flat, percent, min_fee, max_fee
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (currency)
+DO UPDATE SET
+  flat = EXCLUDED.flat,
+  percent = EXCLUDED.percent,
+  min_fee = EXCLUDED.min_fee,
+  max_fee = EXCLUDED.max_fee,
+  updated_at = now()
+RETURNING *;
+
+-- name: GetTransferFee :one
+SELECT * FROM transfer_fees
+WHERE currency = $1 OR currency = ''
+ORDER BY currency DESC
+LIMIT 1;
+
+-- name: ListTransferFees :many
+SELECT * FROM transfer_fees
+ORDER BY currency;
+
+-- name: DeleteTransferFee :execrows
+DELETE FROM transfer_fees
+WHERE id = $1;
@@ -0,0 +1,40 @@
+-- name: SetTransferLimit :one
+INSERT INTO transfer_limits (
+  account_id, owner, currency, max_amount, daily_amount, daily_count
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT ((COALESCE(account_id, 0)), (COALESCE(owner, '')), currency)
+DO UPDATE SET
+  max_amount = EXCLUDED.max_amount,
+  daily_amount = EXCLUDED.daily_amount,
+  daily_count = EXCLUDED.daily_count,
+  updated_at = now()
+RETURNING *;
+
+-- name: GetTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE id = $1 LIMIT 1;
+
+-- name: ListAccountTransferLimits :many
+SELECT * FROM transfer_limits
+WHERE owner = sqlc.arg(owner)
+  OR (currency = sqlc.arg(currency)
+    AND (account_id = sqlc.arg(account_id)
+      OR (account_id IS NULL AND owner IS NULL)))
+ORDER BY id;
+
+-- name: DeleteTransferLimit :execrows
+DELETE FROM transfer_limits
+WHERE id = $1;
+
+-- name: GetAccountOutgoingTotal :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total_amount, COUNT(*) AS transfer_count FROM transfers
+WHERE from_account_id = $1 AND created_at > $2 AND reversal_of IS NULL;
+
+-- name: ListOwnerOutgoingTotals :many
+SELECT a.currency, COALESCE(SUM(t.amount), 0)::bigint AS total_amount, COUNT(*) AS transfer_count FROM transfers t
+JOIN accounts a ON a.id = t.from_account_id
+WHERE a.owner = $1 AND t.created_at > $2 AND t.reversal_of IS NULL
+GROUP BY a.currency
+ORDER BY a.currency;
@@ -0,0 +1,86 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (
+  owner, url, event_types, secret
+) VALUES (
+  $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhooks :many
+SELECT * FROM webhooks
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+  webhook_id, event_type, payload
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: CreateEventWebhookDeliveries :many
+INSERT INTO webhook_deliveries (
+  webhook_id, event_id, event_type, payload
+)
+SELECT id, sqlc.arg(event_id)::bigint, sqlc.arg(event_type)::varchar, sqlc.arg(payload)::jsonb FROM webhooks
+WHERE owner = sqlc.arg(owner) AND (cardinality(event_types) = 0 OR sqlc.arg(event_type)::varchar = ANY(event_types))
+ON CONFLICT (webhook_id, event_id) DO NOTHING
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: GetWebhookDeliveryForUpdate :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ClaimDueWebhookDelivery :one
+UPDATE webhook_deliveries
+  set next_attempt_at = $1
+WHERE id = (
+  SELECT id FROM webhook_deliveries
+  WHERE status = 'pending' AND next_attempt_at <= now()
+  ORDER BY next_attempt_at
+  LIMIT 1
+  FOR NO KEY UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries
+  set status = $2,
+  attempts = $3,
+  next_attempt_at = $4,
+  delivered_at = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: CreateWebhookAttempt :one
+INSERT INTO webhook_attempts (
+  delivery_id, response_status, error
+) VALUES (
+  $1, $2, $3
+) RETURNING *;
+
+-- name: ListWebhookAttempts :many
+SELECT * FROM webhook_attempts
+WHERE delivery_id = $1
+ORDER BY id;
@@ -2,7 +2,6 @@ package db
 
 import (
 	"context"
-	"database/sql"
 	"testing"
 	"time"
 
@@ -11,12 +10,16 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithCurrency(t, util.RandomCurrency())
+}
+
+func createRandomAccountWithCurrency(t *testing.T, currency string) Account {
 	user := createRandomUser(t)
 
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Currency: currency,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -28,6 +31,7 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
 
+	require.Equal(t, int64(1), account.Version)
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
 
@@ -92,24 +96,39 @@ func TestListAccounts(t *testing.T) {
 	}
 }
 
-func TestUpdateAccount(t *testing.T) {
-	accountBefore := createRandomAccount(t)
+func TestListAccountsAfter(t *testing.T) {
+	user := createRandomUser(t)
+
+	n := 3
+	accounts := make([]Account, n)
 
-	updateArg := UpdateAccountParams{
-		ID:      accountBefore.ID,
-		Balance: util.RandomMoney(),
+	for i := 0; i < n; i++ {
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  util.RandomMoney(),
+			Currency: util.RandomCurrency(),
+		})
+		require.NoError(t, err)
+
+		accounts[i] = account
 	}
 
-	accountAfter, err := testQueries.UpdateAccount(context.Background(), updateArg)
+	arg := ListAccountsAfterParams{
+		Owner: user.Username,
+		Limit: 2,
+	}
 
+	page1, err := testQueries.ListAccountsAfter(context.Background(), arg)
 	require.NoError(t, err)
-	require.NotEmpty(t, accountAfter)
+	require.Equal(t, accounts[:2], page1)
 
-	require.Equal(t, accountBefore.ID, accountAfter.ID)
-	require.Equal(t, accountBefore.Owner, accountAfter.Owner)
-	require.Equal(t, updateArg.Balance, accountAfter.Balance)
-	require.Equal(t, accountBefore.Currency, accountAfter.Currency)
-	require.WithinDuration(t, accountBefore.CreatedAt, accountAfter.CreatedAt, time.Second)
+	//next page starts right after the last account of the previous one
+	arg.AfterCreatedAt = page1[1].CreatedAt
+	arg.AfterID = page1[1].ID
+
+	page2, err := testQueries.ListAccountsAfter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, accounts[2:], page2)
 }
 
 func TestAddAccountBalance(t *testing.T) {
@@ -129,18 +148,6 @@ func TestAddAccountBalance(t *testing.T) {
 	require.Equal(t, accountBefore.Owner, accountAfter.Owner)
 	require.Equal(t, accountBefore.Balance+addArg.Amount, accountAfter.Balance)
 	require.Equal(t, accountBefore.Currency, accountAfter.Currency)
+	require.Equal(t, accountBefore.Version+1, accountAfter.Version)
 	require.WithinDuration(t, accountBefore.CreatedAt, accountAfter.CreatedAt, time.Second)
 }
-
-func TestDeleteAccount(t *testing.T) {
-	account := createRandomAccount(t)
-
-	err := testQueries.DeleteAccount(context.Background(), account.ID)
-	require.NoError(t, err)
-
-	deletedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
-
-	require.Error(t, err)
-	require.EqualError(t, err, sql.ErrNoRows.Error())
-	require.Empty(t, deletedAccount)
-}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// CreateAccountTx creates an account and writes account.created event to the outbox
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		var err error
+
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAccountEvent(ctx, q, util.AccountCreatedEvent, account)
+	})
+
+	return account, err
+}
+
+// UpdateAccountStatusTx changes status of the account if nobody has changed the account since the given version was read
+// It returns sql.ErrNoRows if the account has been changed and writes account.status_changed event to the outbox otherwise
+func (store *SQLStore) UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		var err error
+
+		account, err = q.UpdateAccountStatus(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAccountEvent(ctx, q, util.AccountStatusChangedEvent, account)
+	})
+
+	return account, err
+}
+
+// CloseAccountTxParam contain the account to close and optional account which receives its remaining balance
+// Version is the version of the account the caller has read, 0 closes the account whatever its version is
+type CloseAccountTxParam struct {
+	AccountID        int64 `json:"account_id"`
+	SweepToAccountID int64 `json:"sweep_to_account_id"`
+	Version          int64 `json:"version"`
+}
+
+// CloseAccountTxResult contain the closed account and the sweep transfer if there was a balance to move
+type CloseAccountTxResult struct {
+	Account Account           `json:"account"`
+	Sweep   *TransferTxResult `json:"sweep,omitempty"`
+}
+
+// CloseAccountTx closes an account, moving its balance to another account first
+// It returns ErrAccountHasBalance if the account has money and no sweep account is given
+// and ErrVersionMismatch if the account has been changed since the given version was read
+func (store *SQLStore) CloseAccountTx(ctx context.Context, arg CloseAccountTxParam) (CloseAccountTxResult, error) {
+	var result CloseAccountTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		//the function may be run several times so start from empty result
+		result = CloseAccountTxResult{}
+
+		accountIDs := []int64{arg.AccountID}
+		if arg.SweepToAccountID != 0 {
+			accountIDs = append(accountIDs, arg.SweepToAccountID)
+		}
+
+		//lock both accounts in ID order before the sweep locks them again
+		accounts, err := lockAccounts(ctx, q, accountIDs...)
+		if err != nil {
+			return err
+		}
+
+		account := accounts[arg.AccountID]
+
+		if arg.Version != 0 && account.Version != arg.Version {
+			return ErrVersionMismatch
+		}
+
+		if !util.IsAllowedAccountTransition(account.Status, util.ClosedAccount) {
+			return ErrAccountTransition
+		}
+
+		held, err := q.GetHeldAmount(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		if held > 0 {
+			return ErrAccountHasHolds
+		}
+
+		if account.Balance > 0 {
+			if arg.SweepToAccountID == 0 {
+				return ErrAccountHasBalance
+			}
+
+			sweep, err := transferMoney(ctx, q, account.ID, arg.SweepToAccountID, account.Balance, 0, feeCharge{})
+			if err != nil {
+				return err
+			}
+
+			result.Sweep = &sweep
+
+			//the sweep has changed the balance and so the version
+			account = sweep.FromAccount
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			Status:     util.ClosedAccount,
+			ID:         account.ID,
+			FromStatus: account.Status,
+			Version:    account.Version,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return recordAccountEvent(ctx, q, util.AccountStatusChangedEvent, result.Account)
+	})
+
+	return result, err
+}
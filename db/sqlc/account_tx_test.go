@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func setAccountStatus(t *testing.T, account Account, status string) Account {
+	account, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		Status:     status,
+		ID:         account.ID,
+		FromStatus: account.Status,
+		Version:    account.Version,
+	})
+	require.NoError(t, err)
+	require.Equal(t, status, account.Status)
+
+	return account
+}
+
+func TestTransferTxFrozenAccount(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	require.Equal(t, util.ActiveAccount, account1.Status)
+
+	account2 = setAccountStatus(t, account2, util.FrozenAccount)
+
+	//frozen account can be neither debited nor credited
+	_, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	setAccountStatus(t, account2, util.ActiveAccount)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createFundedAccount(t, util.USD, 100)
+	sweepAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParam{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountHasBalance)
+
+	//account read before it was funded is stale
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParam{
+		AccountID:        account.ID,
+		SweepToAccountID: sweepAccount.ID,
+		Version:          account.Version - 1,
+	})
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParam{
+		AccountID:        account.ID,
+		SweepToAccountID: sweepAccount.ID,
+		Version:          account.Version,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, util.ClosedAccount, result.Account.Status)
+	require.Zero(t, result.Account.Balance)
+
+	//both the sweep and the status change count as changes of the account
+	require.Equal(t, account.Version+2, result.Account.Version)
+
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, account.Balance, result.Sweep.Transfer.Amount)
+	require.Equal(t, sweepAccount.Balance+account.Balance, result.Sweep.ToAccount.Balance)
+
+	//closed account never changes again
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParam{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountTransition)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: sweepAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+}
+
+func TestCloseAccountTxWithHolds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createFundedAccount(t, util.USD, 100)
+	sweepAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	createRandomHold(t, account, 10)
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParam{
+		AccountID:        account.ID,
+		SweepToAccountID: sweepAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrAccountHasHolds)
+}
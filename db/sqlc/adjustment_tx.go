@@ -0,0 +1,127 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// AdjustBalanceTxParam contain the account, the amount added to its balance and who adjusts it and why
+// Version is the version of the account the caller has read, 0 adjusts the account whatever its version is
+type AdjustBalanceTxParam struct {
+	AccountID  int64  `json:"account_id"`
+	Amount     int64  `json:"amount"`
+	Reason     string `json:"reason"`
+	Reference  string `json:"reference"`
+	AdjustedBy string `json:"adjusted_by"`
+	Version    int64  `json:"version"`
+}
+
+// AdjustBalanceTxResult contain the adjustment and both entries it is booked with
+type AdjustBalanceTxResult struct {
+	Adjustment      Adjustment `json:"adjustment"`
+	Account         Account    `json:"account"`
+	Entry           Entry      `json:"entry"`
+	SuspenseAccount Account    `json:"suspense_account"`
+	SuspenseEntry   Entry      `json:"suspense_entry"`
+}
+
+// AdjustBalanceTx adds the amount to the balance of the account and books the opposite entry to the suspense account of its currency
+// Both entries point to the adjustment, so every change of the balance is explained by a transfer or an adjustment
+// Closed accounts can't be adjusted and the balance can't be taken below the amount held on the account
+func (store *SQLStore) AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParam) (AdjustBalanceTxResult, error) {
+	var result AdjustBalanceTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		//the function may be run several times so start from empty result
+		result = AdjustBalanceTxResult{}
+
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Owner == util.SuspenseOwner {
+			return ErrSuspenseAdjustment
+		}
+
+		suspenseAccount, err := systemAccount(ctx, q, util.SuspenseOwner, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := lockAccounts(ctx, q, account.ID, suspenseAccount.ID)
+		if err != nil {
+			return err
+		}
+
+		account = accounts[account.ID]
+
+		if arg.Version != 0 && account.Version != arg.Version {
+			return ErrVersionMismatch
+		}
+
+		if account.Status == util.ClosedAccount {
+			return ErrAccountNotActive
+		}
+
+		if arg.Amount < 0 {
+			available, err := availableBalance(ctx, q, account)
+			if err != nil {
+				return err
+			}
+
+			if available < -arg.Amount {
+				return ErrInsufficientFunds
+			}
+		}
+
+		result.Adjustment, err = q.CreateAdjustment(ctx, CreateAdjustmentParams{
+			AccountID:         account.ID,
+			SuspenseAccountID: suspenseAccount.ID,
+			Amount:            arg.Amount,
+			Reason:            arg.Reason,
+			Reference:         arg.Reference,
+			AdjustedBy:        arg.AdjustedBy,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		adjustmentID := sql.NullInt64{Int64: result.Adjustment.ID, Valid: true}
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:    account.ID,
+			Amount:       arg.Amount,
+			AdjustmentID: adjustmentID,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.SuspenseEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:    suspenseAccount.ID,
+			Amount:       -arg.Amount,
+			AdjustmentID: adjustmentID,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.SuspenseAccount, result.Account, err = moveMoney(ctx, q, suspenseAccount.ID, arg.Amount, account.ID, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		return recordEvent(ctx, q, util.AccountAggregate, account.ID, util.AccountAdjustedEvent, AccountAdjustedPayload{
+			Account:    result.Account,
+			Adjustment: result.Adjustment,
+		})
+	})
+
+	return result, err
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdjustBalanceTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	admin := createRandomUser(t)
+	account := createFundedAccount(t, util.USD, 100)
+
+	arg := AdjustBalanceTxParam{
+		AccountID:  account.ID,
+		Amount:     50,
+		Reason:     util.CorrectionAdjustment,
+		Reference:  util.RandomString(10),
+		AdjustedBy: admin.Username,
+		Version:    account.Version,
+	}
+
+	result, err := store.AdjustBalanceTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	adjustment := result.Adjustment
+	require.NotZero(t, adjustment.ID)
+	require.Equal(t, account.ID, adjustment.AccountID)
+	require.Equal(t, result.SuspenseAccount.ID, adjustment.SuspenseAccountID)
+	require.Equal(t, arg.Amount, adjustment.Amount)
+	require.Equal(t, arg.Reason, adjustment.Reason)
+	require.Equal(t, arg.Reference, adjustment.Reference)
+	require.Equal(t, admin.Username, adjustment.AdjustedBy)
+
+	require.Equal(t, account.Balance+50, result.Account.Balance)
+	require.Equal(t, account.Version+1, result.Account.Version)
+
+	require.Equal(t, util.SuspenseOwner, result.SuspenseAccount.Owner)
+	require.Equal(t, account.Currency, result.SuspenseAccount.Currency)
+
+	//both entries point to the adjustment and balance each other
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(50), result.Entry.Amount)
+	require.Equal(t, sql.NullInt64{Int64: adjustment.ID, Valid: true}, result.Entry.AdjustmentID)
+	require.False(t, result.Entry.TransferID.Valid)
+
+	require.Equal(t, result.SuspenseAccount.ID, result.SuspenseEntry.AccountID)
+	require.Equal(t, int64(-50), result.SuspenseEntry.Amount)
+	require.Equal(t, sql.NullInt64{Int64: adjustment.ID, Valid: true}, result.SuspenseEntry.AdjustmentID)
+
+	//the version read before the adjustment is stale now
+	_, err = store.AdjustBalanceTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	//money held on the account can't be taken away
+	createRandomHold(t, account, 100)
+
+	arg.Amount = -60
+	arg.Reason = util.WriteOffAdjustment
+	arg.Version = 0
+
+	_, err = store.AdjustBalanceTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	arg.Amount = -50
+
+	result, err = store.AdjustBalanceTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, result.Account.Balance)
+
+	adjustments, err := testQueries.ListAccountAdjustments(context.Background(), ListAccountAdjustmentsParams{
+		AccountID: account.ID,
+		Limit:     5,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, adjustments, 2)
+	require.Equal(t, result.Adjustment, adjustments[0])
+	require.Equal(t, adjustment, adjustments[1])
+
+	report, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+
+	for _, mismatch := range report.AdjustmentMismatches {
+		require.NotEqual(t, adjustment.ID, mismatch.ID)
+		require.NotEqual(t, result.Adjustment.ID, mismatch.ID)
+	}
+}
+
+func TestAdjustBalanceTxNotAllowed(t *testing.T) {
+	store := NewStore(testDB)
+
+	admin := createRandomUser(t)
+	account := createRandomAccountWithCurrency(t, util.EUR)
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParam{AccountID: account.ID})
+	require.NoError(t, err)
+
+	arg := AdjustBalanceTxParam{
+		AccountID:  account.ID,
+		Amount:     10,
+		Reason:     util.GoodwillAdjustment,
+		Reference:  util.RandomString(10),
+		AdjustedBy: admin.Username,
+	}
+
+	_, err = store.AdjustBalanceTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	//the suspense account is the other side of every adjustment, so it can't be adjusted itself
+	active := createRandomAccountWithCurrency(t, util.EUR)
+	arg.AccountID = active.ID
+
+	result, err := store.AdjustBalanceTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	arg.AccountID = result.SuspenseAccount.ID
+
+	_, err = store.AdjustBalanceTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrSuspenseAdjustment)
+}
+
+func TestReconcileTxOrphanAdjustmentEntry(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	//entry linked to an adjustment which doesn't exist
+	orphan, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:    account.ID,
+		Amount:       1,
+		AdjustmentID: sql.NullInt64{Int64: math.MaxInt64, Valid: true},
+	})
+	require.NoError(t, err)
+
+	report, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+	require.True(t, report.HasDiscrepancies())
+	require.Contains(t, report.OrphanEntries, orphan)
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAuditLog(t *testing.T, username string) AuditLog {
+	arg := CreateAuditLogParams{
+		Username:    username,
+		Method:      http.MethodPost,
+		Route:       "/accounts/:id/holds",
+		ResourceID:  util.RandomString(4),
+		ClientIp:    "192.0.2.1",
+		UserAgent:   "curl/7.88.1",
+		Status:      http.StatusOK,
+		RequestBody: json.RawMessage(`{"amount": 10}`),
+	}
+
+	auditLog, err := testQueries.CreateAuditLog(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, auditLog.ID)
+	require.Equal(t, arg.Username, auditLog.Username)
+	require.Equal(t, arg.Method, auditLog.Method)
+	require.Equal(t, arg.Route, auditLog.Route)
+	require.Equal(t, arg.ResourceID, auditLog.ResourceID)
+	require.Equal(t, arg.ClientIp, auditLog.ClientIp)
+	require.Equal(t, arg.UserAgent, auditLog.UserAgent)
+	require.Equal(t, arg.Status, auditLog.Status)
+	require.JSONEq(t, string(arg.RequestBody), string(auditLog.RequestBody))
+	require.WithinDuration(t, time.Now(), auditLog.CreatedAt, time.Second)
+
+	return auditLog
+}
+
+func TestCreateAuditLog(t *testing.T) {
+	createRandomAuditLog(t, util.RandomOwner())
+}
+
+func TestListAuditLogs(t *testing.T) {
+	username := util.RandomOwner()
+
+	var last AuditLog
+	for i := 0; i < 3; i++ {
+		last = createRandomAuditLog(t, username)
+	}
+
+	auditLogs, err := testQueries.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		Username: sql.NullString{String: username, Valid: true},
+		Limit:    5,
+	})
+	require.NoError(t, err)
+	require.Len(t, auditLogs, 3)
+	require.Equal(t, last.ID, auditLogs[0].ID)
+
+	auditLogs, err = testQueries.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		Username:        sql.NullString{String: username, Valid: true},
+		BeforeCreatedAt: sql.NullTime{Time: last.CreatedAt, Valid: true},
+		BeforeID:        sql.NullInt64{Int64: last.ID, Valid: true},
+		Limit:           5,
+	})
+	require.NoError(t, err)
+	require.Len(t, auditLogs, 2)
+	for _, auditLog := range auditLogs {
+		require.Less(t, auditLog.ID, last.ID)
+	}
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	auditLog := createRandomAuditLog(t, util.RandomOwner())
+
+	_, err := testDB.Exec("UPDATE audit_log SET status = 500 WHERE id = $1", auditLog.ID)
+	require.Error(t, err)
+
+	_, err = testDB.Exec("DELETE FROM audit_log WHERE id = $1", auditLog.ID)
+	require.Error(t, err)
+
+	_, err = testDB.Exec("TRUNCATE audit_log")
+	require.Error(t, err)
+
+	auditLogs, err := testQueries.ListAuditLogs(context.Background(), ListAuditLogsParams{
+		ResourceID: sql.NullString{String: auditLog.ResourceID, Valid: true},
+		Limit:      5,
+	})
+	require.NoError(t, err)
+	require.Contains(t, auditLogs, auditLog)
+}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// SnapshotBalancesTxParam contain the account and the last midnight to take its balance snapshot at
+type SnapshotBalancesTxParam struct {
+	AccountID int64     `json:"account_id"`
+	Through   time.Time `json:"through"`
+}
+
+// SnapshotBalancesTxResult contain snapshots created for every midnight after the latest existing one
+type SnapshotBalancesTxResult struct {
+	Snapshots []BalanceSnapshot `json:"snapshots"`
+}
+
+// SnapshotBalancesTx takes daily balance snapshots of the account at every midnight UTC after its latest snapshot through the given one
+// Each snapshot is the previous one plus entries created in between and it is verified against the account balance less entries created after its midnight,
+// ErrSnapshotMismatch rolls everything back, so a wrong snapshot never becomes the base of the following ones
+// Midnights after the start of the oldest transaction still in flight are left for a later run, since it may yet commit entries created before them
+// Transactions of other roles are only visible to members of pg_read_all_stats, so it fails with ErrSessionsNotVisible
+// rather than snapshot midnights which some unseen transaction may still write entries into
+// Midnights which already have a snapshot are skipped, so running it again for the same day changes nothing
+func (store *SQLStore) SnapshotBalancesTx(ctx context.Context, arg SnapshotBalancesTxParam) (SnapshotBalancesTxResult, error) {
+	var result SnapshotBalancesTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		//the function may be run several times so start from empty result
+		result = SnapshotBalancesTxResult{Snapshots: []BalanceSnapshot{}}
+
+		//read first, so every transaction which isn't visible to this one is still counted as in flight
+		oldest, err := q.GetOldestTransactionStart(ctx)
+		if err != nil {
+			return err
+		}
+
+		if oldest.HiddenSessions > 0 {
+			return ErrSessionsNotVisible
+		}
+		cutoff := oldest.OldestStart
+
+		//the lock keeps the balance in step with entries read below
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		through := truncateToDay(arg.Through)
+		if cutoff.Before(through) {
+			through = truncateToDay(cutoff)
+		}
+
+		//the first snapshot is taken at the midnight after the account is created
+		since := time.Time{}
+		balance := util.NewMoney(0, account.Currency)
+		next := truncateToDay(account.CreatedAt).AddDate(0, 0, 1)
+
+		latest, err := q.GetLatestBalanceSnapshot(ctx, GetLatestBalanceSnapshotParams{
+			AccountID:  account.ID,
+			SnapshotAt: through,
+		})
+
+		switch {
+		case err == nil:
+			since = latest.SnapshotAt
+			balance.Amount = latest.Balance
+			next = latest.SnapshotAt.AddDate(0, 0, 1)
+		case err != sql.ErrNoRows:
+			return err
+		}
+
+		for at := next; !at.After(through); at = at.AddDate(0, 0, 1) {
+			delta, err := q.GetAccountEntriesSumBetween(ctx, GetAccountEntriesSumBetweenParams{
+				AccountID:   account.ID,
+				CreatedFrom: since,
+				CreatedTo:   at,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			balance, err = balance.Add(util.NewMoney(delta, account.Currency))
+			if err != nil {
+				return err
+			}
+
+			if err := verifySnapshotBalance(ctx, q, account, at, balance.Amount); err != nil {
+				return err
+			}
+
+			snapshot, err := q.CreateBalanceSnapshot(ctx, CreateBalanceSnapshotParams{
+				AccountID:  account.ID,
+				SnapshotAt: at,
+				Balance:    balance.Amount,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			result.Snapshots = append(result.Snapshots, snapshot)
+			since = at
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// verifySnapshotBalance checks the balance at the midnight against the account balance less entries created after it
+// The account balance is kept apart from entries, so an entry booked into a day which is already snapshotted shows up as ErrSnapshotMismatch
+func verifySnapshotBalance(ctx context.Context, q *Queries, account Account, at time.Time, balance int64) error {
+	later, err := q.GetAccountEntriesSumSince(ctx, GetAccountEntriesSumSinceParams{
+		AccountID: account.ID,
+		CreatedAt: at,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	expected, err := util.NewMoney(account.Balance, account.Currency).Add(util.NewMoney(-later, account.Currency))
+	if err != nil {
+		return err
+	}
+
+	if expected.Amount != balance {
+		return fmt.Errorf("%w: account %d at %s: snapshot %d, account balance %d",
+			ErrSnapshotMismatch, account.ID, at.Format(time.RFC3339), balance, expected.Amount)
+	}
+
+	return nil
+}
+
+// BalanceAsOfTxParam contain the account and the moment to get its balance at
+type BalanceAsOfTxParam struct {
+	AccountID int64     `json:"account_id"`
+	At        time.Time `json:"at"`
+}
+
+// BalanceAsOfTxResult contain the balance at the moment and the snapshot it is computed from, if there is one
+type BalanceAsOfTxResult struct {
+	Account  Account          `json:"account"`
+	At       time.Time        `json:"at"`
+	Balance  int64            `json:"balance"`
+	Snapshot *BalanceSnapshot `json:"snapshot"`
+}
+
+// BalanceAsOfTx returns the balance of the account at the moment, which is the sum of its entries created before it
+// The nearest snapshot at or before the moment is taken and only entries created after the snapshot are added to it,
+// the snapshot is verified first and ErrSnapshotMismatch is returned instead of a balance computed from a wrong one
+// All reads see one consistent snapshot of the database
+func (store *SQLStore) BalanceAsOfTx(ctx context.Context, arg BalanceAsOfTxParam) (BalanceAsOfTxResult, error) {
+	var result BalanceAsOfTxResult
+
+	err := store.execTx(ctx, snapshotTxOptions, func(q *Queries) error {
+		var err error
+
+		result = BalanceAsOfTxResult{At: arg.At}
+
+		result.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		since := time.Time{}
+		balance := util.NewMoney(0, result.Account.Currency)
+
+		snapshot, err := q.GetLatestBalanceSnapshot(ctx, GetLatestBalanceSnapshotParams{
+			AccountID:  arg.AccountID,
+			SnapshotAt: arg.At,
+		})
+
+		switch {
+		case err == nil:
+			if err := verifySnapshotBalance(ctx, q, result.Account, snapshot.SnapshotAt, snapshot.Balance); err != nil {
+				return err
+			}
+
+			result.Snapshot = &snapshot
+			since = snapshot.SnapshotAt
+			balance.Amount = snapshot.Balance
+		case err != sql.ErrNoRows:
+			return err
+		}
+
+		delta, err := q.GetAccountEntriesSumBetween(ctx, GetAccountEntriesSumBetweenParams{
+			AccountID:   arg.AccountID,
+			CreatedFrom: since,
+			CreatedTo:   arg.At,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		balance, err = balance.Add(util.NewMoney(delta, result.Account.Currency))
+		if err != nil {
+			return err
+		}
+
+		result.Balance = balance.Amount
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// createLedgerAccount creates an account which balance is the sum of its entries, as it is for accounts opened through the API
+func createLedgerAccount(t *testing.T) Account {
+	account := createRandomAccountWithCurrency(t, util.USD)
+
+	_, err := testDB.ExecContext(context.Background(), "UPDATE accounts SET balance = 0 WHERE id = $1", account.ID)
+	require.NoError(t, err)
+
+	account.Balance = 0
+	return account
+}
+
+// createAccountEntry books the entry and adds it to the account balance
+func createAccountEntry(t *testing.T, account Account, amount int64) Entry {
+	entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: amount,
+	})
+	require.NoError(t, err)
+
+	return entry
+}
+
+func TestBalanceAsOfTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createLedgerAccount(t)
+
+	entry1 := createAccountEntry(t, account, 100)
+	entry2 := createAccountEntry(t, account, 50)
+	entry3 := createAccountEntry(t, account, -30)
+
+	//entries created at the moment are not counted yet
+	result, err := store.BalanceAsOfTx(context.Background(), BalanceAsOfTxParam{
+		AccountID: account.ID,
+		At:        entry2.CreatedAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, entry1.Amount, result.Balance)
+	require.Nil(t, result.Snapshot)
+
+	snapshot, err := testQueries.CreateBalanceSnapshot(context.Background(), CreateBalanceSnapshotParams{
+		AccountID:  account.ID,
+		SnapshotAt: entry2.CreatedAt,
+		Balance:    entry1.Amount,
+	})
+	require.NoError(t, err)
+
+	result, err = store.BalanceAsOfTx(context.Background(), BalanceAsOfTxParam{
+		AccountID: account.ID,
+		At:        entry3.CreatedAt.Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(120), result.Balance)
+	require.NotNil(t, result.Snapshot)
+	require.WithinDuration(t, snapshot.SnapshotAt, result.Snapshot.SnapshotAt, 0)
+
+	//a snapshot which doesn't match the account balance is never used
+	_, err = testQueries.CreateBalanceSnapshot(context.Background(), CreateBalanceSnapshotParams{
+		AccountID:  account.ID,
+		SnapshotAt: entry3.CreatedAt,
+		Balance:    0,
+	})
+	require.NoError(t, err)
+
+	_, err = store.BalanceAsOfTx(context.Background(), BalanceAsOfTxParam{
+		AccountID: account.ID,
+		At:        entry3.CreatedAt.Add(time.Second),
+	})
+	require.ErrorIs(t, err, ErrSnapshotMismatch)
+}
+
+func TestSnapshotBalancesTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createLedgerAccount(t)
+
+	//move the account and its entries back in time, so it has finished days to snapshot
+	_, err := testDB.ExecContext(context.Background(),
+		"UPDATE accounts SET created_at = now() - interval '3 days' WHERE id = $1", account.ID)
+	require.NoError(t, err)
+
+	entry1 := createAccountEntry(t, account, 100)
+	entry2 := createAccountEntry(t, account, 40)
+
+	_, err = testDB.ExecContext(context.Background(),
+		"UPDATE entries SET created_at = now() - interval '2 days' WHERE id = $1", entry1.ID)
+	require.NoError(t, err)
+
+	today := truncateToDay(time.Now())
+
+	result, err := store.SnapshotBalancesTx(context.Background(), SnapshotBalancesTxParam{
+		AccountID: account.ID,
+		Through:   today,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Snapshots, 3)
+	require.Equal(t, today, result.Snapshots[2].SnapshotAt.UTC())
+	require.Equal(t, int64(100), result.Snapshots[2].Balance)
+
+	//already snapshotted days are skipped and midnights which are yet to come are left for later
+	result, err = store.SnapshotBalancesTx(context.Background(), SnapshotBalancesTxParam{
+		AccountID: account.ID,
+		Through:   today.AddDate(0, 0, 1),
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Snapshots)
+
+	balanceTx, err := store.BalanceAsOfTx(context.Background(), BalanceAsOfTxParam{
+		AccountID: account.ID,
+		At:        entry2.CreatedAt.Add(time.Second),
+	})
+	require.NoError(t, err)
+	require.NotNil(t, balanceTx.Snapshot)
+	require.Equal(t, int64(140), balanceTx.Balance)
+
+	//an entry booked into a day which is already snapshotted is found by the verification
+	_, err = testDB.ExecContext(context.Background(),
+		"UPDATE entries SET created_at = now() - interval '2 days' WHERE id = $1", entry2.ID)
+	require.NoError(t, err)
+
+	_, err = testDB.ExecContext(context.Background(),
+		"DELETE FROM balance_snapshots WHERE account_id = $1 AND snapshot_at = $2", account.ID, today)
+	require.NoError(t, err)
+
+	result, err = store.SnapshotBalancesTx(context.Background(), SnapshotBalancesTxParam{
+		AccountID: account.ID,
+		Through:   today,
+	})
+	require.ErrorIs(t, err, ErrSnapshotMismatch)
+
+	_, err = store.BalanceAsOfTx(context.Background(), BalanceAsOfTxParam{
+		AccountID: account.ID,
+		At:        time.Now(),
+	})
+	require.ErrorIs(t, err, ErrSnapshotMismatch)
+}
+
+func TestGetOldestTransactionStart(t *testing.T) {
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+
+	//the transaction starts with its first statement
+	_, err = tx.ExecContext(context.Background(), "SELECT 1")
+	require.NoError(t, err)
+	started := time.Now()
+
+	//the test user owns the database, so no session of it is hidden
+	oldest, err := testQueries.GetOldestTransactionStart(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, oldest.HiddenSessions)
+	require.False(t, oldest.OldestStart.After(started))
+}
@@ -0,0 +1,219 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// BatchTransferLeg contain information for one transfer of a batch
+type BatchTransferLeg struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+}
+
+// BatchTransferTxParam contain all transfers of a batch
+type BatchTransferTxParam struct {
+	Legs []BatchTransferLeg `json:"legs"`
+}
+
+// BatchTransferTxResult contain result of every leg in the order of the legs
+// Accounts of every leg have balances after the whole batch
+type BatchTransferTxResult struct {
+	Transfers []TransferTxResult `json:"transfers"`
+}
+
+// BatchTransferTx performs all transfers of a batch within one transaction, so either all of them are done or none
+// First of all it locks every account of the batch in the order of IDs, so batches sharing accounts don't deadlock each other
+// Every leg is charged its own fee and checked against the available balance left after the previous legs, errors are wrapped with the index of the leg
+// Then transfers and entries are created leg by leg and finally each balance is changed once
+// Limits of every sender are checked after all legs are created, so the whole batch counts towards them
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParam) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		//the function may be run several times so start from empty result
+		result = BatchTransferTxResult{Transfers: make([]TransferTxResult, len(arg.Legs))}
+
+		//fees are known before locking, so fee accounts are locked in the same order as the others
+		fees := make([]feeCharge, len(arg.Legs))
+
+		accountIDs := make([]int64, 0, 3*len(arg.Legs))
+		for i, leg := range arg.Legs {
+			fee, err := transferFee(ctx, q, leg.FromAccountID, leg.Amount)
+			if err != nil {
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+
+			fees[i] = fee
+			accountIDs = append(accountIDs, leg.FromAccountID, leg.ToAccountID)
+
+			if fee.amount > 0 {
+				accountIDs = append(accountIDs, fee.accountID)
+			}
+		}
+
+		accounts, err := lockAccounts(ctx, q, accountIDs...)
+		if err != nil {
+			return err
+		}
+
+		//amounts collects balance changes of accounts made by previous legs
+		amounts := make(map[int64]int64, len(accounts))
+
+		//available balances of senders before the batch
+		available := make(map[int64]int64, len(accounts))
+
+		//largest amount sent by each sender within the batch
+		largest := make(map[int64]int64, len(accounts))
+
+		for i, leg := range arg.Legs {
+			fromAccount := accounts[leg.FromAccountID]
+			toAccount := accounts[leg.ToAccountID]
+			fee := fees[i]
+
+			if fromAccount.Status != util.ActiveAccount || toAccount.Status != util.ActiveAccount {
+				return fmt.Errorf("leg %d: %w", i, ErrAccountNotActive)
+			}
+
+			if _, ok := available[leg.FromAccountID]; !ok {
+				available[leg.FromAccountID], err = availableBalance(ctx, q, fromAccount)
+				if err != nil {
+					return err
+				}
+			}
+
+			debit, err := util.NewMoney(leg.Amount, fromAccount.Currency).Add(util.NewMoney(fee.amount, fromAccount.Currency))
+			if err != nil {
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+
+			if available[leg.FromAccountID]+amounts[leg.FromAccountID] < debit.Amount {
+				return fmt.Errorf("leg %d: %w", i, ErrInsufficientFunds)
+			}
+
+			conversion, err := convertAmount(ctx, q, fromAccount.Currency, toAccount.Currency, leg.Amount)
+			if err != nil {
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+
+			legResult := &result.Transfers[i]
+
+			legResult.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+				FromAccountID: leg.FromAccountID,
+				ToAccountID:   leg.ToAccountID,
+				Amount:        leg.Amount,
+				ToAmount:      conversion.toAmount,
+				ExchangeRate:  conversion.rate,
+				Rounding:      conversion.rounding,
+				Fee:           fee.amount,
+				FeeAccountID:  sql.NullInt64{Int64: fee.accountID, Valid: fee.amount > 0},
+			})
+
+			if err != nil {
+				return err
+			}
+
+			err = recordTransferCreated(ctx, q, legResult.Transfer)
+			if err != nil {
+				return err
+			}
+
+			legResult.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+				AccountID:  leg.FromAccountID,
+				Amount:     -debit.Amount,
+				TransferID: sql.NullInt64{Int64: legResult.Transfer.ID, Valid: true},
+			})
+
+			if err != nil {
+				return err
+			}
+
+			legResult.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+				AccountID:  leg.ToAccountID,
+				Amount:     conversion.toAmount,
+				TransferID: sql.NullInt64{Int64: legResult.Transfer.ID, Valid: true},
+			})
+
+			if err != nil {
+				return err
+			}
+
+			if fee.amount > 0 {
+				feeEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+					AccountID:  fee.accountID,
+					Amount:     fee.amount,
+					TransferID: sql.NullInt64{Int64: legResult.Transfer.ID, Valid: true},
+				})
+
+				if err != nil {
+					return err
+				}
+
+				legResult.FeeEntry = &feeEntry
+
+				if err = addAmount(amounts, accounts[fee.accountID], fee.amount); err != nil {
+					return fmt.Errorf("leg %d: %w", i, err)
+				}
+			}
+
+			if err = addAmount(amounts, fromAccount, -debit.Amount); err != nil {
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+
+			if err = addAmount(amounts, toAccount, conversion.toAmount); err != nil {
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+
+			if leg.Amount > largest[leg.FromAccountID] {
+				largest[leg.FromAccountID] = leg.Amount
+			}
+		}
+
+		//check limits of senders in the order of their IDs
+
+		senderIDs := make([]int64, 0, len(largest))
+		for id := range largest {
+			senderIDs = append(senderIDs, id)
+		}
+
+		for _, id := range sortedAccountIDs(senderIDs...) {
+			err = checkTransferLimits(ctx, q, accounts[id], largest[id])
+			if err != nil {
+				return fmt.Errorf("account %d: %w", id, err)
+			}
+		}
+
+		//update accounts balance
+
+		updatedAccounts, err := addBalances(ctx, q, amounts)
+		if err != nil {
+			return err
+		}
+
+		for i, leg := range arg.Legs {
+			result.Transfers[i].FromAccount = updatedAccounts[leg.FromAccountID]
+			result.Transfers[i].ToAccount = updatedAccounts[leg.ToAccountID]
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// addAmount adds the amount to the balance change of the account collected from previous legs
+// It returns util.ErrAmountOverflow instead of wrapping around
+func addAmount(amounts map[int64]int64, account Account, amount int64) error {
+	total, err := util.NewMoney(amounts[account.ID], account.Currency).Add(util.NewMoney(amount, account.Currency))
+	if err != nil {
+		return err
+	}
+
+	amounts[account.ID] = total.Amount
+
+	return nil
+}
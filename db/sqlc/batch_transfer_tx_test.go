@@ -0,0 +1,126 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortedAccountIDs(t *testing.T) {
+	require.Equal(t, []int64{1, 2, 5, 9}, sortedAccountIDs(9, 2, 5, 2, 1, 9))
+	require.Empty(t, sortedAccountIDs())
+}
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(10)
+	n := 3
+
+	fromAccount := createFundedAccount(t, util.USD, int64(n)*amount)
+
+	arg := BatchTransferTxParam{}
+	toAccounts := make([]Account, n)
+
+	for i := 0; i < n; i++ {
+		toAccounts[i] = createRandomAccountWithCurrency(t, util.USD)
+		arg.Legs = append(arg.Legs, BatchTransferLeg{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccounts[i].ID,
+			Amount:        amount,
+		})
+	}
+
+	result, err := store.BatchTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, result.Transfers, n)
+
+	for i, legResult := range result.Transfers {
+		require.Equal(t, fromAccount.ID, legResult.Transfer.FromAccountID)
+		require.Equal(t, toAccounts[i].ID, legResult.Transfer.ToAccountID)
+		require.Equal(t, amount, legResult.Transfer.Amount)
+
+		require.Equal(t, -amount, legResult.FromEntry.Amount)
+		require.Equal(t, amount, legResult.ToEntry.Amount)
+
+		//accounts have balances after the whole batch
+		require.Equal(t, fromAccount.Balance-int64(n)*amount, legResult.FromAccount.Balance)
+		require.Equal(t, toAccounts[i].Balance+amount, legResult.ToAccount.Balance)
+	}
+}
+
+func TestBatchTransferTxAllOrNothing(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(10)
+
+	fromAccount := createFundedAccount(t, util.USD, amount)
+	toAccount1 := createRandomAccountWithCurrency(t, util.USD)
+	toAccount2 := createRandomAccountWithCurrency(t, util.USD)
+
+	//the second leg can't be paid after the first one
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParam{
+		Legs: []BatchTransferLeg{
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount1.ID, Amount: amount},
+			{FromAccountID: fromAccount.ID, ToAccountID: toAccount2.ID, Amount: amount},
+		},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.EqualError(t, err, "leg 1: "+ErrInsufficientFunds.Error())
+
+	for _, account := range []Account{fromAccount, toAccount1, toAccount2} {
+		updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, updatedAccount.Balance)
+	}
+}
+
+func TestBatchTransferTxDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+
+	//run n concurrent batches moving money around the same accounts in opposite directions
+	n := 10
+	amount := int64(10)
+
+	accounts := []Account{
+		createFundedAccount(t, util.USD, int64(n)*amount),
+		createFundedAccount(t, util.USD, int64(n)*amount),
+		createFundedAccount(t, util.USD, int64(n)*amount),
+	}
+
+	errChan := make(chan error)
+
+	for i := 0; i < n; i++ {
+		legs := []BatchTransferLeg{
+			{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: amount},
+			{FromAccountID: accounts[1].ID, ToAccountID: accounts[2].ID, Amount: amount},
+			{FromAccountID: accounts[2].ID, ToAccountID: accounts[0].ID, Amount: amount},
+		}
+
+		if i%2 == 1 {
+			for j := range legs {
+				legs[j].FromAccountID, legs[j].ToAccountID = legs[j].ToAccountID, legs[j].FromAccountID
+			}
+		}
+
+		go func() {
+			_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParam{Legs: legs})
+
+			errChan <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errChan
+		require.NoError(t, err)
+	}
+
+	//every account gets back what it sent
+	for _, account := range accounts {
+		updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, updatedAccount.Balance)
+	}
+}
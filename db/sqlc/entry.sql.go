@@ -7,29 +7,40 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id, adjustment_id
 ) VALUES (
-  $1, $2
-) RETURNING id, account_id, amount, created_at
+  $1, $2, $3, $4
+) RETURNING id, account_id, amount, created_at, transfer_id, adjustment_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"accountID"`
-	Amount    int64 `json:"amount"`
+	AccountID    int64         `json:"accountID"`
+	Amount       int64         `json:"amount"`
+	TransferID   sql.NullInt64 `json:"transferID"`
+	AdjustmentID sql.NullInt64 `json:"adjustmentID"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.AdjustmentID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.AdjustmentID,
 	)
 	return i, err
 }
@@ -44,8 +55,93 @@ func (q *Queries) DeleteEntry(ctx context.Context, id int64) error {
 	return err
 }
 
+const getAccountEntriesSum = `-- name: GetAccountEntriesSum :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS entries_sum FROM entries
+WHERE account_id = $1 AND created_at < $2
+`
+
+type GetAccountEntriesSumParams struct {
+	AccountID int64     `json:"accountID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (q *Queries) GetAccountEntriesSum(ctx context.Context, arg GetAccountEntriesSumParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAccountEntriesSum, arg.AccountID, arg.CreatedAt)
+	var entries_sum int64
+	err := row.Scan(&entries_sum)
+	return entries_sum, err
+}
+
+const getAccountEntriesSumBetween = `-- name: GetAccountEntriesSumBetween :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS entries_sum FROM entries
+WHERE account_id = $1
+  AND created_at >= $2
+  AND created_at < $3
+`
+
+type GetAccountEntriesSumBetweenParams struct {
+	AccountID   int64     `json:"accountID"`
+	CreatedFrom time.Time `json:"createdFrom"`
+	CreatedTo   time.Time `json:"createdTo"`
+}
+
+func (q *Queries) GetAccountEntriesSumBetween(ctx context.Context, arg GetAccountEntriesSumBetweenParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAccountEntriesSumBetween, arg.AccountID, arg.CreatedFrom, arg.CreatedTo)
+	var entries_sum int64
+	err := row.Scan(&entries_sum)
+	return entries_sum, err
+}
+
+const getAccountEntriesSumSince = `-- name: GetAccountEntriesSumSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS entries_sum FROM entries
+WHERE account_id = $1 AND created_at >= $2
+`
+
+type GetAccountEntriesSumSinceParams struct {
+	AccountID int64     `json:"accountID"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (q *Queries) GetAccountEntriesSumSince(ctx context.Context, arg GetAccountEntriesSumSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAccountEntriesSumSince, arg.AccountID, arg.CreatedAt)
+	var entries_sum int64
+	err := row.Scan(&entries_sum)
+	return entries_sum, err
+}
+
+const getAccountEntriesSumThrough = `-- name: GetAccountEntriesSumThrough :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS entries_sum FROM entries
+WHERE account_id = $1
+  AND (created_at, id) <= ($2::timestamptz, $3::bigint)
+`
+
+type GetAccountEntriesSumThroughParams struct {
+	AccountID      int64     `json:"accountID"`
+	AfterCreatedAt time.Time `json:"afterCreatedAt"`
+	AfterID        int64     `json:"afterID"`
+}
+
+func (q *Queries) GetAccountEntriesSumThrough(ctx context.Context, arg GetAccountEntriesSumThroughParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAccountEntriesSumThrough, arg.AccountID, arg.AfterCreatedAt, arg.AfterID)
+	var entries_sum int64
+	err := row.Scan(&entries_sum)
+	return entries_sum, err
+}
+
+const getAccountLastEntryID = `-- name: GetAccountLastEntryID :one
+SELECT COALESCE(MAX(id), 0)::bigint AS last_entry_id FROM entries
+WHERE account_id = $1
+`
+
+func (q *Queries) GetAccountLastEntryID(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getAccountLastEntryID, accountID)
+	var last_entry_id int64
+	err := row.Scan(&last_entry_id)
+	return last_entry_id, err
+}
+
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -57,12 +153,173 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.AdjustmentID,
 	)
 	return i, err
 }
 
+const listAccountEntries = `-- name: ListAccountEntries :many
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id, (SUM(amount) OVER (ORDER BY created_at, id))::bigint AS running_total FROM entries
+WHERE account_id = $1 AND created_at >= $2 AND created_at < $3
+ORDER BY created_at, id
+LIMIT $4
+OFFSET $5
+`
+
+type ListAccountEntriesParams struct {
+	AccountID   int64     `json:"accountID"`
+	CreatedAt   time.Time `json:"createdAt"`
+	CreatedAt_2 time.Time `json:"createdAt2"`
+	Limit       int32     `json:"limit"`
+	Offset      int32     `json:"offset"`
+}
+
+type ListAccountEntriesRow struct {
+	ID           int64         `json:"id"`
+	AccountID    int64         `json:"accountID"`
+	Amount       int64         `json:"amount"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	TransferID   sql.NullInt64 `json:"transferID"`
+	AdjustmentID sql.NullInt64 `json:"adjustmentID"`
+	RunningTotal int64         `json:"runningTotal"`
+}
+
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntries,
+		arg.AccountID,
+		arg.CreatedAt,
+		arg.CreatedAt_2,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEntriesRow{}
+	for rows.Next() {
+		var i ListAccountEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.AdjustmentID,
+			&i.RunningTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountEntriesAfter = `-- name: ListAccountEntriesAfter :many
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id FROM entries
+WHERE account_id = $1
+  AND (created_at, id) > ($2::timestamptz, $3::bigint)
+  AND created_at < $4
+ORDER BY created_at, id
+LIMIT $5
+`
+
+type ListAccountEntriesAfterParams struct {
+	AccountID      int64     `json:"accountID"`
+	AfterCreatedAt time.Time `json:"afterCreatedAt"`
+	AfterID        int64     `json:"afterID"`
+	CreatedTo      time.Time `json:"createdTo"`
+	Limit          int32     `json:"limit"`
+}
+
+func (q *Queries) ListAccountEntriesAfter(ctx context.Context, arg ListAccountEntriesAfterParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntriesAfter,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.CreatedTo,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.AdjustmentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountEntriesSince = `-- name: ListAccountEntriesSince :many
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id FROM entries
+WHERE account_id = $1 AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListAccountEntriesSinceParams struct {
+	AccountID int64 `json:"accountID"`
+	AfterID   int64 `json:"afterID"`
+	Limit     int32 `json:"limit"`
+}
+
+func (q *Queries) ListAccountEntriesSince(ctx context.Context, arg ListAccountEntriesSinceParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountEntriesSince, arg.AccountID, arg.AfterID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.AdjustmentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, adjustment_id FROM entries
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -87,6 +344,8 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.AdjustmentID,
 		); err != nil {
 			return nil, err
 		}
@@ -105,7 +364,7 @@ const updateEntry = `-- name: UpdateEntry :one
 UPDATE entries
   set amount = $2
 WHERE id = $1
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, transfer_id, adjustment_id
 `
 
 type UpdateEntryParams struct {
@@ -121,6 +380,8 @@ func (q *Queries) UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.AdjustmentID,
 	)
 	return i, err
 }
@@ -101,3 +101,40 @@ func TestDeleteEntry(t *testing.T) {
 	require.EqualError(t, err, sql.ErrNoRows.Error())
 	require.Empty(t, deletedEntry)
 }
+
+func TestListAccountEntriesSince(t *testing.T) {
+	account := createRandomAccount(t)
+
+	lastEntryID, err := testQueries.GetAccountLastEntryID(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, lastEntryID)
+
+	entries := make([]Entry, 3)
+	for i := range entries {
+		entries[i], err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    util.RandomMoney(),
+		})
+		require.NoError(t, err)
+	}
+
+	lastEntryID, err = testQueries.GetAccountLastEntryID(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, entries[2].ID, lastEntryID)
+
+	since, err := testQueries.ListAccountEntriesSince(context.Background(), ListAccountEntriesSinceParams{
+		AccountID: account.ID,
+		AfterID:   entries[0].ID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, entries[1:], since)
+
+	since, err = testQueries.ListAccountEntriesSince(context.Background(), ListAccountEntriesSinceParams{
+		AccountID: account.ID,
+		AfterID:   lastEntryID,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, since)
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func upsertRandomExchangeRate(t *testing.T, fromCurrency string, toCurrency string) ExchangeRate {
+	arg := UpsertExchangeRateParams{
+		FromCurrency: fromCurrency,
+		ToCurrency:   toCurrency,
+		Rate:         "1.5",
+	}
+
+	exchangeRate, err := testQueries.UpsertExchangeRate(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, exchangeRate)
+
+	require.Equal(t, arg.FromCurrency, exchangeRate.FromCurrency)
+	require.Equal(t, arg.ToCurrency, exchangeRate.ToCurrency)
+	require.Equal(t, arg.Rate, exchangeRate.Rate)
+	require.NotZero(t, exchangeRate.UpdatedAt)
+
+	return exchangeRate
+}
+
+func TestUpsertExchangeRate(t *testing.T) {
+	exchangeRateBefore := upsertRandomExchangeRate(t, util.EUR, util.RUB)
+
+	arg := UpsertExchangeRateParams{
+		FromCurrency: util.EUR,
+		ToCurrency:   util.RUB,
+		Rate:         "98.1234",
+	}
+
+	exchangeRateAfter, err := testQueries.UpsertExchangeRate(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.Rate, exchangeRateAfter.Rate)
+	require.False(t, exchangeRateAfter.UpdatedAt.Before(exchangeRateBefore.UpdatedAt))
+}
+
+func TestGetExchangeRate(t *testing.T) {
+	exchangeRateActual := upsertRandomExchangeRate(t, util.RUB, util.USD)
+
+	exchangeRateExpect, err := testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		FromCurrency: util.RUB,
+		ToCurrency:   util.USD,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, exchangeRateActual.FromCurrency, exchangeRateExpect.FromCurrency)
+	require.Equal(t, exchangeRateActual.ToCurrency, exchangeRateExpect.ToCurrency)
+	require.Equal(t, exchangeRateActual.Rate, exchangeRateExpect.Rate)
+	require.WithinDuration(t, exchangeRateActual.UpdatedAt, exchangeRateExpect.UpdatedAt, time.Second)
+}
+
+func TestListExchangeRates(t *testing.T) {
+	upsertRandomExchangeRate(t, util.EUR, util.USD)
+	upsertRandomExchangeRate(t, util.USD, util.RUB)
+
+	exchangeRates, err := testQueries.ListExchangeRates(context.Background())
+
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(exchangeRates), 2)
+
+	for _, exchangeRate := range exchangeRates {
+		require.NotEmpty(t, exchangeRate)
+		require.NotEqual(t, exchangeRate.FromCurrency, exchangeRate.ToCurrency)
+	}
+}
+
+func TestDeleteExchangeRate(t *testing.T) {
+	upsertRandomExchangeRate(t, util.RUB, util.EUR)
+
+	arg := DeleteExchangeRateParams{
+		FromCurrency: util.RUB,
+		ToCurrency:   util.EUR,
+	}
+
+	err := testQueries.DeleteExchangeRate(context.Background(), arg)
+	require.NoError(t, err)
+
+	deletedExchangeRate, err := testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		FromCurrency: util.RUB,
+		ToCurrency:   util.EUR,
+	})
+
+	require.Error(t, err)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+	require.Empty(t, deletedExchangeRate)
+}
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomHold(t *testing.T, account Account, amount int64) Hold {
+	store := NewStore(testDB)
+
+	arg := AuthorizeHoldTxParam{
+		AccountID: account.ID,
+		Amount:    amount,
+		ExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	hold, err := store.AuthorizeHoldTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, hold)
+
+	require.Equal(t, arg.AccountID, hold.AccountID)
+	require.Equal(t, arg.Amount, hold.Amount)
+	require.Equal(t, util.ActiveHold, hold.Status)
+	require.Zero(t, hold.CapturedAmount)
+	require.False(t, hold.TransferID.Valid)
+	require.WithinDuration(t, arg.ExpiredAt, hold.ExpiredAt, time.Second)
+
+	return hold
+}
+
+func TestAuthorizeHoldTx(t *testing.T) {
+	account := createFundedAccount(t, util.USD, 100)
+
+	createRandomHold(t, account, 60)
+
+	heldAmount, err := testQueries.GetHeldAmount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(60), heldAmount)
+
+	//balance itself is not touched
+	account2, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, account2.Balance)
+
+	//only 40 is still available
+	store := NewStore(testDB)
+	_, err = store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParam{
+		AccountID: account.ID,
+		Amount:    41,
+		ExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxRespectsHolds(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, util.USD, 100)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	createRandomHold(t, fromAccount, 60)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        41,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        40,
+	})
+	require.NoError(t, err)
+}
+
+func TestCaptureHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, util.USD, 100)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	hold := createRandomHold(t, fromAccount, 60)
+
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParam{
+		HoldID:      hold.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      61,
+	})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	//capture part of the hold, the rest becomes available again
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParam{
+		HoldID:      hold.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      50,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, util.CapturedHold, result.Hold.Status)
+	require.Equal(t, int64(50), result.Hold.CapturedAmount)
+	require.Equal(t, sql.NullInt64{Int64: result.Transfer.ID, Valid: true}, result.Hold.TransferID)
+
+	require.Equal(t, int64(50), result.Transfer.Amount)
+	require.Equal(t, fromAccount.Balance-50, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+50, result.ToAccount.Balance)
+
+	heldAmount, err := testQueries.GetHeldAmount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Zero(t, heldAmount)
+
+	//hold can be captured only once
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParam{
+		HoldID:      hold.ID,
+		ToAccountID: toAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestCaptureHoldTxFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	fee := setRandomCurrencyFee(t, SetTransferFeeParams{Flat: 5, Percent: "0"})
+
+	fromAccount := createFundedAccount(t, fee.Currency, 100)
+	toAccount := createRandomAccountWithCurrency(t, fee.Currency)
+
+	hold := createRandomHold(t, fromAccount, 60)
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParam{
+		HoldID:      hold.ID,
+		ToAccountID: toAccount.ID,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(60), result.Transfer.Amount)
+	require.Equal(t, int64(5), result.Transfer.Fee)
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, int64(5), result.FeeEntry.Amount)
+	require.Equal(t, fromAccount.Balance-65, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+60, result.ToAccount.Balance)
+}
+
+func TestCaptureHoldTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, util.USD, 100)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	hold := createRandomHold(t, fromAccount, 60)
+
+	_, err := testQueries.SetTransferLimit(context.Background(), SetTransferLimitParams{
+		AccountID: sql.NullInt64{Int64: fromAccount.ID, Valid: true},
+		Currency:  util.USD,
+		MaxAmount: sql.NullInt64{Int64: 50, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParam{
+		HoldID:      hold.ID,
+		ToAccountID: toAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	//the failed capture is rolled back, so the hold is still active
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParam{
+		HoldID:      hold.ID,
+		ToAccountID: toAccount.ID,
+		Amount:      50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.CapturedHold, result.Hold.Status)
+}
+
+func TestReleaseHold(t *testing.T) {
+	account := createFundedAccount(t, util.USD, 100)
+	hold := createRandomHold(t, account, 60)
+
+	hold2, err := testQueries.ReleaseHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.ReleasedHold, hold2.Status)
+
+	heldAmount, err := testQueries.GetHeldAmount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, heldAmount)
+
+	//released hold can't be released again
+	_, err = testQueries.ReleaseHold(context.Background(), hold.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestExpireHolds(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, util.USD, 100)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	hold, err := store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParam{
+		AccountID: fromAccount.ID,
+		Amount:    60,
+		ExpiredAt: time.Now().Add(time.Second),
+	})
+	require.NoError(t, err)
+
+	time.Sleep(time.Second)
+
+	//expired hold doesn't reserve money even before the expirer runs
+	heldAmount, err := testQueries.GetHeldAmount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Zero(t, heldAmount)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParam{
+		HoldID:      hold.ID,
+		ToAccountID: toAccount.ID,
+	})
+	require.ErrorIs(t, err, ErrHoldExpired)
+
+	n, err := testQueries.ExpireHolds(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1))
+
+	hold2, err := testQueries.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.ExpiredHold, hold2.Status)
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// AuthorizeHoldTxParam contain information for reserving money of an account
+type AuthorizeHoldTxParam struct {
+	AccountID int64     `json:"account_id"`
+	Amount    int64     `json:"amount"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+// AuthorizeHoldTx reserves part of the available balance of an account without moving money
+// It locks the account the same way as TransferTx does and returns ErrInsufficientFunds if available balance is too low
+func (store *SQLStore) AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParam) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status != util.ActiveAccount {
+			return ErrAccountNotActive
+		}
+
+		available, err := availableBalance(ctx, q, account)
+		if err != nil {
+			return err
+		}
+
+		if available < arg.Amount {
+			return ErrInsufficientFunds
+		}
+
+		hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID: arg.AccountID,
+			Amount:    arg.Amount,
+			ExpiredAt: arg.ExpiredAt,
+		})
+
+		return err
+	})
+
+	return hold, err
+}
+
+// CaptureHoldTxParam contain information for turning a hold into a transfer
+// Zero Amount captures the whole hold
+type CaptureHoldTxParam struct {
+	HoldID      int64 `json:"hold_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	Amount      int64 `json:"amount"`
+}
+
+// CaptureHoldTxResult contain the captured hold and result of its transfer
+type CaptureHoldTxResult struct {
+	Hold Hold `json:"hold"`
+	TransferTxResult
+}
+
+// CaptureHoldTx transfers all or part of the held money to the receiver and closes the hold
+// The part which is not captured becomes available again
+// A capture is an ordinary transfer of the account owner, so it is charged the transfer fee and counts towards transfer limits
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParam) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		//the function may be run several times so start from empty result
+		result = CaptureHoldTxResult{}
+
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != util.ActiveHold {
+			return ErrHoldNotActive
+		}
+
+		if !hold.ExpiredAt.After(time.Now()) {
+			return ErrHoldExpired
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		//the hold is still active, so its amount may be spent on top of available balance
+		result.TransferTxResult, err = executeTransfer(ctx, q, hold.AccountID, arg.ToAccountID, amount, hold.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CaptureHold(ctx, CaptureHoldParams{
+			ID:             hold.ID,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,173 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomIdempotencyKey(t *testing.T, expiredAt time.Time) IdempotencyKey {
+	arg := CreateIdempotencyKeyParams{
+		Username:    util.RandomOwner(),
+		Key:         util.RandomString(32),
+		RequestHash: util.RandomString(64),
+		ExpiredAt:   expiredAt,
+	}
+
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, key)
+
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.Key, key.Key)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.JSONEq(t, "{}", string(key.Response))
+	require.WithinDuration(t, arg.ExpiredAt, key.ExpiredAt, time.Second)
+	require.NotZero(t, key.CreatedAt)
+
+	return key
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	createRandomIdempotencyKey(t, time.Now().Add(time.Minute))
+}
+
+func TestCreateIdempotencyKeyConflict(t *testing.T) {
+	key := createRandomIdempotencyKey(t, time.Now().Add(time.Minute))
+
+	arg := CreateIdempotencyKeyParams{
+		Username:    key.Username,
+		Key:         key.Key,
+		RequestHash: util.RandomString(64),
+		ExpiredAt:   time.Now().Add(time.Minute),
+	}
+
+	conflictKey, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+
+	require.Error(t, err)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+	require.Empty(t, conflictKey)
+}
+
+func TestCreateIdempotencyKeyExpired(t *testing.T) {
+	key := createRandomIdempotencyKey(t, time.Now().Add(-time.Minute))
+
+	arg := CreateIdempotencyKeyParams{
+		Username:    key.Username,
+		Key:         key.Key,
+		RequestHash: util.RandomString(64),
+		ExpiredAt:   time.Now().Add(time.Minute),
+	}
+
+	renewedKey, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.Key, renewedKey.Key)
+	require.Equal(t, arg.RequestHash, renewedKey.RequestHash)
+	require.WithinDuration(t, arg.ExpiredAt, renewedKey.ExpiredAt, time.Second)
+}
+
+func TestCreateIdempotencyKeyOtherUser(t *testing.T) {
+	key := createRandomIdempotencyKey(t, time.Now().Add(time.Minute))
+
+	arg := CreateIdempotencyKeyParams{
+		Username:    util.RandomOwner(),
+		Key:         key.Key,
+		RequestHash: util.RandomString(64),
+		ExpiredAt:   time.Now().Add(time.Minute),
+	}
+
+	//the same key of another user is a different key
+	otherKey, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.Equal(t, arg.Username, otherKey.Username)
+	require.Equal(t, arg.RequestHash, otherKey.RequestHash)
+
+	keyAfter, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: key.Username,
+		Key:      key.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key.RequestHash, keyAfter.RequestHash)
+}
+
+func TestDeleteExpiredIdempotencyKeys(t *testing.T) {
+	expiredKey := createRandomIdempotencyKey(t, time.Now().Add(-time.Minute))
+	activeKey := createRandomIdempotencyKey(t, time.Now().Add(time.Minute))
+
+	deleted, err := testQueries.DeleteExpiredIdempotencyKeys(context.Background(), time.Now())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, deleted, int64(1))
+
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: expiredKey.Username,
+		Key:      expiredKey.Key,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: activeKey.Username,
+		Key:      activeKey.Key,
+	})
+	require.NoError(t, err)
+}
+
+func TestDeleteExpiredIdempotencyKeysRetained(t *testing.T) {
+	//the key expired a minute ago but is kept for an hour after expiry
+	retainedKey := createRandomIdempotencyKey(t, time.Now().Add(-time.Minute))
+
+	_, err := testQueries.DeleteExpiredIdempotencyKeys(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+
+	_, err = testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: retainedKey.Username,
+		Key:      retainedKey.Key,
+	})
+	require.NoError(t, err)
+}
+
+func TestGetIdempotencyKey(t *testing.T) {
+	keyActual := createRandomIdempotencyKey(t, time.Now().Add(time.Minute))
+
+	keyExpect, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: keyActual.Username,
+		Key:      keyActual.Key,
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, keyExpect)
+
+	require.Equal(t, keyExpect.Key, keyActual.Key)
+	require.Equal(t, keyExpect.RequestHash, keyActual.RequestHash)
+	require.WithinDuration(t, keyExpect.CreatedAt, keyActual.CreatedAt, time.Second)
+	require.WithinDuration(t, keyExpect.ExpiredAt, keyActual.ExpiredAt, time.Second)
+}
+
+func TestUpdateIdempotencyKeyResponse(t *testing.T) {
+	keyBefore := createRandomIdempotencyKey(t, time.Now().Add(time.Minute))
+
+	response, err := json.Marshal(map[string]string{"owner": util.RandomOwner()})
+	require.NoError(t, err)
+
+	arg := UpdateIdempotencyKeyResponseParams{
+		Username: keyBefore.Username,
+		Key:      keyBefore.Key,
+		Response: response,
+	}
+
+	keyAfter, err := testQueries.UpdateIdempotencyKeyResponse(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, keyAfter)
+
+	require.Equal(t, keyBefore.Key, keyAfter.Key)
+	require.Equal(t, keyBefore.RequestHash, keyAfter.RequestHash)
+	require.JSONEq(t, string(arg.Response), string(keyAfter.Response))
+}
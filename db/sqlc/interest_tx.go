@@ -0,0 +1,277 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// SetAccountInterestTxParam contain the account, the product it earns interest of and the day it is accrued through
+// Version is the version of the account the caller has read, 0 changes the account whatever its version is
+type SetAccountInterestTxParam struct {
+	AccountID      int64     `json:"account_id"`
+	ProductID      int64     `json:"product_id"`
+	AccruedThrough time.Time `json:"accrued_through"`
+	Version        int64     `json:"version"`
+}
+
+// SetAccountInterestTxResult contain the interest of the account and the account with its new version
+type SetAccountInterestTxResult struct {
+	AccountInterest AccountInterest `json:"account_interest"`
+	Account         Account         `json:"account"`
+}
+
+// SetAccountInterestTx makes the account earn interest of the product and bumps the account version, so its ETag changes
+// It returns ErrVersionMismatch if the account has been changed since the given version was read and ErrAccountNotActive if it is closed
+func (store *SQLStore) SetAccountInterestTx(ctx context.Context, arg SetAccountInterestTxParam) (SetAccountInterestTxResult, error) {
+	var result SetAccountInterestTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if arg.Version != 0 && account.Version != arg.Version {
+			return ErrVersionMismatch
+		}
+
+		if account.Status == util.ClosedAccount {
+			return ErrAccountNotActive
+		}
+
+		result.AccountInterest, err = q.SetAccountInterest(ctx, SetAccountInterestParams{
+			AccountID:      account.ID,
+			ProductID:      arg.ProductID,
+			AccruedThrough: arg.AccruedThrough,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.IncrementAccountVersion(ctx, account.ID)
+		return err
+	})
+
+	return result, err
+}
+
+// AccrueInterestTxParam contain the account and the last day to accrue interest for
+type AccrueInterestTxParam struct {
+	AccountID int64     `json:"account_id"`
+	Through   time.Time `json:"through"`
+}
+
+// AccrueInterestTxResult contain accruals of every day and transfers which posted interest at the ends of months
+type AccrueInterestTxResult struct {
+	AccountInterest AccountInterest    `json:"account_interest"`
+	Accruals        []InterestAccrual  `json:"accruals"`
+	Postings        []TransferTxResult `json:"postings"`
+}
+
+// AccrueInterestTx accrues interest of the account for every day after the last accrued one through the given day
+// Interest is accrued on the balance at the end of each day and the exact accrued amount is kept with its fraction
+// On the last day of a month the whole part of accrued interest is posted from the interest expense account of the currency
+// Days which are already accrued are skipped, so running it again for the same day changes nothing
+// Interest is accrued only while the account is active, days when it is frozen or closed earn nothing
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParam) (AccrueInterestTxResult, error) {
+	var result AccrueInterestTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		//the function may be run several times so start from empty result
+		result = AccrueInterestTxResult{
+			Accruals: []InterestAccrual{},
+			Postings: []TransferTxResult{},
+		}
+
+		accountInterest, err := q.GetAccountInterestForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		result.AccountInterest = accountInterest
+
+		through := truncateToDay(arg.Through)
+		if !accountInterest.AccruedThrough.Before(through) {
+			return nil
+		}
+
+		product, err := q.GetInterestProduct(ctx, accountInterest.ProductID)
+		if err != nil {
+			return err
+		}
+
+		//the lock keeps the account from being frozen or closed while interest is accrued and posted
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		//frozen and closed accounts earn nothing, their days are skipped so they are not due again
+		if account.Status != util.ActiveAccount {
+			result.AccountInterest, err = q.UpdateAccountInterest(ctx, UpdateAccountInterestParams{
+				AccountID:      account.ID,
+				Accrued:        accountInterest.Accrued,
+				AccruedThrough: through,
+			})
+			return err
+		}
+
+		accrued := accountInterest.Accrued
+
+		//interest posted within this run is created now, so add it to balances of the following days
+		var posted int64
+
+		for day := truncateToDay(accountInterest.AccruedThrough).AddDate(0, 0, 1); !day.After(through); day = day.AddDate(0, 0, 1) {
+			nextDay := day.AddDate(0, 0, 1)
+
+			balance, err := q.GetAccountEntriesSum(ctx, GetAccountEntriesSumParams{
+				AccountID: account.ID,
+				CreatedAt: nextDay,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			balance += posted
+
+			interest, newAccrued, err := util.AccrueDailyInterest(balance, accrued, product.AnnualRate, product.Compounding)
+			if err != nil {
+				return err
+			}
+
+			accrued = newAccrued
+
+			//interest is posted on the last day of a month
+			var transferID sql.NullInt64
+			if nextDay.Day() == 1 {
+				whole, rest, err := util.SplitAccruedInterest(accrued)
+				if err != nil {
+					return err
+				}
+
+				if whole > 0 {
+					posting, err := postInterest(ctx, q, account, whole)
+					if err != nil {
+						return err
+					}
+
+					result.Postings = append(result.Postings, posting)
+					transferID = sql.NullInt64{Int64: posting.Transfer.ID, Valid: true}
+					posted += whole
+					accrued = rest
+				}
+			}
+
+			accrual, err := q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+				AccountID:   account.ID,
+				AccrualDate: day,
+				Basis:       balance,
+				Amount:      interest,
+				TransferID:  transferID,
+			})
+
+			if err != nil {
+				return err
+			}
+
+			result.Accruals = append(result.Accruals, accrual)
+		}
+
+		result.AccountInterest, err = q.UpdateAccountInterest(ctx, UpdateAccountInterestParams{
+			AccountID:      account.ID,
+			Accrued:        accrued,
+			AccruedThrough: through,
+		})
+
+		return err
+	})
+
+	return result, err
+}
+
+// postInterest moves interest from the interest expense account of the currency to the account
+// The expense account is the only one which may go below zero, so its balance isn't checked
+func postInterest(ctx context.Context, q *Queries, account Account, amount int64) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	expenseAccount, err := systemAccount(ctx, q, util.InterestExpenseOwner, account.Currency)
+	if err != nil {
+		return result, err
+	}
+
+	_, _, err = getAccountsForUpdate(ctx, q, expenseAccount.ID, account.ID)
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: expenseAccount.ID,
+		ToAccountID:   account.ID,
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+		Rounding:      "0",
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	err = recordTransferCreated(ctx, q, result.Transfer)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  expenseAccount.ID,
+		Amount:     -amount,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  account.ID,
+		Amount:     amount,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount, result.ToAccount, err = moveMoney(ctx, q, expenseAccount.ID, amount, account.ID, amount)
+
+	return result, err
+}
+
+// systemAccount returns the account of the system user in the currency and creates it on first use
+func systemAccount(ctx context.Context, q *Queries, owner string, currency string) (Account, error) {
+	account, err := q.GetAccountByOwner(ctx, GetAccountByOwnerParams{
+		Owner:    owner,
+		Currency: currency,
+	})
+
+	if err == sql.ErrNoRows {
+		return q.CreateAccount(ctx, CreateAccountParams{
+			Owner:    owner,
+			Balance:  0,
+			Currency: currency,
+		})
+	}
+
+	return account, err
+}
+
+// truncateToDay returns midnight UTC of the day
+func truncateToDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomInterestProduct(t *testing.T, currency string, annualRate string, compounding string) InterestProduct {
+	arg := CreateInterestProductParams{
+		Name:        util.RandomString(12),
+		Currency:    currency,
+		AnnualRate:  annualRate,
+		Compounding: compounding,
+	}
+
+	product, err := testQueries.CreateInterestProduct(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Name, product.Name)
+	require.Equal(t, arg.AnnualRate, product.AnnualRate)
+
+	return product
+}
+
+func TestAccrueInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountWithCurrency(t, util.USD)
+
+	//36500 at 10% a year earns exactly 10 a day
+	_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 36500})
+	require.NoError(t, err)
+
+	product := createRandomInterestProduct(t, util.USD, "0.1", util.MonthlyCompounding)
+
+	today := truncateToDay(time.Now())
+	lastDayOfMonth := time.Date(today.Year(), today.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	days := int64(lastDayOfMonth.Sub(today)/(24*time.Hour)) + 1
+
+	_, err = testQueries.SetAccountInterest(context.Background(), SetAccountInterestParams{
+		AccountID:      account.ID,
+		ProductID:      product.ID,
+		AccruedThrough: today.AddDate(0, 0, -1),
+	})
+	require.NoError(t, err)
+
+	result, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParam{
+		AccountID: account.ID,
+		Through:   lastDayOfMonth,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, result.Accruals, int(days))
+	for _, accrual := range result.Accruals {
+		require.Equal(t, int64(36500), accrual.Basis)
+		require.Equal(t, "10.0000000000", accrual.Amount)
+	}
+
+	//whole interest of the month is posted on its last day from the expense account
+	require.Len(t, result.Postings, 1)
+	posting := result.Postings[0]
+	require.Equal(t, 10*days, posting.Transfer.Amount)
+	require.Equal(t, util.InterestExpenseOwner, posting.FromAccount.Owner)
+	require.Equal(t, account.Balance+10*days, posting.ToAccount.Balance)
+	require.Equal(t, sql.NullInt64{Int64: posting.Transfer.ID, Valid: true}, result.Accruals[days-1].TransferID)
+
+	require.Equal(t, "0.0000000000", result.AccountInterest.Accrued)
+	require.True(t, lastDayOfMonth.Equal(truncateToDay(result.AccountInterest.AccruedThrough)))
+
+	//days which are accrued already are skipped
+	result, err = store.AccrueInterestTx(context.Background(), AccrueInterestTxParam{
+		AccountID: account.ID,
+		Through:   lastDayOfMonth,
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Accruals)
+	require.Empty(t, result.Postings)
+}
+
+func TestAccrueInterestTxSkipsInactiveAccounts(t *testing.T) {
+	store := NewStore(testDB)
+
+	for _, status := range []string{util.FrozenAccount, util.ClosedAccount} {
+		account := createRandomAccountWithCurrency(t, util.USD)
+
+		_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 36500})
+		require.NoError(t, err)
+
+		product := createRandomInterestProduct(t, util.USD, "0.1", util.MonthlyCompounding)
+
+		today := truncateToDay(time.Now())
+
+		accountInterest, err := testQueries.SetAccountInterest(context.Background(), SetAccountInterestParams{
+			AccountID:      account.ID,
+			ProductID:      product.ID,
+			AccruedThrough: today.AddDate(0, 0, -3),
+		})
+		require.NoError(t, err)
+
+		//the status changes after the account was listed as due
+		_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+			ID:         account.ID,
+			Status:     status,
+			FromStatus: util.ActiveAccount,
+			Version:    account.Version,
+		})
+		require.NoError(t, err)
+
+		result, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParam{
+			AccountID: account.ID,
+			Through:   today,
+		})
+		require.NoError(t, err)
+
+		require.Empty(t, result.Accruals, status)
+		require.Empty(t, result.Postings, status)
+		require.Equal(t, accountInterest.Accrued, result.AccountInterest.Accrued)
+		require.True(t, today.Equal(truncateToDay(result.AccountInterest.AccruedThrough)), status)
+
+		account2, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, account2.Balance)
+	}
+}
+
+func TestAccrueInterestTxKeepsFraction(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountWithCurrency(t, util.EUR)
+
+	_, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 100})
+	require.NoError(t, err)
+
+	product := createRandomInterestProduct(t, util.EUR, "0.05", util.DailyCompounding)
+
+	//accrue today only, which is posted if today is the last day of the month
+	today := truncateToDay(time.Now())
+
+	_, err = testQueries.SetAccountInterest(context.Background(), SetAccountInterestParams{
+		AccountID:      account.ID,
+		ProductID:      product.ID,
+		AccruedThrough: today.AddDate(0, 0, -1),
+	})
+	require.NoError(t, err)
+
+	result, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParam{
+		AccountID: account.ID,
+		Through:   today,
+	})
+	require.NoError(t, err)
+
+	//100 at 5% earns less than one a day, so nothing is posted and the fraction is kept
+	require.Len(t, result.Accruals, 1)
+	require.Equal(t, "0.0136986301", result.Accruals[0].Amount)
+	require.Empty(t, result.Postings)
+	require.Equal(t, "0.0136986301", result.AccountInterest.Accrued)
+}
+
+func TestSetAccountInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccountWithCurrency(t, util.USD)
+	product := createRandomInterestProduct(t, util.USD, "0.1", util.MonthlyCompounding)
+
+	arg := SetAccountInterestTxParam{
+		AccountID:      account.ID,
+		ProductID:      product.ID,
+		AccruedThrough: truncateToDay(time.Now()).AddDate(0, 0, -1),
+		Version:        account.Version,
+	}
+
+	result, err := store.SetAccountInterestTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, product.ID, result.AccountInterest.ProductID)
+	require.Equal(t, account.Version+1, result.Account.Version)
+
+	//the version read before the change is stale now
+	_, err = store.SetAccountInterestTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrVersionMismatch)
+
+	accountInterest, err := testQueries.GetAccountInterest(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, product.ID, accountInterest.ProductID)
+}
@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -14,6 +16,61 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"createdAt"`
+	// active, frozen or closed
+	Status string `json:"status"`
+	// incremented on every change of the account
+	Version int64 `json:"version"`
+}
+
+type AccountInterest struct {
+	AccountID int64 `json:"accountID"`
+	ProductID int64 `json:"productID"`
+	// exact interest accrued and not posted yet
+	Accrued string `json:"accrued"`
+	// last day interest is accrued for
+	AccruedThrough time.Time `json:"accruedThrough"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type Adjustment struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"accountID"`
+	// account the balancing entry is booked to
+	SuspenseAccountID int64 `json:"suspenseAccountID"`
+	// added to the balance of the account, negative to take money away
+	Amount int64 `json:"amount"`
+	// correction, chargeback, goodwill or write_off
+	Reason string `json:"reason"`
+	// ticket or document which explains the adjustment
+	Reference  string    `json:"reference"`
+	AdjustedBy string    `json:"adjustedBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+type AuditLog struct {
+	ID int64 `json:"id"`
+	// user of the access token, empty for requests without one
+	Username string `json:"username"`
+	Method   string `json:"method"`
+	// route pattern like /accounts/:id/freeze, the path if no route matched
+	Route string `json:"route"`
+	// path parameters of the route joined by slashes, empty for routes without them
+	ResourceID string `json:"resourceID"`
+	ClientIp   string `json:"clientIp"`
+	UserAgent  string `json:"userAgent"`
+	// HTTP status of the response
+	Status int32 `json:"status"`
+	// JSON body with secrets redacted, null without a body
+	RequestBody json.RawMessage `json:"requestBody"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+type BalanceSnapshot struct {
+	AccountID int64 `json:"accountID"`
+	// midnight UTC, the balance sums entries created before it
+	SnapshotAt time.Time `json:"snapshotAt"`
+	Balance    int64     `json:"balance"`
+	CreatedAt  time.Time `json:"createdAt"`
 }
 
 type Entry struct {
@@ -22,6 +79,132 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"createdAt"`
+	// transfer which created the entry, not a foreign key so reconciliation can report dangling links
+	TransferID sql.NullInt64 `json:"transferID"`
+	// adjustment which created the entry, not a foreign key so reconciliation can report dangling links
+	AdjustmentID sql.NullInt64 `json:"adjustmentID"`
+}
+
+type ExchangeRate struct {
+	FromCurrency string `json:"fromCurrency"`
+	ToCurrency   string `json:"toCurrency"`
+	// amount of to_currency for one unit of from_currency
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type Hold struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"accountID"`
+	// must be positive
+	Amount         int64 `json:"amount"`
+	CapturedAmount int64 `json:"capturedAmount"`
+	// transfer made by the capture
+	TransferID sql.NullInt64 `json:"transferID"`
+	// active, captured, released or expired
+	Status    string    `json:"status"`
+	ExpiredAt time.Time `json:"expiredAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type IdempotencyKey struct {
+	Key string `json:"key"`
+	// hash of the request the key was first used with
+	RequestHash string          `json:"requestHash"`
+	Response    json.RawMessage `json:"response"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	ExpiredAt   time.Time       `json:"expiredAt"`
+	// user the key belongs to, keys of different users never collide
+	Username string `json:"username"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"accountID"`
+	AccrualDate time.Time `json:"accrualDate"`
+	// amount interest is accrued on at the end of the day
+	Basis int64 `json:"basis"`
+	// exact interest of the day
+	Amount string `json:"amount"`
+	// transfer which posted interest at the end of the month
+	TransferID sql.NullInt64 `json:"transferID"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type InterestProduct struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Currency string `json:"currency"`
+	// decimal fraction, 0.05 is 5% a year
+	AnnualRate string `json:"annualRate"`
+	// daily or monthly
+	Compounding string    `json:"compounding"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type Outbox struct {
+	ID int64 `json:"id"`
+	// transfer or account
+	AggregateType string `json:"aggregateType"`
+	AggregateID   int64  `json:"aggregateID"`
+	// like transfer.created or account.status_changed
+	EventType string `json:"eventType"`
+	// state of the aggregate after the change
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+	// null until the relay has published the event
+	SentAt sql.NullTime `json:"sentAt"`
+	// relays skip the event until this time while another one publishes it
+	LockedUntil sql.NullTime `json:"lockedUntil"`
+}
+
+type PendingTransfer struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"fromAccountID"`
+	ToAccountID   int64 `json:"toAccountID"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// pending_approval, approved or rejected
+	Status    string `json:"status"`
+	CreatedBy string `json:"createdBy"`
+	// user who approved or rejected the transfer, never the creator
+	DecidedBy sql.NullString `json:"decidedBy"`
+	DecidedAt sql.NullTime   `json:"decidedAt"`
+	Reason    string         `json:"reason"`
+	// transfer made on approval
+	TransferID sql.NullInt64 `json:"transferID"`
+	CreatedAt  time.Time     `json:"createdAt"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"fromAccountID"`
+	ToAccountID   int64  `json:"toAccountID"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// empty for one-shot transfer, @daily, @weekly or @monthly
+	Recurrence string    `json:"recurrence"`
+	NextRunAt  time.Time `json:"nextRunAt"`
+	// active, completed, failed or cancelled
+	Status     string `json:"status"`
+	RetryCount int32  `json:"retryCount"`
+	MaxRetries int32  `json:"maxRetries"`
+	// workers skip the schedule until this time while it runs or waits for a retry
+	LockedUntil time.Time `json:"lockedUntil"`
+	CreatedAt   time.Time `json:"createdAt"`
+	// day of month in UTC monthly occurrences fall on, shorter months use their last day
+	AnchorDay int32 `json:"anchorDay"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64         `json:"id"`
+	ScheduledTransferID int64         `json:"scheduledTransferID"`
+	TransferID          sql.NullInt64 `json:"transferID"`
+	// succeeded, failed or skipped
+	Status    string    `json:"status"`
+	Error     string    `json:"error"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 type Transfer struct {
@@ -31,6 +214,48 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"createdAt"`
+	// amount credited to the target account in its currency
+	ToAmount     int64  `json:"toAmount"`
+	ExchangeRate string `json:"exchangeRate"`
+	// exact converted amount minus to_amount
+	Rounding string `json:"rounding"`
+	// transfer reversed by this one
+	ReversalOf sql.NullInt64 `json:"reversalOf"`
+	// part of amount returned by reversals
+	ReversedAmount int64 `json:"reversedAmount"`
+	// debited from the sender on top of the amount
+	Fee int64 `json:"fee"`
+	// account the fee is credited to
+	FeeAccountID sql.NullInt64 `json:"feeAccountID"`
+}
+
+type TransferFee struct {
+	ID int64 `json:"id"`
+	// currency of the sender, empty for the default of every currency
+	Currency string `json:"currency"`
+	Flat     int64  `json:"flat"`
+	// percent of the amount, 1.5 is 1.5%
+	Percent string `json:"percent"`
+	MinFee  int64  `json:"minFee"`
+	// 0 means no maximum
+	MaxFee    int64     `json:"maxFee"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type TransferLimit struct {
+	ID int64 `json:"id"`
+	// limit of one account, null for a user limit or a currency default
+	AccountID sql.NullInt64 `json:"accountID"`
+	// limit of all accounts of the user, transfers in other currencies are converted to currency of the limit
+	Owner    sql.NullString `json:"owner"`
+	Currency string         `json:"currency"`
+	// largest single transfer, null means unlimited
+	MaxAmount sql.NullInt64 `json:"maxAmount"`
+	// outgoing total over the last 24 hours, null means unlimited
+	DailyAmount sql.NullInt64 `json:"dailyAmount"`
+	// number of outgoing transfers over the last 24 hours, null means unlimited
+	DailyCount sql.NullInt32 `json:"dailyCount"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
 }
 
 type User struct {
@@ -40,4 +265,41 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"passwordChangedAt"`
 	CreatedAt         time.Time `json:"createdAt"`
+	Role              string    `json:"role"`
+}
+
+type Webhook struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// events sent to the webhook, empty for all events
+	EventTypes []string `json:"eventTypes"`
+	// key of the HMAC-SHA256 signature of deliveries
+	Secret    string    `json:"secret"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type WebhookAttempt struct {
+	ID         int64 `json:"id"`
+	DeliveryID int64 `json:"deliveryID"`
+	// HTTP status of the response, zero if the receiver did not respond
+	ResponseStatus int32     `json:"responseStatus"`
+	Error          string    `json:"error"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type WebhookDelivery struct {
+	ID        int64 `json:"id"`
+	WebhookID int64 `json:"webhookID"`
+	// outbox event which is delivered, null for test deliveries
+	EventID   sql.NullInt64   `json:"eventID"`
+	EventType string          `json:"eventType"`
+	Payload   json.RawMessage `json:"payload"`
+	// pending, succeeded or failed
+	Status   string `json:"status"`
+	Attempts int32  `json:"attempts"`
+	// workers skip the delivery until this time while it is sent or waits for a retry
+	NextAttemptAt time.Time    `json:"nextAttemptAt"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	DeliveredAt   sql.NullTime `json:"deliveredAt"`
 }
@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// AccountAdjustedPayload is the payload of account.adjusted events
+type AccountAdjustedPayload struct {
+	Account    Account    `json:"account"`
+	Adjustment Adjustment `json:"adjustment"`
+}
+
+// recordEvent writes the event to the outbox within the transaction of the change,
+// so the event is published if and only if the change is committed
+func recordEvent(ctx context.Context, q *Queries, aggregateType string, aggregateID int64, eventType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+	})
+
+	return err
+}
+
+// recordTransferCreated writes transfer.created event of the transfer to the outbox
+func recordTransferCreated(ctx context.Context, q *Queries, transfer Transfer) error {
+	return recordEvent(ctx, q, util.TransferAggregate, transfer.ID, util.TransferCreatedEvent, transfer)
+}
+
+// recordAccountEvent writes the event with the state of the account after the change to the outbox
+func recordAccountEvent(ctx context.Context, q *Queries, eventType string, account Account) error {
+	return recordEvent(ctx, q, util.AccountAggregate, account.ID, eventType, account)
+}
@@ -0,0 +1,165 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// listAggregateEvents returns all events of the aggregate in the outbox
+func listAggregateEvents(t *testing.T, aggregateType string, aggregateID int64) []Outbox {
+	rows, err := testDB.QueryContext(context.Background(),
+		`SELECT id, aggregate_type, aggregate_id, event_type, payload, created_at, sent_at, locked_until FROM outbox
+		WHERE aggregate_type = $1 AND aggregate_id = $2 ORDER BY id`, aggregateType, aggregateID)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	events := []Outbox{}
+	for rows.Next() {
+		var i Outbox
+		err := rows.Scan(&i.ID, &i.AggregateType, &i.AggregateID, &i.EventType, &i.Payload, &i.CreatedAt, &i.SentAt, &i.LockedUntil)
+		require.NoError(t, err)
+		events = append(events, i)
+	}
+	require.NoError(t, rows.Err())
+
+	return events
+}
+
+func TestTransferTxOutboxEvent(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, util.USD, 100)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	events := listAggregateEvents(t, util.TransferAggregate, result.Transfer.ID)
+	require.Len(t, events, 1)
+	require.Equal(t, util.TransferCreatedEvent, events[0].EventType)
+	require.False(t, events[0].SentAt.Valid)
+
+	var transfer Transfer
+	err = json.Unmarshal(events[0].Payload, &transfer)
+	require.NoError(t, err)
+	require.Equal(t, result.Transfer.ID, transfer.ID)
+	require.Equal(t, result.Transfer.Amount, transfer.Amount)
+
+	err = testQueries.MarkOutboxEventSent(context.Background(), events[0].ID)
+	require.NoError(t, err)
+
+	events = listAggregateEvents(t, util.TransferAggregate, result.Transfer.ID)
+	require.True(t, events[0].SentAt.Valid)
+}
+
+func TestAccountOutboxEvents(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+
+	account, err := store.CreateAccountTx(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: util.EUR,
+	})
+	require.NoError(t, err)
+
+	account, err = store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusParams{
+		Status:     util.FrozenAccount,
+		ID:         account.ID,
+		FromStatus: account.Status,
+		Version:    account.Version,
+	})
+	require.NoError(t, err)
+
+	//failed change is rolled back together with its event
+	_, err = store.UpdateAccountStatusTx(context.Background(), UpdateAccountStatusParams{
+		Status:     util.ActiveAccount,
+		ID:         account.ID,
+		FromStatus: account.Status,
+		Version:    account.Version - 1,
+	})
+	require.Error(t, err)
+
+	events := listAggregateEvents(t, util.AccountAggregate, account.ID)
+	require.Len(t, events, 2)
+	require.Equal(t, util.AccountCreatedEvent, events[0].EventType)
+	require.Equal(t, util.AccountStatusChangedEvent, events[1].EventType)
+
+	var payload Account
+	err = json.Unmarshal(events[1].Payload, &payload)
+	require.NoError(t, err)
+	require.Equal(t, util.FrozenAccount, payload.Status)
+}
+
+func TestClaimPendingOutboxEvents(t *testing.T) {
+	ctx := context.Background()
+
+	//send everything left by other tests, so only the events created here are pending
+	for {
+		events, err := testQueries.ClaimPendingOutboxEvents(ctx, ClaimPendingOutboxEventsParams{
+			LockedUntil: time.Now().Add(time.Minute),
+			Limit:       100,
+		})
+		require.NoError(t, err)
+
+		for _, event := range events {
+			require.NoError(t, testQueries.MarkOutboxEventSent(ctx, event.ID))
+		}
+
+		if len(events) < 100 {
+			break
+		}
+	}
+
+	created := make([]Outbox, 3)
+	for i := range created {
+		event, err := testQueries.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+			AggregateType: util.TransferAggregate,
+			AggregateID:   util.RandomInt(1, 1000),
+			EventType:     util.TransferCreatedEvent,
+			Payload:       json.RawMessage(`{}`),
+		})
+		require.NoError(t, err)
+		created[i] = event
+	}
+
+	claimed, err := testQueries.ClaimPendingOutboxEvents(ctx, ClaimPendingOutboxEventsParams{
+		LockedUntil: time.Now().Add(time.Minute),
+		Limit:       2,
+	})
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	require.Equal(t, created[0].ID, claimed[0].ID)
+	require.Equal(t, created[1].ID, claimed[1].ID)
+	require.True(t, claimed[0].LockedUntil.Valid)
+
+	//claimed events are skipped by other relays until the lease expires
+	claimed, err = testQueries.ClaimPendingOutboxEvents(ctx, ClaimPendingOutboxEventsParams{
+		LockedUntil: time.Now().Add(time.Minute),
+		Limit:       100,
+	})
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, created[2].ID, claimed[0].ID)
+
+	//released events can be claimed again right away
+	err = testQueries.ReleaseOutboxEvents(ctx, []int64{created[0].ID, created[1].ID})
+	require.NoError(t, err)
+
+	claimed, err = testQueries.ClaimPendingOutboxEvents(ctx, ClaimPendingOutboxEventsParams{
+		LockedUntil: time.Now().Add(time.Minute),
+		Limit:       100,
+	})
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	require.Equal(t, created[0].ID, claimed[0].ID)
+}
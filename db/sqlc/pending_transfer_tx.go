@@ -0,0 +1,112 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// CreatePendingTransferTxParam contain the transfer which waits for approval and the optional idempotency key of the request
+type CreatePendingTransferTxParam struct {
+	FromAccountID  int64     `json:"from_account_id"`
+	ToAccountID    int64     `json:"to_account_id"`
+	Amount         int64     `json:"amount"`
+	CreatedBy      string    `json:"created_by"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	KeyUsername    string    `json:"key_username"`
+	RequestHash    string    `json:"request_hash"`
+	KeyExpiredAt   time.Time `json:"key_expired_at"`
+}
+
+// CreatePendingTransferTx saves a transfer which is executed only after another user approves it
+// A retry with the same idempotency key returns the pending transfer created by the first request instead of another one
+func (store *SQLStore) CreatePendingTransferTx(ctx context.Context, arg CreatePendingTransferTxParam) (PendingTransfer, error) {
+	var result PendingTransfer
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		var err error
+
+		//the function may be run several times so start from empty result
+		result = PendingTransfer{}
+
+		if arg.IdempotencyKey != "" {
+			replayed, err := reserveIdempotencyKey(ctx, q, arg.KeyUsername, arg.IdempotencyKey, arg.RequestHash, arg.KeyExpiredAt, &result)
+			if err != nil || replayed {
+				return err
+			}
+		}
+
+		result, err = q.CreatePendingTransfer(ctx, CreatePendingTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			CreatedBy:     arg.CreatedBy,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.IdempotencyKey != "" {
+			return saveIdempotentResponse(ctx, q, arg.KeyUsername, arg.IdempotencyKey, result)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// ApproveTransferTxParam contain the pending transfer and the user who approves it
+type ApproveTransferTxParam struct {
+	ID         int64  `json:"id"`
+	ApprovedBy string `json:"approved_by"`
+}
+
+// ApproveTransferTxResult contain the approved pending transfer and the transfer made for it
+type ApproveTransferTxResult struct {
+	PendingTransfer PendingTransfer  `json:"pending_transfer"`
+	Transfer        TransferTxResult `json:"transfer"`
+}
+
+// ApproveTransferTx executes a transfer which waits for approval the same way as TransferTx does and records the decision
+// It returns ErrTransferNotPending if the transfer is already decided and ErrSelfApproval if the approver created it
+// If the transfer fails, for example because of insufficient funds, it stays pending and can be approved again or rejected
+func (store *SQLStore) ApproveTransferTx(ctx context.Context, arg ApproveTransferTxParam) (ApproveTransferTxResult, error) {
+	var result ApproveTransferTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		//the function may be run several times so start from empty result
+		result = ApproveTransferTxResult{}
+
+		pendingTransfer, err := q.GetPendingTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if pendingTransfer.Status != util.PendingApproval {
+			return ErrTransferNotPending
+		}
+
+		if pendingTransfer.CreatedBy == arg.ApprovedBy {
+			return ErrSelfApproval
+		}
+
+		result.Transfer, err = executeTransfer(ctx, q, pendingTransfer.FromAccountID, pendingTransfer.ToAccountID, pendingTransfer.Amount, 0)
+		if err != nil {
+			return err
+		}
+
+		result.PendingTransfer, err = q.DecidePendingTransfer(ctx, DecidePendingTransferParams{
+			ID:         pendingTransfer.ID,
+			Status:     util.ApprovedTransfer,
+			DecidedBy:  sql.NullString{String: arg.ApprovedBy, Valid: true},
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+
+		return err
+	})
+
+	return result, err
+}
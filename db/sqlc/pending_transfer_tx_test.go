@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPendingTransfer(t *testing.T, fromAccount Account, toAccount Account, amount int64) PendingTransfer {
+	arg := CreatePendingTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		CreatedBy:     fromAccount.Owner,
+	}
+
+	pendingTransfer, err := testQueries.CreatePendingTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.PendingApproval, pendingTransfer.Status)
+	require.Equal(t, arg.CreatedBy, pendingTransfer.CreatedBy)
+	require.False(t, pendingTransfer.DecidedBy.Valid)
+	require.False(t, pendingTransfer.TransferID.Valid)
+
+	return pendingTransfer
+}
+
+func TestCreatePendingTransferTxIdempotency(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+
+	arg := CreatePendingTransferTxParam{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         60,
+		CreatedBy:      account1.Owner,
+		IdempotencyKey: util.RandomString(16),
+		KeyUsername:    account1.Owner,
+		RequestHash:    util.RandomString(32),
+		KeyExpiredAt:   time.Now().Add(time.Hour),
+	}
+
+	pendingTransfer1, err := store.CreatePendingTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, util.PendingApproval, pendingTransfer1.Status)
+
+	//retry returns the same pending transfer instead of creating another one
+	pendingTransfer2, err := store.CreatePendingTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, pendingTransfer1.ID, pendingTransfer2.ID)
+
+	arg.Amount = 70
+	arg.RequestHash = util.RandomString(32)
+	_, err = store.CreatePendingTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestApproveTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, util.USD, 100)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	approver := createRandomUser(t)
+
+	pendingTransfer := createRandomPendingTransfer(t, account1, account2, 60)
+
+	//creator can't approve own transfer
+	_, err := store.ApproveTransferTx(context.Background(), ApproveTransferTxParam{
+		ID:         pendingTransfer.ID,
+		ApprovedBy: account1.Owner,
+	})
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	result, err := store.ApproveTransferTx(context.Background(), ApproveTransferTxParam{
+		ID:         pendingTransfer.ID,
+		ApprovedBy: approver.Username,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, util.ApprovedTransfer, result.PendingTransfer.Status)
+	require.Equal(t, approver.Username, result.PendingTransfer.DecidedBy.String)
+	require.True(t, result.PendingTransfer.DecidedAt.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.PendingTransfer.TransferID.Int64)
+
+	require.Equal(t, int64(60), result.Transfer.Transfer.Amount)
+	require.Equal(t, int64(40), result.Transfer.FromAccount.Balance)
+
+	//approved transfer is executed only once
+	_, err = store.ApproveTransferTx(context.Background(), ApproveTransferTxParam{
+		ID:         pendingTransfer.ID,
+		ApprovedBy: approver.Username,
+	})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
+
+func TestApproveTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, util.USD, 10)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	approver := createRandomUser(t)
+
+	pendingTransfer := createRandomPendingTransfer(t, account1, account2, 60)
+
+	_, err := store.ApproveTransferTx(context.Background(), ApproveTransferTxParam{
+		ID:         pendingTransfer.ID,
+		ApprovedBy: approver.Username,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	//failed approval leaves the transfer pending
+	pendingTransfer, err = testQueries.GetPendingTransfer(context.Background(), pendingTransfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, util.PendingApproval, pendingTransfer.Status)
+
+	rejected, err := testQueries.DecidePendingTransfer(context.Background(), DecidePendingTransferParams{
+		ID:        pendingTransfer.ID,
+		Status:    util.RejectedTransfer,
+		DecidedBy: sql.NullString{String: approver.Username, Valid: true},
+		Reason:    "not enough money",
+	})
+	require.NoError(t, err)
+	require.Equal(t, util.RejectedTransfer, rejected.Status)
+	require.Equal(t, "not enough money", rejected.Reason)
+
+	_, err = store.ApproveTransferTx(context.Background(), ApproveTransferTxParam{
+		ID:         pendingTransfer.ID,
+		ApprovedBy: approver.Username,
+	})
+	require.ErrorIs(t, err, ErrTransferNotPending)
+}
@@ -6,28 +6,125 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error)
+	CaptureHold(ctx context.Context, arg CaptureHoldParams) (Hold, error)
+	ClaimDueScheduledTransfer(ctx context.Context, lockedUntil time.Time) (ScheduledTransfer, error)
+	ClaimDueWebhookDelivery(ctx context.Context, nextAttemptAt time.Time) (WebhookDelivery, error)
+	ClaimPendingOutboxEvents(ctx context.Context, arg ClaimPendingOutboxEventsParams) ([]Outbox, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAdjustment(ctx context.Context, arg CreateAdjustmentParams) (Adjustment, error)
+	CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) (AuditLog, error)
+	CreateBalanceSnapshot(ctx context.Context, arg CreateBalanceSnapshotParams) (BalanceSnapshot, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateEventWebhookDeliveries(ctx context.Context, arg CreateEventWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateInterestAccrual(ctx context.Context, arg CreateInterestAccrualParams) (InterestAccrual, error)
+	CreateInterestProduct(ctx context.Context, arg CreateInterestProductParams) (InterestProduct, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (PendingTransfer, error)
+	CreateReverseTransfer(ctx context.Context, arg CreateReverseTransferParams) (Transfer, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
-	DeleteAccount(ctx context.Context, id int64) error
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	CreateWebhookAttempt(ctx context.Context, arg CreateWebhookAttemptParams) (WebhookAttempt, error)
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) (WebhookDelivery, error)
+	DecidePendingTransfer(ctx context.Context, arg DecidePendingTransferParams) (PendingTransfer, error)
 	DeleteEntry(ctx context.Context, id int64) error
+	DeleteExchangeRate(ctx context.Context, arg DeleteExchangeRateParams) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, expiredAt time.Time) (int64, error)
 	DeleteTransfer(ctx context.Context, id int64) error
+	DeleteTransferFee(ctx context.Context, id int64) (int64, error)
+	DeleteTransferLimit(ctx context.Context, id int64) (int64, error)
+	DeleteWebhook(ctx context.Context, id int64) (int64, error)
+	ExpireHolds(ctx context.Context) (int64, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountByOwner(ctx context.Context, arg GetAccountByOwnerParams) (Account, error)
+	GetAccountEntriesSum(ctx context.Context, arg GetAccountEntriesSumParams) (int64, error)
+	GetAccountEntriesSumBetween(ctx context.Context, arg GetAccountEntriesSumBetweenParams) (int64, error)
+	GetAccountEntriesSumSince(ctx context.Context, arg GetAccountEntriesSumSinceParams) (int64, error)
+	GetAccountEntriesSumThrough(ctx context.Context, arg GetAccountEntriesSumThroughParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountInterest(ctx context.Context, accountID int64) (AccountInterest, error)
+	GetAccountInterestForUpdate(ctx context.Context, accountID int64) (AccountInterest, error)
+	GetAccountLastEntryID(ctx context.Context, accountID int64) (int64, error)
+	GetAccountOutgoingTotal(ctx context.Context, arg GetAccountOutgoingTotalParams) (GetAccountOutgoingTotalRow, error)
+	GetAdjustment(ctx context.Context, id int64) (Adjustment, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetHeldAmount(ctx context.Context, accountID int64) (int64, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetInterestProduct(ctx context.Context, id int64) (InterestProduct, error)
+	GetLatestBalanceSnapshot(ctx context.Context, arg GetLatestBalanceSnapshotParams) (BalanceSnapshot, error)
+	GetOldestTransactionStart(ctx context.Context) (GetOldestTransactionStartRow, error)
+	GetPendingTransfer(ctx context.Context, id int64) (PendingTransfer, error)
+	GetPendingTransferForUpdate(ctx context.Context, id int64) (PendingTransfer, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetScheduledTransferForUpdate(ctx context.Context, id int64) (ScheduledTransfer, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferFee(ctx context.Context, currency string) (TransferFee, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimit(ctx context.Context, id int64) (TransferLimit, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetWebhook(ctx context.Context, id int64) (Webhook, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookDeliveryForUpdate(ctx context.Context, id int64) (WebhookDelivery, error)
+	IncrementAccountVersion(ctx context.Context, id int64) (Account, error)
+	ListAccountAdjustments(ctx context.Context, arg ListAccountAdjustmentsParams) ([]Adjustment, error)
+	ListAccountBalanceMismatches(ctx context.Context) ([]ListAccountBalanceMismatchesRow, error)
+	ListAccountBalanceSnapshots(ctx context.Context, arg ListAccountBalanceSnapshotsParams) ([]BalanceSnapshot, error)
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error)
+	ListAccountEntriesAfter(ctx context.Context, arg ListAccountEntriesAfterParams) ([]Entry, error)
+	ListAccountEntriesSince(ctx context.Context, arg ListAccountEntriesSinceParams) ([]Entry, error)
+	ListAccountTransferLimits(ctx context.Context, arg ListAccountTransferLimitsParams) ([]TransferLimit, error)
+	ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]Transfer, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsAfter(ctx context.Context, arg ListAccountsAfterParams) ([]Account, error)
+	ListAdjustmentEntryMismatches(ctx context.Context) ([]ListAdjustmentEntryMismatchesRow, error)
+	ListAuditLogs(ctx context.Context, arg ListAuditLogsParams) ([]AuditLog, error)
+	ListDueAccountInterest(ctx context.Context, accruedThrough time.Time) ([]int64, error)
+	ListDueBalanceSnapshots(ctx context.Context, snapshotAt time.Time) ([]int64, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
+	ListInterestAccruals(ctx context.Context, arg ListInterestAccrualsParams) ([]InterestAccrual, error)
+	ListInterestProducts(ctx context.Context) ([]InterestProduct, error)
+	ListOrphanEntries(ctx context.Context) ([]Entry, error)
+	ListOwnerOutgoingTotals(ctx context.Context, arg ListOwnerOutgoingTotalsParams) ([]ListOwnerOutgoingTotalsRow, error)
+	ListOwnerTransfers(ctx context.Context, arg ListOwnerTransfersParams) ([]Transfer, error)
+	ListPendingTransfers(ctx context.Context, arg ListPendingTransfersParams) ([]PendingTransfer, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListTransferEntryMismatches(ctx context.Context) ([]ListTransferEntryMismatchesRow, error)
+	ListTransferFees(ctx context.Context) ([]TransferFee, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
-	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	ListWebhookAttempts(ctx context.Context, deliveryID int64) ([]WebhookAttempt, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhooks(ctx context.Context, arg ListWebhooksParams) ([]Webhook, error)
+	MarkOutboxEventSent(ctx context.Context, id int64) error
+	ReleaseHold(ctx context.Context, id int64) (Hold, error)
+	ReleaseOutboxEvents(ctx context.Context, ids []int64) error
+	SetAccountInterest(ctx context.Context, arg SetAccountInterestParams) (AccountInterest, error)
+	SetTransferFee(ctx context.Context, arg SetTransferFeeParams) (TransferFee, error)
+	SetTransferLimit(ctx context.Context, arg SetTransferLimitParams) (TransferLimit, error)
+	UpdateAccountInterest(ctx context.Context, arg UpdateAccountInterestParams) (AccountInterest, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
 	UpdateEntry(ctx context.Context, arg UpdateEntryParams) (Entry, error)
+	UpdateIdempotencyKeyResponse(ctx context.Context, arg UpdateIdempotencyKeyResponseParams) (IdempotencyKey, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateScheduledTransferNextRun(ctx context.Context, arg UpdateScheduledTransferNextRunParams) (ScheduledTransfer, error)
+	UpdateScheduledTransferStatus(ctx context.Context, arg UpdateScheduledTransferStatusParams) (ScheduledTransfer, error)
 	UpdateTransfer(ctx context.Context, arg UpdateTransferParams) (Transfer, error)
+	UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (WebhookDelivery, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,58 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// ReconciliationReport contain every discrepancy found in the ledger
+// Transfer mismatches are transfers without exactly one debit of amount plus fee on the sender, one credit of to_amount on the receiver
+// and, when a fee is charged, one credit of the fee on the fee account
+// Adjustment mismatches are adjustments without exactly one entry of the amount on the account and the opposite one on the suspense account
+type ReconciliationReport struct {
+	CheckedAt            time.Time                          `json:"checked_at"`
+	TransferMismatches   []ListTransferEntryMismatchesRow   `json:"transfer_mismatches"`
+	AdjustmentMismatches []ListAdjustmentEntryMismatchesRow `json:"adjustment_mismatches"`
+	BalanceMismatches    []ListAccountBalanceMismatchesRow  `json:"balance_mismatches"`
+	OrphanEntries        []Entry                            `json:"orphan_entries"`
+}
+
+// HasDiscrepancies returns true if any check of the report failed
+func (report ReconciliationReport) HasDiscrepancies() bool {
+	return len(report.TransferMismatches) > 0 ||
+		len(report.AdjustmentMismatches) > 0 ||
+		len(report.BalanceMismatches) > 0 ||
+		len(report.OrphanEntries) > 0
+}
+
+// ReconcileTx checks the double-entry invariants of transfers, adjustments, entries and accounts
+// All checks read the same snapshot, so transfers committed meanwhile don't show up as discrepancies
+func (store *SQLStore) ReconcileTx(ctx context.Context) (ReconciliationReport, error) {
+	var report ReconciliationReport
+
+	err := store.execTx(ctx, snapshotTxOptions, func(q *Queries) error {
+		var err error
+
+		report.CheckedAt = time.Now()
+
+		report.TransferMismatches, err = q.ListTransferEntryMismatches(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.AdjustmentMismatches, err = q.ListAdjustmentEntryMismatches(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.BalanceMismatches, err = q.ListAccountBalanceMismatches(ctx)
+		if err != nil {
+			return err
+		}
+
+		report.OrphanEntries, err = q.ListOrphanEntries(ctx)
+		return err
+	})
+
+	return report, err
+}
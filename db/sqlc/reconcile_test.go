@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"testing"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcileTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, util.USD, 100)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	report, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+	require.NotZero(t, report.CheckedAt)
+
+	for _, mismatch := range report.TransferMismatches {
+		require.NotEqual(t, result.Transfer.ID, mismatch.ID)
+	}
+
+	//accounts were created with balance but without entries
+	requireBalanceMismatch(t, report, fromAccount.ID, fromAccount.Balance-10, -10)
+
+	//tampered entry breaks the transfer and the balance of its account
+	_, err = testQueries.UpdateEntry(context.Background(), UpdateEntryParams{
+		ID:     result.ToEntry.ID,
+		Amount: 11,
+	})
+	require.NoError(t, err)
+
+	//entry linked to a transfer which doesn't exist
+	orphan, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:  toAccount.ID,
+		Amount:     1,
+		TransferID: sql.NullInt64{Int64: math.MaxInt64, Valid: true},
+	})
+	require.NoError(t, err)
+
+	report, err = store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+	require.True(t, report.HasDiscrepancies())
+
+	found := false
+	for _, mismatch := range report.TransferMismatches {
+		if mismatch.ID == result.Transfer.ID {
+			found = true
+			require.Equal(t, int64(2), mismatch.EntryCount)
+			require.Equal(t, int64(1), mismatch.EntrySum)
+		}
+	}
+	require.True(t, found)
+
+	requireBalanceMismatch(t, report, toAccount.ID, toAccount.Balance+10, 12)
+
+	require.Contains(t, report.OrphanEntries, orphan)
+}
+
+func requireBalanceMismatch(t *testing.T, report ReconciliationReport, accountID int64, balance int64, entryBalance int64) {
+	require.Contains(t, report.BalanceMismatches, ListAccountBalanceMismatchesRow{
+		ID:           accountID,
+		Balance:      balance,
+		EntryBalance: entryBalance,
+	})
+}
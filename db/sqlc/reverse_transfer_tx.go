@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// ReverseTransferTxParam contain information for reversal of a transfer
+// Amount is in currency of the original sender, zero Amount reverses everything which is not reversed yet
+// If IdempotencyKey is set the result is saved and returned again for every replay of the same request like in TransferTx
+type ReverseTransferTxParam struct {
+	TransferID     int64     `json:"transfer_id"`
+	Amount         int64     `json:"amount"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	KeyUsername    string    `json:"key_username"`
+	RequestHash    string    `json:"request_hash"`
+	KeyExpiredAt   time.Time `json:"key_expired_at"`
+}
+
+// ReverseTransferTxResult contain the original transfer and result of the reverse transfer
+type ReverseTransferTxResult struct {
+	OriginalTransfer Transfer `json:"original_transfer"`
+	TransferTxResult
+}
+
+// ReverseTransferTx returns money of a transfer from the receiver back to the sender
+// It books a reverse transfer linked to the original one with two compensating entries and adds the amount to reversed_amount of the original transfer
+// The receiver is debited with the share of to_amount matching the reversed share of amount, so the original exchange rate is kept
+// and the reversals of a whole transfer always add up to its to_amount
+// The fee of the original transfer is not refunded
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParam) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		//the function may be run several times so start from empty result
+		result = ReverseTransferTxResult{}
+
+		//reserve idempotency key or replay the saved result
+
+		if arg.IdempotencyKey != "" {
+			replayed, err := reserveIdempotencyKey(ctx, q, arg.KeyUsername, arg.IdempotencyKey, arg.RequestHash, arg.KeyExpiredAt, &result)
+			if err != nil || replayed {
+				return err
+			}
+		}
+
+		//lock the original transfer so concurrent reversals can't exceed its amount
+
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if original.ReversalOf.Valid {
+			return ErrTransferNotReversible
+		}
+
+		remaining := original.Amount - original.ReversedAmount
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+
+		if amount <= 0 || amount > remaining {
+			return ErrReversalExceedsAmount
+		}
+
+		//debit the receiver with the difference of cumulative shares to avoid rounding drift between partial reversals
+
+		reversedBefore, err := util.ProportionalAmount(original.ToAmount, original.ReversedAmount, original.Amount)
+		if err != nil {
+			return err
+		}
+
+		reversedAfter, err := util.ProportionalAmount(original.ToAmount, original.ReversedAmount+amount, original.Amount)
+		if err != nil {
+			return err
+		}
+
+		debit := reversedAfter - reversedBefore
+		if debit <= 0 {
+			return ErrAmountTooSmall
+		}
+
+		//lock accounts and check available balance of the original receiver, money reserved by holds can't be reversed
+
+		fromAccount, toAccount, err := getAccountsForUpdate(ctx, q, original.ToAccountID, original.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		//frozen accounts still take part, freezing is how fraud is stopped before it is reversed
+		if fromAccount.Status == util.ClosedAccount || toAccount.Status == util.ClosedAccount {
+			return ErrAccountNotActive
+		}
+
+		available, err := availableBalance(ctx, q, fromAccount)
+		if err != nil {
+			return err
+		}
+
+		if available < debit {
+			return ErrInsufficientFunds
+		}
+
+		rate, rounding := "1", "0"
+		if fromAccount.Currency != toAccount.Currency {
+			rate, err = util.InvertExchangeRate(original.ExchangeRate)
+			if err != nil {
+				return err
+			}
+
+			rounding, err = util.ConversionRounding(debit, rate, amount)
+			if err != nil {
+				return err
+			}
+		}
+
+		//create reverse transfer and compensating entries
+
+		result.Transfer, err = q.CreateReverseTransfer(ctx, CreateReverseTransferParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        debit,
+			ToAmount:      amount,
+			ExchangeRate:  rate,
+			Rounding:      rounding,
+			ReversalOf:    sql.NullInt64{Int64: original.ID, Valid: true},
+		})
+
+		if err != nil {
+			return err
+		}
+
+		err = recordTransferCreated(ctx, q, result.Transfer)
+		if err != nil {
+			return err
+		}
+
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  original.ToAccountID,
+			Amount:     -debit,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  original.FromAccountID,
+			Amount:     amount,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+		})
+
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, result.ToAccount, err = moveMoney(ctx, q, original.ToAccountID, debit, original.FromAccountID, amount)
+		if err != nil {
+			return err
+		}
+
+		//mark the original transfer as reversed
+
+		result.OriginalTransfer, err = q.AddTransferReversedAmount(ctx, AddTransferReversedAmountParams{
+			ID:     original.ID,
+			Amount: amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		//save result for replays
+
+		if arg.IdempotencyKey != "" {
+			return saveIdempotentResponse(ctx, q, arg.KeyUsername, arg.IdempotencyKey, result)
+		}
+
+		return nil
+	})
+
+	return result, err
+}
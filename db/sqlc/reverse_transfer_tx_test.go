@@ -0,0 +1,203 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReverseTransferTxPartial(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(100)
+
+	fromAccount := createFundedAccount(t, util.USD, amount)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	transferTx, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	//reverse 30 first, then everything left
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: transferTx.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(30), result.OriginalTransfer.ReversedAmount)
+	require.Equal(t, transferTx.Transfer.ID, result.Transfer.ReversalOf.Int64)
+	require.Equal(t, toAccount.ID, result.Transfer.FromAccountID)
+	require.Equal(t, fromAccount.ID, result.Transfer.ToAccountID)
+	require.Equal(t, int64(30), result.Transfer.Amount)
+	require.Equal(t, int64(-30), result.FromEntry.Amount)
+	require.Equal(t, int64(30), result.ToEntry.Amount)
+
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: transferTx.Transfer.ID,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, amount, result.OriginalTransfer.ReversedAmount)
+	require.Equal(t, int64(70), result.Transfer.Amount)
+
+	//balances are back where they were before the transfer
+	require.Equal(t, transferTx.FromAccount.Balance+amount, result.ToAccount.Balance)
+	require.Equal(t, transferTx.ToAccount.Balance-amount, result.FromAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: transferTx.Transfer.ID,
+		Amount:     1,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsAmount)
+
+	//reverse transfer itself can't be reversed
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: result.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferNotReversible)
+}
+
+func TestReverseTransferTxCrossCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		FromCurrency: util.USD,
+		ToCurrency:   util.EUR,
+		Rate:         "0.9215",
+	})
+	require.NoError(t, err)
+
+	amount := int64(1000)
+
+	fromAccount := createFundedAccount(t, util.USD, amount)
+	toAccount := createRandomAccountWithCurrency(t, util.EUR)
+
+	transferTx, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	//the rate changes after the transfer, reversals keep the original one
+	_, err = testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		FromCurrency: util.USD,
+		ToCurrency:   util.EUR,
+		Rate:         "0.5",
+	})
+	require.NoError(t, err)
+
+	var debited int64
+	for _, part := range []int64{333, 333, 334} {
+		result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+			TransferID: transferTx.Transfer.ID,
+			Amount:     part,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, part, result.Transfer.ToAmount)
+		require.Equal(t, "1.0851871948", result.Transfer.ExchangeRate)
+		debited += result.Transfer.Amount
+	}
+
+	//partial reversals add up to the credited amount
+	require.Equal(t, transferTx.Transfer.ToAmount, debited)
+
+	updatedFromAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedFromAccount.Balance)
+
+	updatedToAccount, err := testQueries.GetAccount(context.Background(), toAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, toAccount.Balance, updatedToAccount.Balance)
+}
+
+func TestReverseTransferTxHeldFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(100)
+
+	fromAccount := createFundedAccount(t, util.USD, amount)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	transferTx, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	//the receiver reserves everything but less than the transferred amount
+	_, err = store.AuthorizeHoldTx(context.Background(), AuthorizeHoldTxParam{
+		AccountID: toAccount.ID,
+		Amount:    transferTx.ToAccount.Balance - amount + 1,
+		ExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: transferTx.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	//the part which is not held can still be reversed
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParam{
+		TransferID: transferTx.Transfer.ID,
+		Amount:     amount - 1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, amount-1, result.OriginalTransfer.ReversedAmount)
+}
+
+func TestReverseTransferTxIdempotency(t *testing.T) {
+	store := NewStore(testDB)
+
+	amount := int64(100)
+
+	fromAccount := createFundedAccount(t, util.USD, amount)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	transferTx, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	arg := ReverseTransferTxParam{
+		TransferID:     transferTx.Transfer.ID,
+		Amount:         30,
+		IdempotencyKey: util.RandomString(32),
+		KeyUsername:    util.RandomOwner(),
+		RequestHash:    util.RandomString(64),
+		KeyExpiredAt:   time.Now().Add(time.Minute),
+	}
+
+	result1, err := store.ReverseTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	//replay returns the saved result without reversing again
+	result2, err := store.ReverseTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.OriginalTransfer.ReversedAmount, result2.OriginalTransfer.ReversedAmount)
+
+	original, err := testQueries.GetTransfer(context.Background(), transferTx.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), original.ReversedAmount)
+
+	//same key with a different request is rejected
+	arg.Amount = 40
+	arg.RequestHash = util.RandomString(64)
+
+	_, err = store.ReverseTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, nextRunAt time.Time) ScheduledTransfer {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	arg := CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        util.RandomMoney(),
+		Recurrence:    util.MonthlyRecurrence,
+		NextRunAt:     nextRunAt,
+		MaxRetries:    3,
+		AnchorDay:     util.AnchorDay(nextRunAt),
+	}
+
+	scheduledTransfer, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, scheduledTransfer)
+
+	require.Equal(t, arg.Owner, scheduledTransfer.Owner)
+	require.Equal(t, arg.FromAccountID, scheduledTransfer.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduledTransfer.ToAccountID)
+	require.Equal(t, arg.Amount, scheduledTransfer.Amount)
+	require.Equal(t, arg.Recurrence, scheduledTransfer.Recurrence)
+	require.WithinDuration(t, arg.NextRunAt, scheduledTransfer.NextRunAt, time.Second)
+	require.Equal(t, arg.MaxRetries, scheduledTransfer.MaxRetries)
+	require.Equal(t, arg.AnchorDay, scheduledTransfer.AnchorDay)
+	require.Equal(t, util.ActiveSchedule, scheduledTransfer.Status)
+	require.Zero(t, scheduledTransfer.RetryCount)
+
+	require.NotZero(t, scheduledTransfer.ID)
+	require.NotZero(t, scheduledTransfer.CreatedAt)
+
+	return scheduledTransfer
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+}
+
+func TestGetScheduledTransfer(t *testing.T) {
+	scheduledTransfer1 := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	scheduledTransfer2, err := testQueries.GetScheduledTransfer(context.Background(), scheduledTransfer1.ID)
+
+	require.NoError(t, err)
+	require.Equal(t, scheduledTransfer1, scheduledTransfer2)
+}
+
+// claimScheduledTransfer claims due scheduled transfers until the one with id is found
+func claimScheduledTransfer(t *testing.T, id int64, lockedUntil time.Time) (ScheduledTransfer, bool) {
+	for {
+		scheduledTransfer, err := testQueries.ClaimDueScheduledTransfer(context.Background(), lockedUntil)
+		if err == sql.ErrNoRows {
+			return ScheduledTransfer{}, false
+		}
+
+		require.NoError(t, err)
+
+		if scheduledTransfer.ID == id {
+			return scheduledTransfer, true
+		}
+	}
+}
+
+func TestClaimDueScheduledTransfer(t *testing.T) {
+	notDue := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+	due := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+
+	lockedUntil := time.Now().Add(time.Hour)
+
+	claimed, found := claimScheduledTransfer(t, due.ID, lockedUntil)
+	require.True(t, found)
+	require.WithinDuration(t, lockedUntil, claimed.LockedUntil, time.Second)
+
+	//claimed schedule is skipped until the lock expires
+	_, found = claimScheduledTransfer(t, due.ID, lockedUntil)
+	require.False(t, found)
+
+	_, found = claimScheduledTransfer(t, notDue.ID, lockedUntil)
+	require.False(t, found)
+}
+
+func TestRecordScheduledTransferRunTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+	nextRunAt, _ := util.NextOccurrence(scheduledTransfer.Recurrence, scheduledTransfer.NextRunAt, int(scheduledTransfer.AnchorDay))
+
+	result, err := store.RecordScheduledTransferRunTx(context.Background(), RecordScheduledTransferRunTxParam{
+		ScheduledTransfer: scheduledTransfer,
+		RunStatus:         util.FailedRun,
+		Error:             ErrInsufficientFunds.Error(),
+		NextRunAt:         nextRunAt,
+		Status:            util.ActiveSchedule,
+	})
+
+	require.NoError(t, err)
+
+	require.Equal(t, scheduledTransfer.ID, result.Run.ScheduledTransferID)
+	require.Equal(t, util.FailedRun, result.Run.Status)
+	require.Equal(t, ErrInsufficientFunds.Error(), result.Run.Error)
+	require.False(t, result.Run.TransferID.Valid)
+
+	require.WithinDuration(t, nextRunAt, result.ScheduledTransfer.NextRunAt, time.Second)
+	require.Equal(t, util.ActiveSchedule, result.ScheduledTransfer.Status)
+
+	runs, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduledTransfer.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []ScheduledTransferRun{result.Run}, runs)
+}
+
+func TestRecordScheduledTransferRunTxCancelled(t *testing.T) {
+	store := NewStore(testDB)
+
+	scheduledTransfer := createRandomScheduledTransfer(t, time.Now().Add(-time.Minute))
+
+	//the owner cancels the schedule while it is running
+	cancelled, err := testQueries.UpdateScheduledTransferStatus(context.Background(), UpdateScheduledTransferStatusParams{
+		ID:     scheduledTransfer.ID,
+		Status: util.CancelledSchedule,
+	})
+	require.NoError(t, err)
+
+	result, err := store.RecordScheduledTransferRunTx(context.Background(), RecordScheduledTransferRunTxParam{
+		ScheduledTransfer: scheduledTransfer,
+		RunStatus:         util.SucceededRun,
+		NextRunAt:         scheduledTransfer.NextRunAt.Add(time.Hour),
+		Status:            util.ActiveSchedule,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, util.CancelledSchedule, result.ScheduledTransfer.Status)
+	require.WithinDuration(t, cancelled.NextRunAt, result.ScheduledTransfer.NextRunAt, time.Second)
+	require.Equal(t, util.SucceededRun, result.Run.Status)
+}
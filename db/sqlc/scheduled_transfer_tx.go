@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// RecordScheduledTransferRunTxParam contain outcome of a scheduled transfer run and the next state of the schedule
+type RecordScheduledTransferRunTxParam struct {
+	ScheduledTransfer ScheduledTransfer `json:"scheduled_transfer"`
+	TransferID        sql.NullInt64     `json:"transfer_id"`
+	RunStatus         string            `json:"run_status"`
+	Error             string            `json:"error"`
+	NextRunAt         time.Time         `json:"next_run_at"`
+	RetryCount        int32             `json:"retry_count"`
+	LockedUntil       time.Time         `json:"locked_until"`
+	Status            string            `json:"status"`
+}
+
+// RecordScheduledTransferRunTxResult contain information about result of transaction
+type RecordScheduledTransferRunTxResult struct {
+	ScheduledTransfer ScheduledTransfer    `json:"scheduled_transfer"`
+	Run               ScheduledTransferRun `json:"run"`
+}
+
+// RecordScheduledTransferRunTx saves the run of a claimed scheduled transfer and moves the schedule to its next state
+// If the owner changed or cancelled the schedule while it was running, the changes are kept and only the lock is released
+func (store *SQLStore) RecordScheduledTransferRunTx(ctx context.Context, arg RecordScheduledTransferRunTxParam) (RecordScheduledTransferRunTxResult, error) {
+	var result RecordScheduledTransferRunTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		schedule, err := q.GetScheduledTransferForUpdate(ctx, arg.ScheduledTransfer.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Run, err = q.CreateScheduledTransferRun(ctx, CreateScheduledTransferRunParams{
+			ScheduledTransferID: schedule.ID,
+			TransferID:          arg.TransferID,
+			Status:              arg.RunStatus,
+			Error:               arg.Error,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		next := UpdateScheduledTransferNextRunParams{
+			ID:          schedule.ID,
+			NextRunAt:   arg.NextRunAt,
+			RetryCount:  arg.RetryCount,
+			LockedUntil: arg.LockedUntil,
+			Status:      arg.Status,
+		}
+
+		if schedule.Status != util.ActiveSchedule || !schedule.NextRunAt.Equal(arg.ScheduledTransfer.NextRunAt) {
+			next = UpdateScheduledTransferNextRunParams{
+				ID:         schedule.ID,
+				NextRunAt:  schedule.NextRunAt,
+				RetryCount: schedule.RetryCount,
+				Status:     schedule.Status,
+			}
+		}
+
+		result.ScheduledTransfer, err = q.UpdateScheduledTransferNextRun(ctx, next)
+		return err
+	})
+
+	return result, err
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// AccountStatementTxParam contain the account and the [From, To) period of a statement page
+// If AfterID is set the page starts right after that entry and Offset is ignored
+type AccountStatementTxParam struct {
+	AccountID      int64     `json:"account_id"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	Limit          int32     `json:"limit"`
+	Offset         int32     `json:"offset"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+}
+
+// StatementLine is an entry with the balance of the account right after it
+type StatementLine struct {
+	Entry
+	Balance int64 `json:"balance"`
+}
+
+// AccountStatementTxResult contain balances at both ends of the period and one page of its entries
+type AccountStatementTxResult struct {
+	AccountID      int64           `json:"account_id"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Entries        []StatementLine `json:"entries"`
+}
+
+// AccountStatementTx builds the statement of an account from its entries
+// Balances are sums of entries, so opening balance, lines and closing balance always add up
+func (store *SQLStore) AccountStatementTx(ctx context.Context, arg AccountStatementTxParam) (AccountStatementTxResult, error) {
+	var result AccountStatementTxResult
+
+	err := store.execTx(ctx, snapshotTxOptions, func(q *Queries) error {
+		var err error
+
+		result = AccountStatementTxResult{
+			AccountID: arg.AccountID,
+			From:      arg.From,
+			To:        arg.To,
+		}
+
+		result.OpeningBalance, err = q.GetAccountEntriesSum(ctx, GetAccountEntriesSumParams{
+			AccountID: arg.AccountID,
+			CreatedAt: arg.From,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ClosingBalance, err = q.GetAccountEntriesSum(ctx, GetAccountEntriesSumParams{
+			AccountID: arg.AccountID,
+			CreatedAt: arg.To,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterID != 0 {
+			result.Entries, err = accountEntriesAfter(ctx, q, arg)
+			return err
+		}
+
+		rows, err := q.ListAccountEntries(ctx, ListAccountEntriesParams{
+			AccountID:   arg.AccountID,
+			CreatedAt:   arg.From,
+			CreatedAt_2: arg.To,
+			Limit:       arg.Limit,
+			Offset:      arg.Offset,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Entries = make([]StatementLine, len(rows))
+		for i, row := range rows {
+			result.Entries[i] = StatementLine{
+				Entry: Entry{
+					ID:         row.ID,
+					AccountID:  row.AccountID,
+					Amount:     row.Amount,
+					CreatedAt:  row.CreatedAt,
+					TransferID: row.TransferID,
+				},
+				//running total covers every entry of the period before this one, not only this page
+				Balance: result.OpeningBalance + row.RunningTotal,
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// accountEntriesAfter reads the page of a statement following an entry
+// Running balance starts from the sum of all entries up to that entry, so it doesn't depend on the pages before
+func accountEntriesAfter(ctx context.Context, q *Queries, arg AccountStatementTxParam) ([]StatementLine, error) {
+	balance, err := q.GetAccountEntriesSumThrough(ctx, GetAccountEntriesSumThroughParams{
+		AccountID:      arg.AccountID,
+		AfterCreatedAt: arg.AfterCreatedAt,
+		AfterID:        arg.AfterID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := q.ListAccountEntriesAfter(ctx, ListAccountEntriesAfterParams{
+		AccountID:      arg.AccountID,
+		AfterCreatedAt: arg.AfterCreatedAt,
+		AfterID:        arg.AfterID,
+		CreatedTo:      arg.To,
+		Limit:          arg.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]StatementLine, len(entries))
+	for i, entry := range entries {
+		balance += entry.Amount
+		lines[i] = StatementLine{Entry: entry, Balance: balance}
+	}
+
+	return lines, nil
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, util.USD, 100)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	from := time.Now().Add(-time.Minute)
+
+	n := 3
+	amount := int64(10)
+
+	for i := 0; i < n; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParam{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+	}
+
+	arg := AccountStatementTxParam{
+		AccountID: fromAccount.ID,
+		From:      from,
+		To:        time.Now().Add(time.Minute),
+		Limit:     5,
+		Offset:    0,
+	}
+
+	statement, err := store.AccountStatementTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, fromAccount.ID, statement.AccountID)
+	require.Zero(t, statement.OpeningBalance)
+	require.Equal(t, -int64(n)*amount, statement.ClosingBalance)
+	require.Len(t, statement.Entries, n)
+
+	for i, line := range statement.Entries {
+		require.Equal(t, fromAccount.ID, line.AccountID)
+		require.Equal(t, -amount, line.Amount)
+		require.Equal(t, -int64(i+1)*amount, line.Balance)
+	}
+
+	//running balance of a later page continues from the previous ones
+	arg.Limit = 2
+	arg.Offset = 1
+
+	page, err := store.AccountStatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 2)
+	require.Equal(t, statement.Entries[1:], page.Entries)
+	require.Equal(t, statement.ClosingBalance, page.ClosingBalance)
+
+	//keyset page continues after the given entry with the same running balance
+	arg.Offset = 0
+	arg.AfterCreatedAt = statement.Entries[0].CreatedAt
+	arg.AfterID = statement.Entries[0].ID
+
+	keysetPage, err := store.AccountStatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, statement.Entries[1:], keysetPage.Entries)
+
+	arg.AfterCreatedAt = time.Time{}
+	arg.AfterID = 0
+
+	//period before the transfers is empty
+	arg.To = from
+	arg.From = from.Add(-time.Minute)
+
+	empty, err := store.AccountStatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, empty.Entries)
+	require.Zero(t, empty.OpeningBalance)
+	require.Zero(t, empty.ClosingBalance)
+}
@@ -3,12 +3,69 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/lib/pq"
+)
+
+// Different types of error returned by transactions
+var (
+	ErrIdempotencyKeyReused  = errors.New("idempotency key has already been used with a different request")
+	ErrInsufficientFunds     = errors.New("insufficient funds")
+	ErrExchangeRateNotFound  = errors.New("exchange rate is not found")
+	ErrAmountTooSmall        = errors.New("amount is too small to be converted")
+	ErrTransferNotReversible = errors.New("reverse transfer cannot be reversed")
+	ErrReversalExceedsAmount = errors.New("reversal exceeds the amount which is not reversed yet")
+	ErrHoldNotActive         = errors.New("hold is not active")
+	ErrHoldExpired           = errors.New("hold is expired")
+	ErrCaptureExceedsHold    = errors.New("capture exceeds the held amount")
+	ErrAccountNotActive      = errors.New("account is not active")
+	ErrAccountTransition     = errors.New("account status transition is not allowed")
+	ErrAccountHasBalance     = errors.New("account has balance")
+	ErrAccountHasHolds       = errors.New("account has active holds")
+	ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+	ErrTransferNotPending    = errors.New("transfer is not pending approval")
+	ErrSelfApproval          = errors.New("transfer cannot be approved by its creator")
+	ErrSnapshotMismatch      = errors.New("balance snapshot doesn't match the sum of entries")
+	ErrSessionsNotVisible    = errors.New("transactions of other database roles are not visible, grant pg_read_all_stats to the database user")
+	ErrVersionMismatch       = errors.New("account has been changed since it was read")
+	ErrSuspenseAdjustment    = errors.New("suspense account cannot be adjusted")
 )
 
+// balanceConstraint is a name of DB constraint which prevents negative account balance
+const balanceConstraint = "balance_non_negative"
+
+// Store executes queries and transactions
+// Every amount and balance it takes or returns is an int64 in minor units of the account currency,
+// the API converts decimal amounts with util.Money before they reach the store
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParam) (TransferTxResult, error)
+	AuthorizeHoldTx(ctx context.Context, arg AuthorizeHoldTxParam) (Hold, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParam) (CaptureHoldTxResult, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParam) (BatchTransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParam) (ReverseTransferTxResult, error)
+	RecordScheduledTransferRunTx(ctx context.Context, arg RecordScheduledTransferRunTxParam) (RecordScheduledTransferRunTxResult, error)
+	ReconcileTx(ctx context.Context) (ReconciliationReport, error)
+	AccountStatementTx(ctx context.Context, arg AccountStatementTxParam) (AccountStatementTxResult, error)
+	CloseAccountTx(ctx context.Context, arg CloseAccountTxParam) (CloseAccountTxResult, error)
+	TransferLimitsTx(ctx context.Context, accountID int64) (TransferLimitsTxResult, error)
+	CreatePendingTransferTx(ctx context.Context, arg CreatePendingTransferTxParam) (PendingTransfer, error)
+	ApproveTransferTx(ctx context.Context, arg ApproveTransferTxParam) (ApproveTransferTxResult, error)
+	AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParam) (AccrueInterestTxResult, error)
+	SetAccountInterestTx(ctx context.Context, arg SetAccountInterestTxParam) (SetAccountInterestTxResult, error)
+	SnapshotBalancesTx(ctx context.Context, arg SnapshotBalancesTxParam) (SnapshotBalancesTxResult, error)
+	BalanceAsOfTx(ctx context.Context, arg BalanceAsOfTxParam) (BalanceAsOfTxResult, error)
+	AdjustBalanceTx(ctx context.Context, arg AdjustBalanceTxParam) (AdjustBalanceTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountParams) (Account, error)
+	UpdateAccountStatusTx(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	RecordWebhookAttemptTx(ctx context.Context, arg RecordWebhookAttemptTxParam) (RecordWebhookAttemptTxResult, error)
 }
 
 // Store provides all functions to execute SQL queries and transactions
@@ -25,9 +82,80 @@ func NewStore(db *sql.DB) Store {
 	}
 }
 
+// txOptions describes how execTx runs a transaction
+type txOptions struct {
+	isolation  sql.IsolationLevel
+	readOnly   bool
+	maxRetries int
+	backoff    time.Duration
+}
+
+// maxTxBackoff limits the delay between two attempts of a transaction
+const maxTxBackoff = time.Second
+
+// Postgres error codes after which the whole transaction can be safely run again
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+// serializableTxOptions runs a transaction with SERIALIZABLE isolation and retries it on conflicts
+var serializableTxOptions = txOptions{
+	isolation:  sql.LevelSerializable,
+	maxRetries: 10,
+	backoff:    10 * time.Millisecond,
+}
+
+// snapshotTxOptions runs a read only transaction which sees one consistent snapshot of the database
+var snapshotTxOptions = txOptions{
+	isolation: sql.LevelRepeatableRead,
+	readOnly:  true,
+}
+
+// retryDelay returns exponential backoff with random jitter for the attempt
+func (opts txOptions) retryDelay(attempt int) time.Duration {
+	delay := opts.backoff
+	for i := 0; i < attempt && delay < maxTxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > maxTxBackoff {
+		delay = maxTxBackoff
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// isRetryableTxError returns true if the transaction failed because of a concurrent transaction
+func isRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == serializationFailureCode || pqErr.Code == deadlockDetectedCode
+}
+
 // execTx executes a function within transaction
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+// The function is run again while the transaction fails with serialization failure or deadlock and retries are left
+func (store *SQLStore) execTx(ctx context.Context, opts txOptions, fn func(*Queries) error) error {
+	for attempt := 0; ; attempt++ {
+		err := store.runTx(ctx, opts, fn)
+		if err == nil || attempt >= opts.maxRetries || !isRetryableTxError(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(opts.retryDelay(attempt)):
+		}
+	}
+}
+
+// runTx executes a function within single transaction
+func (store *SQLStore) runTx(ctx context.Context, opts txOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: opts.isolation, ReadOnly: opts.readOnly})
 	if err != nil {
 		return err
 	}
@@ -36,7 +164,7 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx error: %v, rb error: %v", err, rbErr)
+			return fmt.Errorf("tx error: %w, rb error: %v", err, rbErr)
 		}
 		return err
 	}
@@ -44,10 +172,17 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 }
 
 // TransferTxParam contain information for transaction between two accounts
+// If IdempotencyKey is set the result is saved and returned again for every replay of the same request
+// Keys are scoped to KeyUsername, so the same key sent by different users never collides
+// Amount is in minor units of the sender currency
 type TransferTxParam struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID  int64     `json:"from_account_id"`
+	ToAccountID    int64     `json:"to_account_id"`
+	Amount         int64     `json:"amount"`
+	IdempotencyKey string    `json:"idempotency_key"`
+	KeyUsername    string    `json:"key_username"`
+	RequestHash    string    `json:"request_hash"`
+	KeyExpiredAt   time.Time `json:"key_expired_at"`
 }
 
 // TransferTxResult contain information about result of transaction
@@ -57,99 +192,423 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	FeeEntry    *Entry   `json:"fee_entry,omitempty"`
 }
 
 var txKey = struct{}{}
 
 // TransferTx performs a money trasfer between two account
-// First of all TransferTx create transfer record then two entries record for account and finally change balance of each account
+// First of all TransferTx locks both accounts and checks the available balance of the sender, it returns ErrInsufficientFunds if it is too low
+// The fee from transfer_fees of the sender currency is debited on top of the amount and credited to the fee income account of the currency
+// If accounts have different currencies the amount credited to the receiver is converted with the rate from exchange_rates
+// Then TransferTx create transfer record then entries record for every account and finally change balance of each account
+// Outgoing transfers of the sender including the new one are checked against its limits, ErrTransferLimitExceeded rolls everything back
+// The transaction runs with SERIALIZABLE isolation and is retried when it conflicts with a concurrent one
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParam) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
 		var err error
 
-		//create transfer
+		//the function may be run several times so start from empty result
+		result = TransferTxResult{}
 
-		txName := ctx.Value(txKey)
-		fmt.Println(txName, "create transfer")
+		//reserve idempotency key or replay the saved result
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+		if arg.IdempotencyKey != "" {
+			replayed, err := reserveIdempotencyKey(ctx, q, arg.KeyUsername, arg.IdempotencyKey, arg.RequestHash, arg.KeyExpiredAt, &result)
+			if err != nil || replayed {
+				return err
+			}
+		}
 
+		result, err = executeTransfer(ctx, q, arg.FromAccountID, arg.ToAccountID, arg.Amount, 0)
 		if err != nil {
 			return err
 		}
 
-		//create two entry for each account
+		//save result for replays
 
-		fmt.Println(txName, "create entry 1")
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+		if arg.IdempotencyKey != "" {
+			return saveIdempotentResponse(ctx, q, arg.KeyUsername, arg.IdempotencyKey, result)
+		}
 
-		if err != nil {
-			return err
+		return nil
+	})
+
+	return result, err
+}
+
+// executeTransfer moves money between two accounts on request of the sender, charges the fee and checks limits of the sender
+// reserved is the amount of active holds of the sender spent by the transfer, it is zero unless a hold is captured
+func executeTransfer(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64, amount int64, reserved int64) (TransferTxResult, error) {
+	fee, err := transferFee(ctx, q, fromAccountID, amount)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	result, err := transferMoney(ctx, q, fromAccountID, toAccountID, amount, reserved, fee)
+	if err != nil {
+		return result, err
+	}
+
+	err = checkTransferLimits(ctx, q, result.FromAccount, amount)
+
+	return result, err
+}
+
+// feeCharge is the fee of a transfer and the account it is credited to
+type feeCharge struct {
+	amount    int64
+	accountID int64
+}
+
+// transferFee computes the fee of a transfer from the fee schedule of the sender currency or the default one
+// No fee is charged when neither is set
+func transferFee(ctx context.Context, q *Queries, fromAccountID int64, amount int64) (feeCharge, error) {
+	//currency of an account never changes, so the sender doesn't have to be locked yet
+	fromAccount, err := q.GetAccount(ctx, fromAccountID)
+	if err != nil {
+		return feeCharge{}, err
+	}
+
+	schedule, err := q.GetTransferFee(ctx, fromAccount.Currency)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return feeCharge{}, nil
 		}
+		return feeCharge{}, err
+	}
+
+	fee, err := util.TransferFee(amount, schedule.Flat, schedule.Percent, schedule.MinFee, schedule.MaxFee)
+	if err != nil || fee == 0 {
+		return feeCharge{}, err
+	}
+
+	feeAccount, err := systemAccount(ctx, q, util.FeeIncomeOwner, fromAccount.Currency)
+	if err != nil {
+		return feeCharge{}, err
+	}
+
+	return feeCharge{amount: fee, accountID: feeAccount.ID}, nil
+}
+
+// transferMoney moves money between two accounts within the transaction
+// The amount plus the fee must be covered by available balance of the sender plus reserved, which is the part of its active holds spent by this transfer
+// The sender gets one entry of both amount and fee, the fee account gets its own entry of the fee
+func transferMoney(ctx context.Context, q *Queries, fromAccountID int64, toAccountID int64, amount int64, reserved int64, fee feeCharge) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	//lock accounts and check balance before moving money
+
+	accountIDs := []int64{fromAccountID, toAccountID}
+	if fee.amount > 0 {
+		accountIDs = append(accountIDs, fee.accountID)
+	}
+
+	accounts, err := lockAccounts(ctx, q, accountIDs...)
+	if err != nil {
+		return result, err
+	}
+
+	fromAccount, toAccount := accounts[fromAccountID], accounts[toAccountID]
+
+	if fromAccount.Status != util.ActiveAccount || toAccount.Status != util.ActiveAccount {
+		return result, ErrAccountNotActive
+	}
+
+	available, err := availableBalance(ctx, q, fromAccount)
+	if err != nil {
+		return result, err
+	}
+
+	debit, err := util.NewMoney(amount, fromAccount.Currency).Add(util.NewMoney(fee.amount, fromAccount.Currency))
+	if err != nil {
+		return result, err
+	}
+
+	if available+reserved < debit.Amount {
+		return result, ErrInsufficientFunds
+	}
+
+	//convert amount into currency of the receiver
+
+	conversion, err := convertAmount(ctx, q, fromAccount.Currency, toAccount.Currency, amount)
+	if err != nil {
+		return result, err
+	}
+
+	//create transfer
+
+	txName := ctx.Value(txKey)
+	fmt.Println(txName, "create transfer")
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccountID,
+		Amount:        amount,
+		ToAmount:      conversion.toAmount,
+		ExchangeRate:  conversion.rate,
+		Rounding:      conversion.rounding,
+		Fee:           fee.amount,
+		FeeAccountID:  sql.NullInt64{Int64: fee.accountID, Valid: fee.amount > 0},
+	})
+
+	if err != nil {
+		return result, err
+	}
 
-		fmt.Println(txName, "create entry 2")
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+	err = recordTransferCreated(ctx, q, result.Transfer)
+	if err != nil {
+		return result, err
+	}
+
+	//create entry for each account
+
+	fmt.Println(txName, "create entry 1")
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  fromAccountID,
+		Amount:     -debit.Amount,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	fmt.Println(txName, "create entry 2")
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  toAccountID,
+		Amount:     conversion.toAmount,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	amounts := map[int64]int64{
+		fromAccountID: -debit.Amount,
+	}
+	amounts[toAccountID] += conversion.toAmount
+
+	if fee.amount > 0 {
+		feeEntry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  fee.accountID,
+			Amount:     fee.amount,
+			TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
 		})
 
 		if err != nil {
-			return err
+			return result, err
 		}
 
-		//update accounts balance
+		result.FeeEntry = &feeEntry
+		amounts[fee.accountID] += fee.amount
+	}
+
+	//update accounts balance
+
+	updatedAccounts, err := addBalances(ctx, q, amounts)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount, result.ToAccount = updatedAccounts[fromAccountID], updatedAccounts[toAccountID]
+
+	return result, nil
+}
+
+// availableBalance returns balance of the account minus its active holds
+func availableBalance(ctx context.Context, q *Queries, account Account) (int64, error) {
+	held, err := q.GetHeldAmount(ctx, account.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	return account.Balance - held, nil
+}
+
+// reserveIdempotencyKey reserves the key of the user for the request
+// If the key has already been used it returns true and decodes the response saved for the request into result
+// ErrIdempotencyKeyReused is returned when the key has been used with a different request
+func reserveIdempotencyKey(ctx context.Context, q *Queries, username string, key string, requestHash string, expiredAt time.Time, result interface{}) (bool, error) {
+	_, err := q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:    username,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiredAt:   expiredAt,
+	})
+
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	saved, err := q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+		Username: username,
+		Key:      key,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if saved.RequestHash != requestHash {
+		return false, ErrIdempotencyKeyReused
+	}
+
+	return true, json.Unmarshal(saved.Response, result)
+}
+
+// saveIdempotentResponse saves the result which is returned again for every replay of the request with the key
+func saveIdempotentResponse(ctx context.Context, q *Queries, username string, key string, result interface{}) error {
+	response, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.UpdateIdempotencyKeyResponse(ctx, UpdateIdempotencyKeyResponseParams{
+		Username: username,
+		Key:      key,
+		Response: response,
+	})
+
+	return err
+}
+
+// sortedAccountIDs returns unique account IDs in ascending order
+// Accounts are always locked and updated in this order, so concurrent transactions wait for each other instead of deadlocking
+func sortedAccountIDs(accountIDs ...int64) []int64 {
+	unique := make(map[int64]bool, len(accountIDs))
+	sorted := make([]int64, 0, len(accountIDs))
 
-		// compare ID because may be deadlock between two account and many transactions
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+	for _, id := range accountIDs {
+		if !unique[id] {
+			unique[id] = true
+			sorted = append(sorted, id)
 		}
+	}
+
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return sorted
+}
+
+// lockAccounts locks accounts in the order of their IDs
+func lockAccounts(ctx context.Context, q *Queries, accountIDs ...int64) (map[int64]Account, error) {
+	accounts := make(map[int64]Account, len(accountIDs))
 
+	for _, id := range sortedAccountIDs(accountIDs...) {
+		account, err := q.GetAccountForUpdate(ctx, id)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		return nil
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
+
+// getAccountsForUpdate locks two accounts ordered by ID the same way as addBalances does
+func getAccountsForUpdate(ctx context.Context, q *Queries, accountID1 int64, accountID2 int64) (account1 Account, account2 Account, err error) {
+	accounts, err := lockAccounts(ctx, q, accountID1, accountID2)
+	if err != nil {
+		return
+	}
+
+	return accounts[accountID1], accounts[accountID2], nil
+}
+
+// currencyConversion describes how the transfer amount is credited to the receiver
+type currencyConversion struct {
+	toAmount int64
+	rate     string
+	rounding string
+}
+
+// convertAmount converts amount between currencies with the current exchange rate
+func convertAmount(ctx context.Context, q *Queries, fromCurrency string, toCurrency string, amount int64) (currencyConversion, error) {
+	if fromCurrency == toCurrency {
+		return currencyConversion{toAmount: amount, rate: "1", rounding: "0"}, nil
+	}
+
+	exchangeRate, err := q.GetExchangeRate(ctx, GetExchangeRateParams{
+		FromCurrency: fromCurrency,
+		ToCurrency:   toCurrency,
 	})
 
-	return result, err
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return currencyConversion{}, fmt.Errorf("%w: %s to %s", ErrExchangeRateNotFound, fromCurrency, toCurrency)
+		}
+		return currencyConversion{}, err
+	}
+
+	toAmount, rounding, err := util.ConvertAmount(amount, exchangeRate.Rate)
+	if err != nil {
+		return currencyConversion{}, err
+	}
+
+	if toAmount <= 0 {
+		return currencyConversion{}, ErrAmountTooSmall
+	}
+
+	return currencyConversion{toAmount: toAmount, rate: exchangeRate.Rate, rounding: rounding}, nil
 }
 
-func addMoney(
+// moveMoney debits one account and credits another one
+// It returns ErrInsufficientFunds if the debited balance becomes negative
+func moveMoney(
 	ctx context.Context,
 	q *Queries,
 	fromAccountID int64,
-	amount1 int64,
+	debit int64,
 	toAccountID int64,
-	amount2 int64,
+	credit int64,
 ) (fromAccount Account, toAccount Account, err error) {
-	fromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     fromAccountID,
-		Amount: amount1,
-	})
+	amounts := make(map[int64]int64, 2)
+	amounts[fromAccountID] -= debit
+	amounts[toAccountID] += credit
 
+	accounts, err := addBalances(ctx, q, amounts)
 	if err != nil {
 		return
 	}
 
-	toAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     toAccountID,
-		Amount: amount2,
-	})
+	return accounts[fromAccountID], accounts[toAccountID], nil
+}
 
-	if err != nil {
-		return
+// addBalances adds amounts to balances of accounts in the order of their IDs
+// It returns ErrInsufficientFunds if any balance becomes negative and util.ErrAmountOverflow if it doesn't fit into bigint
+func addBalances(ctx context.Context, q *Queries, amounts map[int64]int64) (map[int64]Account, error) {
+	accountIDs := make([]int64, 0, len(amounts))
+	for id := range amounts {
+		accountIDs = append(accountIDs, id)
+	}
+
+	accounts := make(map[int64]Account, len(amounts))
+
+	for _, id := range sortedAccountIDs(accountIDs...) {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     id,
+			Amount: amounts[id],
+		})
+
+		if err != nil {
+			if pqErr, ok := err.(*pq.Error); ok {
+				if pqErr.Constraint == balanceConstraint {
+					return nil, ErrInsufficientFunds
+				}
+
+				if pqErr.Code.Name() == "numeric_value_out_of_range" {
+					return nil, fmt.Errorf("account %d: %w", id, util.ErrAmountOverflow)
+				}
+			}
+			return nil, err
+		}
+
+		accounts[id] = account
 	}
 
-	return
+	return accounts, nil
 }
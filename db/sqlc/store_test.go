@@ -2,23 +2,41 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
+// createFundedAccount creates a random account in the currency and tops it up with the amount
+func createFundedAccount(t *testing.T, currency string, amount int64) Account {
+	account := createRandomAccountWithCurrency(t, currency)
+
+	account, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: amount,
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, account)
+
+	return account
+}
+
 func TestTransferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	fromAccount := createRandomAccount(t)
-	toAccount := createRandomAccount(t)
-
 	//run n concurrent transfer transactions
 	n := 5
 	amount := int64(10)
 
+	fromAccount := createFundedAccount(t, util.USD, int64(n)*amount)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
 	errChan := make(chan error)
 	resultChan := make(chan TransferTxResult)
 
@@ -68,6 +86,7 @@ func TestTransferTx(t *testing.T) {
 		require.Equal(t, -amount, fromEntry.Amount)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
+		require.Equal(t, sql.NullInt64{Int64: transfer.ID, Valid: true}, fromEntry.TransferID)
 
 		_, err = store.GetEntry(context.Background(), fromEntry.ID)
 		require.NoError(t, err)
@@ -79,6 +98,7 @@ func TestTransferTx(t *testing.T) {
 		require.Equal(t, amount, toEntry.Amount)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
+		require.Equal(t, sql.NullInt64{Int64: transfer.ID, Valid: true}, toEntry.TransferID)
 
 		_, err = store.GetEntry(context.Background(), toEntry.ID)
 		require.NoError(t, err)
@@ -135,13 +155,13 @@ func TestTransferTx(t *testing.T) {
 func TestTransferTxDeadlock(t *testing.T) {
 	store := NewStore(testDB)
 
-	fromAccount := createRandomAccount(t)
-	toAccount := createRandomAccount(t)
-
 	//run n concurrent transfer transactions (n - should be even)
 	n := 10
 	amount := int64(10)
 
+	fromAccount := createFundedAccount(t, util.USD, int64(n)*amount)
+	toAccount := createFundedAccount(t, util.USD, int64(n)*amount)
+
 	errChan := make(chan error)
 
 	for i := 0; i < n; i++ {
@@ -179,3 +199,217 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, fromAccount.Balance, updatedAccount1.Balance)
 	require.Equal(t, toAccount.Balance, updatedAccount2.Balance)
 }
+
+func TestTransferTxIdempotency(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createFundedAccount(t, util.USD, int64(10))
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	arg := TransferTxParam{
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    toAccount.ID,
+		Amount:         int64(10),
+		IdempotencyKey: util.RandomString(32),
+		KeyUsername:    fromAccount.Owner,
+		RequestHash:    util.RandomString(64),
+		KeyExpiredAt:   time.Now().Add(time.Minute),
+	}
+
+	result1, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, result1)
+
+	//replay of the same request returns the saved result without moving money again
+	result2, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, result1.Transfer.ID, result2.Transfer.ID)
+	require.Equal(t, result1.FromEntry.ID, result2.FromEntry.ID)
+	require.Equal(t, result1.ToEntry.ID, result2.ToEntry.ID)
+	require.Equal(t, result1.FromAccount.Balance, result2.FromAccount.Balance)
+	require.Equal(t, result1.ToAccount.Balance, result2.ToAccount.Balance)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance-arg.Amount, updatedAccount.Balance)
+
+	//same key with a different request is rejected
+	arg.Amount = int64(20)
+	arg.RequestHash = util.RandomString(64)
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrIdempotencyKeyReused)
+
+	//the same key of another user is not a replay
+	arg.Amount = int64(1)
+	arg.KeyUsername = toAccount.Owner
+
+	result3, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEqual(t, result1.Transfer.ID, result3.Transfer.ID)
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	fromAccount := createRandomAccountWithCurrency(t, util.USD)
+	toAccount := createRandomAccountWithCurrency(t, util.USD)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        fromAccount.Balance + 1,
+	})
+
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	require.Empty(t, result.Transfer)
+
+	//balances stay untouched
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), toAccount.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, fromAccount.Balance, updatedAccount1.Balance)
+	require.Equal(t, toAccount.Balance, updatedAccount2.Balance)
+}
+
+func TestIsRetryableTxError(t *testing.T) {
+	require.True(t, isRetryableTxError(&pq.Error{Code: serializationFailureCode}))
+	require.True(t, isRetryableTxError(&pq.Error{Code: deadlockDetectedCode}))
+	require.True(t, isRetryableTxError(fmt.Errorf("wrapped: %w", &pq.Error{Code: deadlockDetectedCode})))
+
+	require.False(t, isRetryableTxError(&pq.Error{Code: "23505"}))
+	require.False(t, isRetryableTxError(ErrInsufficientFunds))
+	require.False(t, isRetryableTxError(sql.ErrNoRows))
+}
+
+// runConflictingTx updates the account inside a transaction which read it before a concurrent update on its first attempt
+func runConflictingTx(t *testing.T, store *SQLStore, opts txOptions, accountID int64) (int, error) {
+	attempts := 0
+
+	err := store.execTx(context.Background(), opts, func(q *Queries) error {
+		attempts++
+
+		//the first read takes the snapshot of the transaction
+		_, err := q.GetAccount(context.Background(), accountID)
+		if err != nil {
+			return err
+		}
+
+		if attempts == 1 {
+			_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: accountID, Amount: 1})
+			require.NoError(t, err)
+		}
+
+		_, err = q.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: accountID, Amount: 1})
+		return err
+	})
+
+	return attempts, err
+}
+
+func TestExecTxRetriesSerializationFailure(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account := createRandomAccount(t)
+
+	attempts, err := runConflictingTx(t, store, serializableTxOptions, account.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+
+	//both the concurrent update and the retried one are applied
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+2, updatedAccount.Balance)
+}
+
+func TestExecTxSerializationFailureWithoutRetries(t *testing.T) {
+	store := NewStore(testDB).(*SQLStore)
+	account := createRandomAccount(t)
+
+	opts := serializableTxOptions
+	opts.maxRetries = 0
+
+	attempts, err := runConflictingTx(t, store, opts, account.ID)
+	require.Equal(t, 1, attempts)
+
+	var pqErr *pq.Error
+	require.ErrorAs(t, err, &pqErr)
+	require.Equal(t, serializationFailureCode, string(pqErr.Code))
+}
+
+func TestTxRetryDelay(t *testing.T) {
+	opts := txOptions{
+		isolation:  sql.LevelSerializable,
+		maxRetries: 100,
+		backoff:    10 * time.Millisecond,
+	}
+
+	for attempt := 0; attempt < opts.maxRetries; attempt++ {
+		delay := opts.retryDelay(attempt)
+
+		require.Greater(t, delay, time.Duration(0))
+		require.LessOrEqual(t, delay, maxTxBackoff)
+	}
+
+	require.LessOrEqual(t, opts.retryDelay(0), opts.backoff)
+	require.GreaterOrEqual(t, opts.retryDelay(0), opts.backoff/2)
+}
+
+func TestTransferTxCrossCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		FromCurrency: util.USD,
+		ToCurrency:   util.EUR,
+		Rate:         "0.9215",
+	})
+	require.NoError(t, err)
+
+	amount := int64(1000)
+
+	fromAccount := createFundedAccount(t, util.USD, amount)
+	toAccount := createRandomAccountWithCurrency(t, util.EUR)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	//921.5 is rounded half to even
+	toAmount := int64(922)
+
+	require.Equal(t, amount, result.Transfer.Amount)
+	require.Equal(t, toAmount, result.Transfer.ToAmount)
+	require.Equal(t, "0.9215", result.Transfer.ExchangeRate)
+	require.Equal(t, "-0.5000", result.Transfer.Rounding)
+
+	require.Equal(t, -amount, result.FromEntry.Amount)
+	require.Equal(t, toAmount, result.ToEntry.Amount)
+
+	require.Equal(t, fromAccount.Balance-amount, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+toAmount, result.ToAccount.Balance)
+}
+
+func TestTransferTxExchangeRateNotFound(t *testing.T) {
+	store := NewStore(testDB)
+
+	err := testQueries.DeleteExchangeRate(context.Background(), DeleteExchangeRateParams{
+		FromCurrency: util.RUB,
+		ToCurrency:   util.EUR,
+	})
+	require.NoError(t, err)
+
+	fromAccount := createFundedAccount(t, util.RUB, int64(10))
+	toAccount := createRandomAccountWithCurrency(t, util.EUR)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        int64(10),
+	})
+	require.ErrorIs(t, err, ErrExchangeRateNotFound)
+}
@@ -7,24 +7,117 @@ package db
 
 import (
 	"context"
+	"database/sql"
 )
 
+const addTransferReversedAmount = `-- name: AddTransferReversedAmount :one
+UPDATE transfers
+  set reversed_amount = reversed_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding, reversal_of, reversed_amount, fee, fee_account_id
+`
+
+type AddTransferReversedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, addTransferReversedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Rounding,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+		&i.FeeAccountID,
+	)
+	return i, err
+}
+
+const createReverseTransfer = `-- name: CreateReverseTransfer :one
+INSERT INTO transfers (
+  from_account_id, to_account_id, amount, to_amount, exchange_rate, rounding, reversal_of
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding, reversal_of, reversed_amount, fee, fee_account_id
+`
+
+type CreateReverseTransferParams struct {
+	FromAccountID int64         `json:"fromAccountID"`
+	ToAccountID   int64         `json:"toAccountID"`
+	Amount        int64         `json:"amount"`
+	ToAmount      int64         `json:"toAmount"`
+	ExchangeRate  string        `json:"exchangeRate"`
+	Rounding      string        `json:"rounding"`
+	ReversalOf    sql.NullInt64 `json:"reversalOf"`
+}
+
+func (q *Queries) CreateReverseTransfer(ctx context.Context, arg CreateReverseTransferParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, createReverseTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.Rounding,
+		arg.ReversalOf,
+	)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Rounding,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+		&i.FeeAccountID,
+	)
+	return i, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, to_amount, exchange_rate, rounding, fee, fee_account_id
 ) VALUES (
-  $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding, reversal_of, reversed_amount, fee, fee_account_id
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"fromAccountID"`
-	ToAccountID   int64 `json:"toAccountID"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"fromAccountID"`
+	ToAccountID   int64         `json:"toAccountID"`
+	Amount        int64         `json:"amount"`
+	ToAmount      int64         `json:"toAmount"`
+	ExchangeRate  string        `json:"exchangeRate"`
+	Rounding      string        `json:"rounding"`
+	Fee           int64         `json:"fee"`
+	FeeAccountID  sql.NullInt64 `json:"feeAccountID"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.Rounding,
+		arg.Fee,
+		arg.FeeAccountID,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -32,6 +125,13 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Rounding,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+		&i.FeeAccountID,
 	)
 	return i, err
 }
@@ -47,7 +147,7 @@ func (q *Queries) DeleteTransfer(ctx context.Context, id int64) error {
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding, reversal_of, reversed_amount, fee, fee_account_id FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -60,12 +160,209 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Rounding,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+		&i.FeeAccountID,
 	)
 	return i, err
 }
 
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding, reversal_of, reversed_amount, fee, fee_account_id FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Rounding,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+		&i.FeeAccountID,
+	)
+	return i, err
+}
+
+const listAccountTransfers = `-- name: ListAccountTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding, reversal_of, reversed_amount, fee, fee_account_id FROM transfers
+WHERE (
+    ($1::varchar <> 'incoming' AND from_account_id = $2
+      AND ($3::bigint IS NULL OR to_account_id = $3))
+    OR ($1::varchar <> 'outgoing' AND to_account_id = $2
+      AND ($3::bigint IS NULL OR from_account_id = $3))
+  )
+  AND ($4::bigint IS NULL OR amount >= $4)
+  AND ($5::bigint IS NULL OR amount <= $5)
+  AND ($6::timestamptz IS NULL OR created_at >= $6)
+  AND ($7::timestamptz IS NULL OR created_at < $7)
+  AND ($8::timestamptz IS NULL
+    OR (created_at, id) > ($8, $9::bigint))
+ORDER BY created_at, id
+LIMIT $10
+OFFSET $11
+`
+
+type ListAccountTransfersParams struct {
+	Direction      string        `json:"direction"`
+	AccountID      int64         `json:"accountID"`
+	CounterpartyID sql.NullInt64 `json:"counterpartyID"`
+	MinAmount      sql.NullInt64 `json:"minAmount"`
+	MaxAmount      sql.NullInt64 `json:"maxAmount"`
+	CreatedFrom    sql.NullTime  `json:"createdFrom"`
+	CreatedTo      sql.NullTime  `json:"createdTo"`
+	AfterCreatedAt sql.NullTime  `json:"afterCreatedAt"`
+	AfterID        sql.NullInt64 `json:"afterID"`
+	Limit          int32         `json:"limit"`
+	Offset         int32         `json:"offset"`
+}
+
+func (q *Queries) ListAccountTransfers(ctx context.Context, arg ListAccountTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountTransfers,
+		arg.Direction,
+		arg.AccountID,
+		arg.CounterpartyID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.Rounding,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.Fee,
+			&i.FeeAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnerTransfers = `-- name: ListOwnerTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding, reversal_of, reversed_amount, fee, fee_account_id FROM transfers
+WHERE (
+    ($1::varchar <> 'incoming'
+      AND from_account_id IN (SELECT id FROM accounts WHERE owner = $2)
+      AND ($3::bigint IS NULL OR to_account_id = $3))
+    OR ($1::varchar <> 'outgoing'
+      AND to_account_id IN (SELECT id FROM accounts WHERE owner = $2)
+      AND ($3::bigint IS NULL OR from_account_id = $3))
+  )
+  AND ($4::bigint IS NULL OR amount >= $4)
+  AND ($5::bigint IS NULL OR amount <= $5)
+  AND ($6::timestamptz IS NULL OR created_at >= $6)
+  AND ($7::timestamptz IS NULL OR created_at < $7)
+  AND ($8::timestamptz IS NULL
+    OR (created_at, id) > ($8, $9::bigint))
+ORDER BY created_at, id
+LIMIT $10
+OFFSET $11
+`
+
+type ListOwnerTransfersParams struct {
+	Direction      string        `json:"direction"`
+	Owner          string        `json:"owner"`
+	CounterpartyID sql.NullInt64 `json:"counterpartyID"`
+	MinAmount      sql.NullInt64 `json:"minAmount"`
+	MaxAmount      sql.NullInt64 `json:"maxAmount"`
+	CreatedFrom    sql.NullTime  `json:"createdFrom"`
+	CreatedTo      sql.NullTime  `json:"createdTo"`
+	AfterCreatedAt sql.NullTime  `json:"afterCreatedAt"`
+	AfterID        sql.NullInt64 `json:"afterID"`
+	Limit          int32         `json:"limit"`
+	Offset         int32         `json:"offset"`
+}
+
+func (q *Queries) ListOwnerTransfers(ctx context.Context, arg ListOwnerTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listOwnerTransfers,
+		arg.Direction,
+		arg.Owner,
+		arg.CounterpartyID,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.Rounding,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.Fee,
+			&i.FeeAccountID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding, reversal_of, reversed_amount, fee, fee_account_id FROM transfers
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -91,6 +388,13 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.Rounding,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.Fee,
+			&i.FeeAccountID,
 		); err != nil {
 			return nil, err
 		}
@@ -109,7 +413,7 @@ const updateTransfer = `-- name: UpdateTransfer :one
 UPDATE transfers
   set amount = $2
 WHERE id = $1
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, rounding, reversal_of, reversed_amount, fee, fee_account_id
 `
 
 type UpdateTransferParams struct {
@@ -126,6 +430,13 @@ func (q *Queries) UpdateTransfer(ctx context.Context, arg UpdateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.Rounding,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.Fee,
+		&i.FeeAccountID,
 	)
 	return i, err
 }
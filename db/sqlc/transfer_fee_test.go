@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// setRandomCurrencyFee sets the fee of a currency no other test has, so fees don't affect other tests
+func setRandomCurrencyFee(t *testing.T, arg SetTransferFeeParams) TransferFee {
+	arg.Currency = strings.ToUpper(util.RandomString(3))
+
+	fee, err := testQueries.SetTransferFee(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Currency, fee.Currency)
+	require.Equal(t, arg.Percent, fee.Percent)
+
+	return fee
+}
+
+func TestGetTransferFee(t *testing.T) {
+	fee := setRandomCurrencyFee(t, SetTransferFeeParams{Flat: 10, Percent: "0"})
+
+	fee1, err := testQueries.GetTransferFee(context.Background(), fee.Currency)
+	require.NoError(t, err)
+	require.Equal(t, fee.ID, fee1.ID)
+
+	//setting the fee of the same currency again replaces it
+	fee2, err := testQueries.SetTransferFee(context.Background(), SetTransferFeeParams{
+		Currency: fee.Currency,
+		Percent:  "2",
+		MaxFee:   100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, fee.ID, fee2.ID)
+	require.Zero(t, fee2.Flat)
+	require.Equal(t, int64(100), fee2.MaxFee)
+
+	deleted, err := testQueries.DeleteTransferFee(context.Background(), fee2.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+}
+
+func TestTransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	fee := setRandomCurrencyFee(t, SetTransferFeeParams{Flat: 5, Percent: "1", MinFee: 10})
+
+	account1 := createFundedAccount(t, fee.Currency, 1000)
+	account2 := createRandomAccountWithCurrency(t, fee.Currency)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        500,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(500), result.Transfer.Amount)
+	require.Equal(t, int64(10), result.Transfer.Fee)
+	require.True(t, result.Transfer.FeeAccountID.Valid)
+
+	require.Equal(t, int64(-510), result.FromEntry.Amount)
+	require.Equal(t, int64(500), result.ToEntry.Amount)
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, result.Transfer.FeeAccountID.Int64, result.FeeEntry.AccountID)
+	require.Equal(t, int64(10), result.FeeEntry.Amount)
+	require.Equal(t, result.Transfer.ID, result.FeeEntry.TransferID.Int64)
+
+	require.Equal(t, int64(490), result.FromAccount.Balance)
+	require.Equal(t, int64(500), result.ToAccount.Balance)
+
+	feeAccount, err := testQueries.GetAccount(context.Background(), result.FeeEntry.AccountID)
+	require.NoError(t, err)
+	require.Equal(t, util.FeeIncomeOwner, feeAccount.Owner)
+	require.Equal(t, fee.Currency, feeAccount.Currency)
+	require.Equal(t, int64(10), feeAccount.Balance)
+
+	//the fee has to be covered by the balance too
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        485,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	report, err := store.ReconcileTx(context.Background())
+	require.NoError(t, err)
+
+	for _, mismatch := range report.TransferMismatches {
+		require.NotEqual(t, result.Transfer.ID, mismatch.ID)
+	}
+}
+
+func TestBatchTransferTxFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	fee := setRandomCurrencyFee(t, SetTransferFeeParams{Percent: "10", MaxFee: 15})
+
+	account1 := createFundedAccount(t, fee.Currency, 300)
+	account2 := createRandomAccountWithCurrency(t, fee.Currency)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParam{
+		Legs: []BatchTransferLeg{
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 100},
+			{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: 170},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Transfers, 2)
+
+	require.Equal(t, int64(10), result.Transfers[0].Transfer.Fee)
+	require.Equal(t, int64(15), result.Transfers[1].Transfer.Fee)
+	require.NotNil(t, result.Transfers[1].FeeEntry)
+
+	require.Equal(t, int64(5), result.Transfers[1].FromAccount.Balance)
+	require.Equal(t, int64(270), result.Transfers[1].ToAccount.Balance)
+}
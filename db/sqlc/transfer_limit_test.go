@@ -0,0 +1,292 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTransferLimit(t *testing.T) {
+	account := createRandomAccount(t)
+
+	arg := SetTransferLimitParams{
+		AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+		Currency:  account.Currency,
+		MaxAmount: sql.NullInt64{Int64: 100, Valid: true},
+	}
+
+	limit1, err := testQueries.SetTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.AccountID, limit1.AccountID)
+	require.False(t, limit1.Owner.Valid)
+	require.Equal(t, arg.MaxAmount, limit1.MaxAmount)
+	require.False(t, limit1.DailyAmount.Valid)
+
+	//setting the limit of the same scope again replaces it
+	arg.MaxAmount = sql.NullInt64{}
+	arg.DailyCount = sql.NullInt32{Int32: 3, Valid: true}
+
+	limit2, err := testQueries.SetTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, limit1.ID, limit2.ID)
+	require.False(t, limit2.MaxAmount.Valid)
+	require.Equal(t, arg.DailyCount, limit2.DailyCount)
+
+	deleted, err := testQueries.DeleteTransferLimit(context.Background(), limit2.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	_, err = testQueries.GetTransferLimit(context.Background(), limit2.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestTransferTxAccountLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, util.USD, 1000)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+
+	_, err := testQueries.SetTransferLimit(context.Background(), SetTransferLimitParams{
+		AccountID:   sql.NullInt64{Int64: account1.ID, Valid: true},
+		Currency:    account1.Currency,
+		MaxAmount:   sql.NullInt64{Int64: 100, Valid: true},
+		DailyAmount: sql.NullInt64{Int64: 150, Valid: true},
+		DailyCount:  sql.NullInt32{Int32: 3, Valid: true},
+	})
+	require.NoError(t, err)
+
+	transfer := func(amount int64) error {
+		_, err := store.TransferTx(context.Background(), TransferTxParam{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		return err
+	}
+
+	require.ErrorIs(t, transfer(101), ErrTransferLimitExceeded)
+	require.NoError(t, transfer(100))
+
+	//the failed transfer is rolled back and doesn't count
+	require.ErrorIs(t, transfer(60), ErrTransferLimitExceeded)
+	require.NoError(t, transfer(30))
+	require.NoError(t, transfer(20))
+
+	//the daily amount is used up and so is the count
+	require.ErrorIs(t, transfer(1), ErrTransferLimitExceeded)
+
+	limitsTx, err := store.TransferLimitsTx(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, limitsTx.Limits, 1)
+	require.Equal(t, AccountLimitScope, limitsTx.Limits[0].Scope)
+	require.Equal(t, int64(150), limitsTx.Limits[0].UsedAmount)
+	require.Equal(t, int64(3), limitsTx.Limits[0].UsedCount)
+
+	account1, err = testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(850), account1.Balance)
+}
+
+func TestTransferTxUserLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createFundedAccount(t, util.EUR, 1000)
+	receiver := createRandomAccountWithCurrency(t, util.EUR)
+
+	_, err := testQueries.SetTransferLimit(context.Background(), SetTransferLimitParams{
+		Owner:       sql.NullString{String: account.Owner, Valid: true},
+		Currency:    util.EUR,
+		DailyAmount: sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account.ID,
+		ToAccountID:   receiver.ID,
+		Amount:        70,
+	})
+	require.NoError(t, err)
+
+	//the whole batch counts towards the limit
+	_, err = store.BatchTransferTx(context.Background(), BatchTransferTxParam{
+		Legs: []BatchTransferLeg{
+			{FromAccountID: account.ID, ToAccountID: receiver.ID, Amount: 20},
+			{FromAccountID: account.ID, ToAccountID: receiver.ID, Amount: 20},
+		},
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account.ID,
+		ToAccountID:   receiver.ID,
+		Amount:        30,
+	})
+	require.NoError(t, err)
+
+	limitsTx, err := store.TransferLimitsTx(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, limitsTx.Limits, 1)
+	require.Equal(t, UserLimitScope, limitsTx.Limits[0].Scope)
+	require.Equal(t, int64(100), limitsTx.Limits[0].UsedAmount)
+	require.Equal(t, int64(2), limitsTx.Limits[0].UsedCount)
+}
+
+func TestTransferTxUserLimitsAcrossCurrencies(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, util.EUR, 1000)
+
+	//second account of the same user in a currency no other test has
+	currency := strings.ToUpper(util.RandomString(3))
+	account2, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account1.Owner,
+		Balance:  1000,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		FromCurrency: currency,
+		ToCurrency:   util.EUR,
+		Rate:         "2",
+	})
+	require.NoError(t, err)
+
+	receiver1 := createRandomAccountWithCurrency(t, util.EUR)
+	receiver2 := createRandomAccountWithCurrency(t, currency)
+
+	_, err = testQueries.SetTransferLimit(context.Background(), SetTransferLimitParams{
+		Owner:       sql.NullString{String: account1.Owner, Valid: true},
+		Currency:    util.EUR,
+		DailyAmount: sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   receiver1.ID,
+		Amount:        70,
+	})
+	require.NoError(t, err)
+
+	//20 in the other currency are 40 EUR, so the user limit is exceeded
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account2.ID,
+		ToAccountID:   receiver2.ID,
+		Amount:        20,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account2.ID,
+		ToAccountID:   receiver2.ID,
+		Amount:        15,
+	})
+	require.NoError(t, err)
+
+	limitsTx, err := store.TransferLimitsTx(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, limitsTx.Limits, 1)
+	require.Equal(t, UserLimitScope, limitsTx.Limits[0].Scope)
+	require.Equal(t, int64(100), limitsTx.Limits[0].UsedAmount)
+	require.Equal(t, int64(2), limitsTx.Limits[0].UsedCount)
+}
+
+func TestTransferTxUserLimitsWithoutExchangeRate(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, util.EUR, 1000)
+
+	//second account of the same user in a currency which has no rate to EUR
+	currency := strings.ToUpper(util.RandomString(3))
+	account2, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account1.Owner,
+		Balance:  1000,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+
+	receiver1 := createRandomAccountWithCurrency(t, util.EUR)
+	receiver2 := createRandomAccountWithCurrency(t, currency)
+
+	//the transfer is made before the limit, so nothing needs the missing rate yet
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account2.ID,
+		ToAccountID:   receiver2.ID,
+		Amount:        20,
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.SetTransferLimit(context.Background(), SetTransferLimitParams{
+		Owner:       sql.NullString{String: account1.Owner, Valid: true},
+		Currency:    util.EUR,
+		DailyAmount: sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+
+	//transfers in EUR don't need the missing rate
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   receiver1.ID,
+		Amount:        70,
+	})
+	require.NoError(t, err)
+
+	limitsTx, err := store.TransferLimitsTx(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, limitsTx.Limits, 1)
+	require.Equal(t, int64(70), limitsTx.Limits[0].UsedAmount)
+	require.Equal(t, int64(2), limitsTx.Limits[0].UsedCount)
+	require.Equal(t, []string{currency}, limitsTx.Limits[0].UnconvertedCurrencies)
+
+	//transfers in the other currency can't be checked against the limit
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account2.ID,
+		ToAccountID:   receiver2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrExchangeRateNotFound)
+}
+
+func TestTransferTxCurrencyDefaultLimit(t *testing.T) {
+	store := NewStore(testDB)
+
+	//the default limit applies to every account in the currency, so use a currency no other test has
+	currency := strings.ToUpper(util.RandomString(3))
+
+	account1 := createFundedAccount(t, currency, 1000)
+	account2 := createFundedAccount(t, currency, 1000)
+	receiver := createRandomAccountWithCurrency(t, currency)
+
+	_, err := testQueries.SetTransferLimit(context.Background(), SetTransferLimitParams{
+		Currency:  currency,
+		MaxAmount: sql.NullInt64{Int64: 50, Valid: true},
+	})
+	require.NoError(t, err)
+
+	//limit of the account replaces the default
+	_, err = testQueries.SetTransferLimit(context.Background(), SetTransferLimitParams{
+		AccountID: sql.NullInt64{Int64: account2.ID, Valid: true},
+		Currency:  currency,
+		MaxAmount: sql.NullInt64{Int64: 500, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account1.ID,
+		ToAccountID:   receiver.ID,
+		Amount:        100,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParam{
+		FromAccountID: account2.ID,
+		ToAccountID:   receiver.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+}
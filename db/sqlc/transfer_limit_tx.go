@@ -0,0 +1,196 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TransferLimitWindow is the rolling period of daily transfer limits
+const TransferLimitWindow = 24 * time.Hour
+
+// Scopes of transfer limits
+const (
+	AccountLimitScope = "account"
+	UserLimitScope    = "user"
+)
+
+// TransferLimitUsage contain a limit which applies to an account and how much of it is used within the window
+// The account scope limit is either set for the account or is the default of its currency
+// The user scope limit covers outgoing transfers of all accounts of the owner whatever their currency
+// Usage of a user limit is in currency of the limit, transfers in other currencies are converted with the current exchange rates
+// Transfers in currencies without a rate to the limit currency are counted but their amounts are not, those currencies are listed in UnconvertedCurrencies
+type TransferLimitUsage struct {
+	Scope                 string        `json:"scope"`
+	Limit                 TransferLimit `json:"limit"`
+	UsedAmount            int64         `json:"used_amount"`
+	UsedCount             int64         `json:"used_count"`
+	UnconvertedCurrencies []string      `json:"unconverted_currencies,omitempty"`
+}
+
+// TransferLimitsTxResult contain limits of an account with their usage
+type TransferLimitsTxResult struct {
+	Account Account              `json:"account"`
+	Since   time.Time            `json:"since"`
+	Limits  []TransferLimitUsage `json:"limits"`
+}
+
+// TransferLimitsTx returns every limit which applies to outgoing transfers of an account and how much of it is used
+// The limits and the transfer history are read from one snapshot of the database
+func (store *SQLStore) TransferLimitsTx(ctx context.Context, accountID int64) (TransferLimitsTxResult, error) {
+	var result TransferLimitsTxResult
+
+	err := store.execTx(ctx, snapshotTxOptions, func(q *Queries) error {
+		var err error
+
+		result = TransferLimitsTxResult{Since: time.Now().Add(-TransferLimitWindow)}
+
+		result.Account, err = q.GetAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		result.Limits, err = transferLimitUsages(ctx, q, result.Account, result.Since)
+		return err
+	})
+
+	return result, err
+}
+
+// transferLimitUsages loads limits of the account and sums outgoing transfers made since the given time for each of them
+func transferLimitUsages(ctx context.Context, q *Queries, account Account, since time.Time) ([]TransferLimitUsage, error) {
+	limits, err := q.ListAccountTransferLimits(ctx, ListAccountTransferLimitsParams{
+		Currency:  account.Currency,
+		AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+		Owner:     sql.NullString{String: account.Owner, Valid: true},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	//limit set for the account replaces the default of the currency, every limit of the owner applies
+
+	var accountLimit *TransferLimit
+	var userLimits []TransferLimit
+	for i := range limits {
+		limit := &limits[i]
+
+		switch {
+		case limit.Owner.Valid:
+			userLimits = append(userLimits, *limit)
+		case limit.AccountID.Valid:
+			accountLimit = limit
+		case accountLimit == nil:
+			accountLimit = limit
+		}
+	}
+
+	usages := []TransferLimitUsage{}
+
+	if accountLimit != nil {
+		total, err := q.GetAccountOutgoingTotal(ctx, GetAccountOutgoingTotalParams{
+			FromAccountID: account.ID,
+			CreatedAt:     since,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		usages = append(usages, TransferLimitUsage{
+			Scope:      AccountLimitScope,
+			Limit:      *accountLimit,
+			UsedAmount: total.TotalAmount,
+			UsedCount:  total.TransferCount,
+		})
+	}
+
+	if len(userLimits) == 0 {
+		return usages, nil
+	}
+
+	totals, err := q.ListOwnerOutgoingTotals(ctx, ListOwnerOutgoingTotalsParams{
+		Owner:     account.Owner,
+		CreatedAt: since,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, limit := range userLimits {
+		usage := TransferLimitUsage{
+			Scope: UserLimitScope,
+			Limit: limit,
+		}
+
+		for _, total := range totals {
+			amount, err := convertLimitAmount(ctx, q, total.Currency, limit.Currency, total.TotalAmount)
+
+			//a missing rate of another currency must not block transfers which don't need it,
+			//transfers in the currency of the account can't be checked without it though
+			if errors.Is(err, ErrExchangeRateNotFound) && total.Currency != account.Currency {
+				usage.UnconvertedCurrencies = append(usage.UnconvertedCurrencies, total.Currency)
+				usage.UsedCount += total.TransferCount
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			usage.UsedAmount += amount
+			usage.UsedCount += total.TransferCount
+		}
+
+		usages = append(usages, usage)
+	}
+
+	return usages, nil
+}
+
+// convertLimitAmount converts amount to currency of a limit, amounts too small to be converted count as zero
+func convertLimitAmount(ctx context.Context, q *Queries, fromCurrency string, toCurrency string, amount int64) (int64, error) {
+	conversion, err := convertAmount(ctx, q, fromCurrency, toCurrency, amount)
+	if errors.Is(err, ErrAmountTooSmall) {
+		return 0, nil
+	}
+
+	return conversion.toAmount, err
+}
+
+// checkTransferLimits returns ErrTransferLimitExceeded if outgoing transfers of the account break any of its limits
+// It must be called after the new transfers are created, so they are already counted in the usage
+// largestAmount is the largest of the new transfers in currency of the account and is checked against the single transfer limit
+func checkTransferLimits(ctx context.Context, q *Queries, account Account, largestAmount int64) error {
+	usages, err := transferLimitUsages(ctx, q, account, time.Now().Add(-TransferLimitWindow))
+	if err != nil {
+		return err
+	}
+
+	for _, usage := range usages {
+		limit := usage.Limit
+
+		amount, err := convertLimitAmount(ctx, q, account.Currency, limit.Currency, largestAmount)
+		if err != nil {
+			return err
+		}
+
+		if limit.MaxAmount.Valid && amount > limit.MaxAmount.Int64 {
+			return fmt.Errorf("%w: %s max amount is %d", ErrTransferLimitExceeded, usage.Scope, limit.MaxAmount.Int64)
+		}
+
+		if limit.DailyAmount.Valid && usage.UsedAmount > limit.DailyAmount.Int64 {
+			return fmt.Errorf("%w: %s daily amount is %d", ErrTransferLimitExceeded, usage.Scope, limit.DailyAmount.Int64)
+		}
+
+		if limit.DailyCount.Valid && usage.UsedCount > int64(limit.DailyCount.Int32) {
+			return fmt.Errorf("%w: %s daily count is %d", ErrTransferLimitExceeded, usage.Scope, limit.DailyCount.Int32)
+		}
+	}
+
+	return nil
+}
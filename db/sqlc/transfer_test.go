@@ -14,10 +14,15 @@ func createRandomTransfer(t *testing.T) Transfer {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
 
+	amount := util.RandomMoney()
+
 	arg := CreateTransferParams{
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
-		Amount:        util.RandomMoney(),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+		Rounding:      "0",
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -28,6 +33,9 @@ func createRandomTransfer(t *testing.T) Transfer {
 	require.Equal(t, arg.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, arg.ToAccountID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
+	require.Equal(t, arg.ExchangeRate, transfer.ExchangeRate)
+	require.Equal(t, arg.Rounding, transfer.Rounding)
 
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
@@ -106,3 +114,88 @@ func TestDeleteTransfer(t *testing.T) {
 	require.EqualError(t, err, sql.ErrNoRows.Error())
 	require.Empty(t, deletedTransfer)
 }
+
+func createTransferBetween(t *testing.T, fromAccount Account, toAccount Account, amount int64) Transfer {
+	transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
+		Rounding:      "0",
+	})
+	require.NoError(t, err)
+
+	return transfer
+}
+
+func TestListAccountTransfers(t *testing.T) {
+	account := createRandomAccount(t)
+	counterparty1 := createRandomAccount(t)
+	counterparty2 := createRandomAccount(t)
+
+	outgoing := createTransferBetween(t, account, counterparty1, 10)
+	incoming := createTransferBetween(t, counterparty2, account, 20)
+	createTransferBetween(t, counterparty1, counterparty2, 30)
+
+	arg := ListAccountTransfersParams{
+		AccountID: account.ID,
+		Limit:     10,
+	}
+
+	transfers, err := testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{outgoing, incoming}, transfers)
+
+	arg.Direction = "outgoing"
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{outgoing}, transfers)
+
+	arg.Direction = "incoming"
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{incoming}, transfers)
+
+	arg.Direction = ""
+	arg.CounterpartyID = sql.NullInt64{Int64: counterparty1.ID, Valid: true}
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{outgoing}, transfers)
+
+	arg.CounterpartyID = sql.NullInt64{}
+	arg.MinAmount = sql.NullInt64{Int64: 15, Valid: true}
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{incoming}, transfers)
+
+	arg.MinAmount = sql.NullInt64{}
+	arg.CreatedTo = sql.NullTime{Time: outgoing.CreatedAt, Valid: true}
+	transfers, err = testQueries.ListAccountTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestListOwnerTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	outgoing := createTransferBetween(t, account1, account2, 10)
+	incoming := createTransferBetween(t, account2, account1, 20)
+
+	arg := ListOwnerTransfersParams{
+		Direction: "outgoing",
+		Owner:     account1.Owner,
+		Limit:     10,
+	}
+
+	transfers, err := testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{outgoing}, transfers)
+
+	arg.Direction = ""
+	arg.MaxAmount = sql.NullInt64{Int64: 20, Valid: true}
+	transfers, err = testQueries.ListOwnerTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, []Transfer{outgoing, incoming}, transfers)
+}
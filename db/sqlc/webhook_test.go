@@ -0,0 +1,142 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhook(t *testing.T, owner string, eventTypes ...string) Webhook {
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	arg := CreateWebhookParams{
+		Owner:      owner,
+		Url:        "https://example.com/hooks/" + util.RandomString(6),
+		EventTypes: eventTypes,
+		Secret:     "whsec_" + util.RandomString(32),
+	}
+
+	webhook, err := testQueries.CreateWebhook(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, webhook.ID)
+	require.Equal(t, arg.Owner, webhook.Owner)
+	require.Equal(t, arg.Url, webhook.Url)
+	require.Equal(t, arg.EventTypes, webhook.EventTypes)
+	require.Equal(t, arg.Secret, webhook.Secret)
+
+	return webhook
+}
+
+func createRandomOutboxEvent(t *testing.T, eventType string) Outbox {
+	event, err := testQueries.CreateOutboxEvent(context.Background(), CreateOutboxEventParams{
+		AggregateType: util.AccountAggregate,
+		AggregateID:   util.RandomInt(1, 1000),
+		EventType:     eventType,
+		Payload:       json.RawMessage(`{"id": 1}`),
+	})
+	require.NoError(t, err)
+
+	return event
+}
+
+func TestCreateEventWebhookDeliveries(t *testing.T) {
+	user := createRandomUser(t)
+
+	allEvents := createRandomWebhook(t, user.Username)
+	transfers := createRandomWebhook(t, user.Username, util.TransferCreatedEvent)
+	createRandomWebhook(t, user.Username, util.AccountCreatedEvent)
+	createRandomWebhook(t, createRandomUser(t).Username)
+
+	event := createRandomOutboxEvent(t, util.TransferCreatedEvent)
+
+	arg := CreateEventWebhookDeliveriesParams{
+		EventID:   event.ID,
+		EventType: event.EventType,
+		Payload:   event.Payload,
+		Owner:     user.Username,
+	}
+
+	deliveries, err := testQueries.CreateEventWebhookDeliveries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	webhookIDs := []int64{}
+	for _, delivery := range deliveries {
+		require.Equal(t, sql.NullInt64{Int64: event.ID, Valid: true}, delivery.EventID)
+		require.Equal(t, event.EventType, delivery.EventType)
+		require.JSONEq(t, string(event.Payload), string(delivery.Payload))
+		require.Equal(t, util.PendingDelivery, delivery.Status)
+		require.Zero(t, delivery.Attempts)
+		webhookIDs = append(webhookIDs, delivery.WebhookID)
+	}
+	require.ElementsMatch(t, []int64{allEvents.ID, transfers.ID}, webhookIDs)
+
+	//the event published again gets no second delivery
+	deliveries, err = testQueries.CreateEventWebhookDeliveries(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+}
+
+func TestRecordWebhookAttemptTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	webhook := createRandomWebhook(t, createRandomUser(t).Username)
+
+	delivery, err := testQueries.CreateWebhookDelivery(context.Background(), CreateWebhookDeliveryParams{
+		WebhookID: webhook.ID,
+		EventType: util.WebhookTestEvent,
+		Payload:   json.RawMessage(`{}`),
+	})
+	require.NoError(t, err)
+	require.False(t, delivery.EventID.Valid)
+
+	retryAt := time.Now().Add(time.Minute)
+
+	result, err := store.RecordWebhookAttemptTx(context.Background(), RecordWebhookAttemptTxParam{
+		DeliveryID:     delivery.ID,
+		ResponseStatus: http.StatusServiceUnavailable,
+		Error:          "receiver responded with status 503",
+		Status:         util.PendingDelivery,
+		NextAttemptAt:  retryAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), result.Delivery.Attempts)
+	require.Equal(t, util.PendingDelivery, result.Delivery.Status)
+	require.WithinDuration(t, retryAt, result.Delivery.NextAttemptAt, time.Second)
+	require.False(t, result.Delivery.DeliveredAt.Valid)
+
+	result, err = store.RecordWebhookAttemptTx(context.Background(), RecordWebhookAttemptTxParam{
+		DeliveryID:     delivery.ID,
+		ResponseStatus: http.StatusOK,
+		Status:         util.SucceededDelivery,
+		NextAttemptAt:  retryAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(2), result.Delivery.Attempts)
+	require.Equal(t, util.SucceededDelivery, result.Delivery.Status)
+	require.True(t, result.Delivery.DeliveredAt.Valid)
+
+	attempts, err := testQueries.ListWebhookAttempts(context.Background(), delivery.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	require.Equal(t, int32(http.StatusServiceUnavailable), attempts[0].ResponseStatus)
+	require.NotEmpty(t, attempts[0].Error)
+	require.Equal(t, int32(http.StatusOK), attempts[1].ResponseStatus)
+	require.Empty(t, attempts[1].Error)
+
+	//deleting the webhook deletes its delivery log
+	deleted, err := testQueries.DeleteWebhook(context.Background(), webhook.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), deleted)
+
+	_, err = testQueries.GetWebhookDelivery(context.Background(), delivery.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// RecordWebhookAttemptTxParam contain outcome of an attempt to send a webhook delivery and the next state of the delivery
+type RecordWebhookAttemptTxParam struct {
+	DeliveryID     int64     `json:"delivery_id"`
+	ResponseStatus int32     `json:"response_status"`
+	Error          string    `json:"error"`
+	Status         string    `json:"status"`
+	NextAttemptAt  time.Time `json:"next_attempt_at"`
+}
+
+// RecordWebhookAttemptTxResult contain information about result of transaction
+type RecordWebhookAttemptTxResult struct {
+	Delivery WebhookDelivery `json:"delivery"`
+	Attempt  WebhookAttempt  `json:"attempt"`
+}
+
+// RecordWebhookAttemptTx adds the attempt to the delivery log and moves the delivery to its next state
+func (store *SQLStore) RecordWebhookAttemptTx(ctx context.Context, arg RecordWebhookAttemptTxParam) (RecordWebhookAttemptTxResult, error) {
+	var result RecordWebhookAttemptTxResult
+
+	err := store.execTx(ctx, serializableTxOptions, func(q *Queries) error {
+		result = RecordWebhookAttemptTxResult{}
+
+		delivery, err := q.GetWebhookDeliveryForUpdate(ctx, arg.DeliveryID)
+		if err != nil {
+			return err
+		}
+
+		result.Attempt, err = q.CreateWebhookAttempt(ctx, CreateWebhookAttemptParams{
+			DeliveryID:     delivery.ID,
+			ResponseStatus: arg.ResponseStatus,
+			Error:          arg.Error,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		next := UpdateWebhookDeliveryParams{
+			ID:            delivery.ID,
+			Status:        arg.Status,
+			Attempts:      delivery.Attempts + 1,
+			NextAttemptAt: arg.NextAttemptAt,
+		}
+
+		if arg.Status == util.SucceededDelivery {
+			next.DeliveredAt = sql.NullTime{Time: time.Now(), Valid: true}
+		}
+
+		result.Delivery, err = q.UpdateWebhookDelivery(ctx, next)
+		return err
+	})
+
+	return result, err
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"log"
+	"os"
 
 	"github.com/gu3sswho/simplebank/api"
 	db "github.com/gu3sswho/simplebank/db/sqlc"
 	"github.com/gu3sswho/simplebank/util"
+	"github.com/gu3sswho/simplebank/worker"
 	_ "github.com/lib/pq"
 )
 
@@ -22,13 +26,69 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		runReconcile(store)
+		return
+	}
+
+	scheduler := worker.NewScheduler(config, store)
+	go scheduler.Start(context.Background())
+
+	holdExpirer := worker.NewHoldExpirer(config, store)
+	go holdExpirer.Start(context.Background())
+
+	idempotencyKeyPurger := worker.NewIdempotencyKeyPurger(config, store)
+	go idempotencyKeyPurger.Start(context.Background())
+
+	interestAccruer := worker.NewInterestAccruer(config, store)
+	go interestAccruer.Start(context.Background())
+
+	balanceSnapshotter := worker.NewBalanceSnapshotter(config, store)
+	go balanceSnapshotter.Start(context.Background())
+
+	publisher, err := worker.NewPublisher(config)
+	if err != nil {
+		log.Fatal("cannot create outbox publisher:", err)
+	}
+
+	//events are queued for webhooks of their users besides the configured publisher
+	outboxRelay := worker.NewOutboxRelay(config, store, worker.NewMultiPublisher(publisher, worker.NewWebhookPublisher(store)))
+	go outboxRelay.Start(context.Background())
+
+	webhookDispatcher := worker.NewWebhookDispatcher(config, store)
+	go webhookDispatcher.Start(context.Background())
+
 	server, err := api.NewServer(config, store)
 	if err != nil {
 		log.Fatal("cannot create server:", err)
 	}
 
+	accountListener := worker.NewAccountListener(config, server.Broker())
+	go accountListener.Start(context.Background())
+
 	err = server.Start()
 	if err != nil {
 		log.Fatal("cannot start server:", err)
 	}
 }
+
+// runReconcile prints the reconciliation report as JSON and exits with status 1 if the ledger has discrepancies
+func runReconcile(store db.Store) {
+	report, err := store.ReconcileTx(context.Background())
+	if err != nil {
+		log.Fatal("cannot reconcile ledger:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(report)
+	if err != nil {
+		log.Fatal("cannot write report:", err)
+	}
+
+	if report.HasDiscrepancies() {
+		os.Exit(1)
+	}
+}
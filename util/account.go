@@ -0,0 +1,24 @@
+package util
+
+// Constants for all statuses of accounts
+const (
+	ActiveAccount = "active"
+	FrozenAccount = "frozen"
+	ClosedAccount = "closed"
+)
+
+// SystemOwnerPrefix starts usernames of system users which own accounts of the bank itself
+// Such usernames can't be registered and only their accounts may have negative balance
+const SystemOwnerPrefix = "system:"
+
+// IsAllowedAccountTransition returns true if account status can be changed from one status to another
+// Frozen account has to be unfrozen before it can be closed, closed account never changes
+func IsAllowedAccountTransition(from string, to string) bool {
+	switch from {
+	case ActiveAccount:
+		return to == FrozenAccount || to == ClosedAccount
+	case FrozenAccount:
+		return to == ActiveAccount
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAllowedAccountTransition(t *testing.T) {
+	testCases := []struct {
+		from    string
+		to      string
+		allowed bool
+	}{
+		{ActiveAccount, FrozenAccount, true},
+		{ActiveAccount, ClosedAccount, true},
+		{FrozenAccount, ActiveAccount, true},
+		{FrozenAccount, ClosedAccount, false},
+		{ActiveAccount, ActiveAccount, false},
+		{FrozenAccount, FrozenAccount, false},
+		{ClosedAccount, ActiveAccount, false},
+		{ClosedAccount, FrozenAccount, false},
+		{ActiveAccount, "unknown", false},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.allowed, IsAllowedAccountTransition(tc.from, tc.to), "%s -> %s", tc.from, tc.to)
+	}
+}
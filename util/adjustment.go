@@ -0,0 +1,21 @@
+package util
+
+// Constants for all reasons of balance adjustments
+const (
+	CorrectionAdjustment = "correction"
+	ChargebackAdjustment = "chargeback"
+	GoodwillAdjustment   = "goodwill"
+	WriteOffAdjustment   = "write_off"
+)
+
+// SuspenseOwner owns the system accounts which balancing entries of adjustments are booked to
+const SuspenseOwner = SystemOwnerPrefix + "suspense"
+
+// IsSupportedAdjustmentReason returns true if the reason of an adjustment is supported and false or not
+func IsSupportedAdjustmentReason(reason string) bool {
+	switch reason {
+	case CorrectionAdjustment, ChargebackAdjustment, GoodwillAdjustment, WriteOffAdjustment:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,8 @@
+package util
+
+// Constants for all statuses of transfers waiting for approval
+const (
+	PendingApproval  = "pending_approval"
+	ApprovedTransfer = "approved"
+	RejectedTransfer = "rejected"
+)
@@ -1,18 +1,90 @@
 package util
 
 import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 )
 
+// DevelopmentEnvironment is the environment of local setups, which relaxes checks meant for production
+const DevelopmentEnvironment = "development"
+
 // Config stores all configuration of the application
 type Config struct {
-	DBDriver            string        `mapstructure:"DB_DRIVER"`
-	DBSource            string        `mapstructure:"DB_SOURCE"`
-	ServerAddr          string        `mapstructure:"SERVER_ADDR"`
-	TokenSymmetricKey   string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
-	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	Environment            string        `mapstructure:"ENVIRONMENT"`
+	DBDriver               string        `mapstructure:"DB_DRIVER"`
+	DBSource               string        `mapstructure:"DB_SOURCE"`
+	ServerAddr             string        `mapstructure:"SERVER_ADDR"`
+	TokenSymmetricKey      string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration    time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	IdempotencyKeyDuration time.Duration `mapstructure:"IDEMPOTENCY_KEY_DURATION"`
+	// expired idempotency keys are purged every interval once they have been expired for the retention
+	IdempotencyPurgeInterval time.Duration `mapstructure:"IDEMPOTENCY_PURGE_INTERVAL"`
+	IdempotencyKeyRetention  time.Duration `mapstructure:"IDEMPOTENCY_KEY_RETENTION"`
+	SchedulerInterval        time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+	SchedulerRetryDelay      time.Duration `mapstructure:"SCHEDULER_RETRY_DELAY"`
+	HoldDuration             time.Duration `mapstructure:"HOLD_DURATION"`
+	InterestInterval         time.Duration `mapstructure:"INTEREST_INTERVAL"`
+	SnapshotInterval         time.Duration `mapstructure:"SNAPSHOT_INTERVAL"`
+	OutboxInterval           time.Duration `mapstructure:"OUTBOX_INTERVAL"`
+	// stdout or file, events are appended to OutboxFile with the file publisher
+	OutboxPublisher string        `mapstructure:"OUTBOX_PUBLISHER"`
+	OutboxFile      string        `mapstructure:"OUTBOX_FILE"`
+	WebhookInterval time.Duration `mapstructure:"WEBHOOK_INTERVAL"`
+	WebhookTimeout  time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	// failed deliveries are retried after the delay doubled on every attempt, until max attempts are made
+	WebhookRetryDelay  time.Duration `mapstructure:"WEBHOOK_RETRY_DELAY"`
+	WebhookMaxAttempts int32         `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	// transfers above the threshold of their currency wait for approval of another user, given as USD=1000000,EUR=1000000
+	// currencies without a threshold or with zero one never need approval
+	ApprovalThresholds map[string]int64 `mapstructure:"APPROVAL_THRESHOLDS"`
+}
+
+// IsDevelopment returns true if the application runs in a local setup
+func (config Config) IsDevelopment() bool {
+	return config.Environment == DevelopmentEnvironment
+}
+
+// RequiresApproval returns true if a transfer of the amount in the currency has to wait for approval of another user
+func (config Config) RequiresApproval(amount int64, currency string) bool {
+	threshold := config.ApprovalThresholds[currency]
+	return threshold > 0 && amount > threshold
+}
+
+// stringToAmountsHookFunc decodes a list of CURRENCY=AMOUNT pairs separated by commas into amounts by currency
+func stringToAmountsHookFunc() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String || to != reflect.TypeOf(map[string]int64{}) {
+			return data, nil
+		}
+
+		amounts := map[string]int64{}
+
+		for _, pair := range strings.Split(data.(string), ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+
+			currency, rawAmount, found := strings.Cut(pair, "=")
+			if !found {
+				return nil, fmt.Errorf("invalid currency amount %q, expected CURRENCY=AMOUNT", pair)
+			}
+
+			amount, err := strconv.ParseInt(strings.TrimSpace(rawAmount), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount of %s: %w", currency, err)
+			}
+
+			amounts[strings.TrimSpace(currency)] = amount
+		}
+
+		return amounts, nil
+	}
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -27,7 +99,11 @@ func LoadConfig(path string) (config Config, err error) {
 		return
 	}
 
-	err = viper.Unmarshal(&config)
+	err = viper.Unmarshal(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		stringToAmountsHookFunc(),
+	)))
 	if err != nil {
 		return
 	}
@@ -0,0 +1,37 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigApprovalThresholds(t *testing.T) {
+	dir := t.TempDir()
+
+	env := "ACCESS_TOKEN_DURATION=15m\nAPPROVAL_THRESHOLDS=USD=1000000, EUR=900000,RUB=0\n"
+	err := os.WriteFile(filepath.Join(dir, "app.env"), []byte(env), 0o600)
+	require.NoError(t, err)
+
+	config, err := LoadConfig(dir)
+	require.NoError(t, err)
+	require.Equal(t, 15*time.Minute, config.AccessTokenDuration)
+	require.Equal(t, map[string]int64{USD: 1000000, EUR: 900000, RUB: 0}, config.ApprovalThresholds)
+}
+
+func TestRequiresApproval(t *testing.T) {
+	config := Config{ApprovalThresholds: map[string]int64{USD: 1000, EUR: 2000, RUB: 0}}
+
+	require.False(t, config.RequiresApproval(1000, USD))
+	require.True(t, config.RequiresApproval(1001, USD))
+
+	//the same amount is under the threshold of another currency
+	require.False(t, config.RequiresApproval(1001, EUR))
+
+	//zero and missing thresholds turn approvals off for the currency
+	require.False(t, config.RequiresApproval(1000000, RUB))
+	require.False(t, config.RequiresApproval(1000000, "XYZ"))
+}
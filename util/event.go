@@ -0,0 +1,24 @@
+package util
+
+// Constants for all types of aggregates events are written to the outbox for
+const (
+	TransferAggregate = "transfer"
+	AccountAggregate  = "account"
+)
+
+// Constants for all types of events written to the outbox
+const (
+	TransferCreatedEvent      = "transfer.created"
+	AccountCreatedEvent       = "account.created"
+	AccountStatusChangedEvent = "account.status_changed"
+	AccountAdjustedEvent      = "account.adjusted"
+)
+
+// IsSupportedEventType returns true if events of the type are written to the outbox and false or not
+func IsSupportedEventType(eventType string) bool {
+	switch eventType {
+	case TransferCreatedEvent, AccountCreatedEvent, AccountStatusChangedEvent, AccountAdjustedEvent:
+		return true
+	}
+	return false
+}
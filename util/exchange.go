@@ -0,0 +1,128 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// Different types of error returned by currency conversion functions
+var (
+	ErrInvalidExchangeRate = errors.New("exchange rate must be a positive decimal number")
+	ErrAmountOverflow      = errors.New("amount is out of range")
+)
+
+var exchangeRateRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ParseExchangeRate parses a positive decimal exchange rate like "0.9215"
+func ParseExchangeRate(rate string) (*big.Rat, error) {
+	if !exchangeRateRegexp.MatchString(rate) {
+		return nil, ErrInvalidExchangeRate
+	}
+
+	value, ok := new(big.Rat).SetString(rate)
+	if !ok || value.Sign() <= 0 {
+		return nil, ErrInvalidExchangeRate
+	}
+
+	return value, nil
+}
+
+// ConvertAmount multiplies amount by the exchange rate and rounds the result half to even
+// It returns converted amount and rounding which is the exact result minus the converted amount
+func ConvertAmount(amount int64, rate string) (converted int64, rounding string, err error) {
+	value, err := ParseExchangeRate(rate)
+	if err != nil {
+		return
+	}
+
+	exact := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), value)
+
+	quo := roundHalfToEven(exact)
+	if !quo.IsInt64() {
+		err = fmt.Errorf("cannot convert %d with rate %s: %w", amount, rate, ErrAmountOverflow)
+		return
+	}
+
+	converted = quo.Int64()
+	rounding = new(big.Rat).Sub(exact, new(big.Rat).SetInt(quo)).FloatString(rateScale(rate))
+
+	return
+}
+
+// ConversionRounding returns the exact result of amount multiplied by the rate minus the converted amount
+func ConversionRounding(amount int64, rate string, converted int64) (string, error) {
+	value, err := ParseExchangeRate(rate)
+	if err != nil {
+		return "", err
+	}
+
+	exact := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), value)
+
+	return new(big.Rat).Sub(exact, new(big.Rat).SetInt64(converted)).FloatString(rateScale(rate)), nil
+}
+
+// invertedRateScale is the number of digits kept after decimal point of an inverted exchange rate
+const invertedRateScale = 10
+
+// InvertExchangeRate returns the rate of the opposite direction rounded half to even
+func InvertExchangeRate(rate string) (string, error) {
+	value, err := ParseExchangeRate(rate)
+	if err != nil {
+		return "", err
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(invertedRateScale), nil)
+	scaled := roundHalfToEven(new(big.Rat).Mul(new(big.Rat).Inv(value), new(big.Rat).SetInt(scale)))
+
+	if scaled.Sign() <= 0 {
+		return "", ErrInvalidExchangeRate
+	}
+
+	return new(big.Rat).SetFrac(scaled, scale).FloatString(invertedRateScale), nil
+}
+
+// ProportionalAmount returns amount multiplied by part and divided by whole rounded half to even
+func ProportionalAmount(amount int64, part int64, whole int64) (int64, error) {
+	if whole == 0 {
+		return 0, ErrAmountOverflow
+	}
+
+	quo := roundHalfToEven(new(big.Rat).SetFrac(
+		new(big.Int).Mul(big.NewInt(amount), big.NewInt(part)),
+		big.NewInt(whole),
+	))
+
+	if !quo.IsInt64() {
+		return 0, fmt.Errorf("cannot take %d/%d of %d: %w", part, whole, amount, ErrAmountOverflow)
+	}
+
+	return quo.Int64(), nil
+}
+
+// roundHalfToEven rounds the exact value to the nearest integer, halves go to the even one
+func roundHalfToEven(exact *big.Rat) *big.Int {
+	quo, rem := new(big.Int).QuoRem(exact.Num(), exact.Denom(), new(big.Int))
+
+	//rem has the same sign as the value, so compare absolute values to round half to even
+	switch new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(exact.Denom()) {
+	case 1:
+		quo.Add(quo, big.NewInt(int64(rem.Sign())))
+	case 0:
+		if quo.Bit(0) == 1 {
+			quo.Add(quo, big.NewInt(int64(rem.Sign())))
+		}
+	}
+
+	return quo
+}
+
+// rateScale returns the number of digits after decimal point of the rate
+func rateScale(rate string) int {
+	if i := strings.IndexByte(rate, '.'); i >= 0 {
+		return len(rate) - i - 1
+	}
+	return 0
+}
@@ -0,0 +1,90 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAmount(t *testing.T) {
+	testCases := []struct {
+		name      string
+		amount    int64
+		rate      string
+		converted int64
+		rounding  string
+	}{
+		{name: "Exact", amount: 1000, rate: "0.5", converted: 500, rounding: "0.0"},
+		{name: "RoundDown", amount: 1000, rate: "0.92141", converted: 921, rounding: "0.41000"},
+		{name: "RoundUp", amount: 1000, rate: "0.92161", converted: 922, rounding: "-0.39000"},
+		{name: "HalfToEvenUp", amount: 1000, rate: "0.9215", converted: 922, rounding: "-0.5000"},
+		{name: "HalfToEvenDown", amount: 1000, rate: "0.9225", converted: 922, rounding: "0.5000"},
+		{name: "IntegerRate", amount: 7, rate: "90", converted: 630, rounding: "0"},
+		{name: "TooSmall", amount: 1, rate: "0.01", converted: 0, rounding: "0.01"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			converted, rounding, err := ConvertAmount(tc.amount, tc.rate)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.converted, converted)
+			require.Equal(t, tc.rounding, rounding)
+		})
+	}
+}
+
+func TestConvertAmountInvalidRate(t *testing.T) {
+	for _, rate := range []string{"", "0", "0.000", "-1.5", "1e3", "abc", "1.", ".5"} {
+		_, _, err := ConvertAmount(1000, rate)
+		require.ErrorIs(t, err, ErrInvalidExchangeRate, rate)
+	}
+}
+
+func TestConvertAmountOverflow(t *testing.T) {
+	_, _, err := ConvertAmount(math.MaxInt64, "2")
+	require.ErrorIs(t, err, ErrAmountOverflow)
+}
+
+func TestInvertExchangeRate(t *testing.T) {
+	inverted, err := InvertExchangeRate("0.9215")
+	require.NoError(t, err)
+	require.Equal(t, "1.0851871948", inverted)
+
+	inverted, err = InvertExchangeRate("0.5")
+	require.NoError(t, err)
+	require.Equal(t, "2.0000000000", inverted)
+
+	_, err = InvertExchangeRate("0")
+	require.ErrorIs(t, err, ErrInvalidExchangeRate)
+}
+
+func TestProportionalAmount(t *testing.T) {
+	testCases := []struct {
+		name   string
+		amount int64
+		part   int64
+		whole  int64
+		result int64
+	}{
+		{name: "Whole", amount: 922, part: 1000, whole: 1000, result: 922},
+		{name: "Half", amount: 922, part: 500, whole: 1000, result: 461},
+		{name: "RoundDown", amount: 922, part: 333, whole: 1000, result: 307},
+		{name: "HalfToEven", amount: 5, part: 1, whole: 2, result: 2},
+		{name: "Nothing", amount: 922, part: 0, whole: 1000, result: 0},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ProportionalAmount(tc.amount, tc.part, tc.whole)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
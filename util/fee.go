@@ -0,0 +1,58 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// FeeIncomeOwner owns the system accounts which receive transfer fees
+const FeeIncomeOwner = SystemOwnerPrefix + "fee_income"
+
+// ErrInvalidFeePercent is returned when a fee percent is not a decimal number between 0 and 100
+var ErrInvalidFeePercent = errors.New("fee percent must be a decimal number between 0 and 100")
+
+// ParseFeePercent parses a fee percent like "1.5", which must not be below 0 or above 100
+func ParseFeePercent(percent string) (*big.Rat, error) {
+	if !decimalRegexp.MatchString(percent) {
+		return nil, ErrInvalidFeePercent
+	}
+
+	value, ok := new(big.Rat).SetString(percent)
+	if !ok || value.Cmp(big.NewRat(100, 1)) > 0 {
+		return nil, ErrInvalidFeePercent
+	}
+
+	return value, nil
+}
+
+// TransferFee returns the fee of a transfer: the flat fee plus percent of the amount rounded half to even,
+// raised to minFee and capped at maxFee unless it is 0
+func TransferFee(amount int64, flat int64, percent string, minFee int64, maxFee int64) (int64, error) {
+	value, err := ParseFeePercent(percent)
+	if err != nil {
+		return 0, err
+	}
+
+	exact := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), value)
+	exact.Quo(exact, big.NewRat(100, 1))
+
+	fee := roundHalfToEven(exact)
+	fee.Add(fee, big.NewInt(flat))
+
+	if !fee.IsInt64() {
+		return 0, fmt.Errorf("cannot compute fee of %d: %w", amount, ErrAmountOverflow)
+	}
+
+	result := fee.Int64()
+
+	if result < minFee {
+		result = minFee
+	}
+
+	if maxFee > 0 && result > maxFee {
+		result = maxFee
+	}
+
+	return result, nil
+}
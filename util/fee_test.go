@@ -0,0 +1,47 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferFee(t *testing.T) {
+	testCases := []struct {
+		name    string
+		amount  int64
+		flat    int64
+		percent string
+		minFee  int64
+		maxFee  int64
+		fee     int64
+	}{
+		{name: "None", amount: 1000, percent: "0", fee: 0},
+		{name: "Flat", amount: 1000, flat: 25, percent: "0", fee: 25},
+		{name: "Percent", amount: 1000, percent: "1.5", fee: 15},
+		{name: "FlatAndPercent", amount: 1000, flat: 5, percent: "2", fee: 25},
+		{name: "HalfToEven", amount: 250, percent: "1", fee: 2},
+		{name: "HalfToEvenUp", amount: 350, percent: "1", fee: 4},
+		{name: "Minimum", amount: 100, percent: "1", minFee: 10, fee: 10},
+		{name: "Maximum", amount: 100000, percent: "1", maxFee: 500, fee: 500},
+		{name: "NoMaximum", amount: 100000, percent: "1", fee: 1000},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			fee, err := TransferFee(tc.amount, tc.flat, tc.percent, tc.minFee, tc.maxFee)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.fee, fee)
+		})
+	}
+}
+
+func TestTransferFeeInvalidPercent(t *testing.T) {
+	for _, percent := range []string{"", "-1", "100.5", "abc", ".5"} {
+		_, err := TransferFee(1000, 0, percent, 0, 0)
+		require.ErrorIs(t, err, ErrInvalidFeePercent, percent)
+	}
+}
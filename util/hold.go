@@ -0,0 +1,9 @@
+package util
+
+// Constants for all statuses of holds
+const (
+	ActiveHold   = "active"
+	CapturedHold = "captured"
+	ReleasedHold = "released"
+	ExpiredHold  = "expired"
+)
@@ -0,0 +1,125 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+)
+
+// Constants for all compounding periods of interest
+const (
+	DailyCompounding   = "daily"
+	MonthlyCompounding = "monthly"
+)
+
+// InterestExpenseOwner owns the system accounts which pay interest out
+const InterestExpenseOwner = SystemOwnerPrefix + "interest_expense"
+
+// Different types of error returned by interest functions
+var (
+	ErrInvalidInterestRate = errors.New("interest rate must be a decimal fraction between 0 and 1")
+	ErrInvalidAccrued      = errors.New("accrued interest must be a non-negative decimal number")
+)
+
+// daysInYear is the day count basis of interest, every day earns 1/365 of the annual rate
+const daysInYear = 365
+
+// accruedScale is the number of digits after decimal point kept of accrued interest
+const accruedScale = 10
+
+var decimalRegexp = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// IsSupportedCompounding returns true if the compounding period is supported
+func IsSupportedCompounding(compounding string) bool {
+	switch compounding {
+	case DailyCompounding, MonthlyCompounding:
+		return true
+	}
+	return false
+}
+
+// ParseInterestRate parses an annual interest rate like "0.035", which must be above 0 and not above 1
+func ParseInterestRate(rate string) (*big.Rat, error) {
+	if !decimalRegexp.MatchString(rate) {
+		return nil, ErrInvalidInterestRate
+	}
+
+	value, ok := new(big.Rat).SetString(rate)
+	if !ok || value.Sign() <= 0 || value.Cmp(big.NewRat(1, 1)) > 0 {
+		return nil, ErrInvalidInterestRate
+	}
+
+	return value, nil
+}
+
+// parseAccrued parses accrued interest kept as a decimal string
+func parseAccrued(accrued string) (*big.Rat, error) {
+	if !decimalRegexp.MatchString(accrued) {
+		return nil, ErrInvalidAccrued
+	}
+
+	value, ok := new(big.Rat).SetString(accrued)
+	if !ok {
+		return nil, ErrInvalidAccrued
+	}
+
+	return value, nil
+}
+
+// AccrueDailyInterest returns interest of one day and the accrued interest including it
+// With daily compounding interest is earned on the balance plus interest accrued but not posted yet,
+// with monthly compounding only on the balance. Nothing is earned on a balance which is not positive.
+// Interest of the day is rounded half to even to accruedScale digits, so the accrued amount never loses more than that
+func AccrueDailyInterest(balance int64, accrued string, annualRate string, compounding string) (interest string, newAccrued string, err error) {
+	rate, err := ParseInterestRate(annualRate)
+	if err != nil {
+		return
+	}
+
+	accruedValue, err := parseAccrued(accrued)
+	if err != nil {
+		return
+	}
+
+	basis := new(big.Rat).SetInt64(balance)
+	if compounding == DailyCompounding {
+		basis.Add(basis, accruedValue)
+	}
+
+	dayInterest := new(big.Rat)
+	if basis.Sign() > 0 {
+		scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(accruedScale), nil)
+
+		exact := new(big.Rat).Mul(basis, rate)
+		exact.Quo(exact, big.NewRat(daysInYear, 1))
+		exact.Mul(exact, new(big.Rat).SetInt(scale))
+
+		dayInterest.SetFrac(roundHalfToEven(exact), scale)
+	}
+
+	interest = dayInterest.FloatString(accruedScale)
+	newAccrued = new(big.Rat).Add(accruedValue, dayInterest).FloatString(accruedScale)
+
+	return
+}
+
+// SplitAccruedInterest splits accrued interest into the whole amount which can be posted and the fraction which is kept
+func SplitAccruedInterest(accrued string) (whole int64, rest string, err error) {
+	value, err := parseAccrued(accrued)
+	if err != nil {
+		return
+	}
+
+	//accrued interest is never negative, so integer division rounds down
+	quo := new(big.Int).Quo(value.Num(), value.Denom())
+	if !quo.IsInt64() {
+		err = fmt.Errorf("cannot post accrued interest %s: %w", accrued, ErrAmountOverflow)
+		return
+	}
+
+	whole = quo.Int64()
+	rest = new(big.Rat).Sub(value, new(big.Rat).SetInt(quo)).FloatString(accruedScale)
+
+	return
+}
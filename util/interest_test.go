@@ -0,0 +1,58 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccrueDailyInterest(t *testing.T) {
+	testCases := []struct {
+		name        string
+		balance     int64
+		accrued     string
+		compounding string
+		interest    string
+		newAccrued  string
+	}{
+		{name: "Monthly", balance: 36500, accrued: "0.5", compounding: MonthlyCompounding, interest: "10.0000000000", newAccrued: "10.5000000000"},
+		{name: "Daily", balance: 36500, accrued: "0.5", compounding: DailyCompounding, interest: "10.0001369863", newAccrued: "10.5001369863"},
+		{name: "Fraction", balance: 1, accrued: "0", compounding: MonthlyCompounding, interest: "0.0002739726", newAccrued: "0.0002739726"},
+		{name: "ZeroBalance", balance: 0, accrued: "1.25", compounding: MonthlyCompounding, interest: "0.0000000000", newAccrued: "1.2500000000"},
+		{name: "NegativeBalance", balance: -100, accrued: "0", compounding: DailyCompounding, interest: "0.0000000000", newAccrued: "0.0000000000"},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			interest, newAccrued, err := AccrueDailyInterest(tc.balance, tc.accrued, "0.1", tc.compounding)
+
+			require.NoError(t, err)
+			require.Equal(t, tc.interest, interest)
+			require.Equal(t, tc.newAccrued, newAccrued)
+		})
+	}
+}
+
+func TestAccrueDailyInterestInvalid(t *testing.T) {
+	for _, rate := range []string{"", "0", "1.5", "-0.1", "abc", ".5"} {
+		_, _, err := AccrueDailyInterest(1000, "0", rate, DailyCompounding)
+		require.ErrorIs(t, err, ErrInvalidInterestRate, rate)
+	}
+
+	_, _, err := AccrueDailyInterest(1000, "-1", "0.1", DailyCompounding)
+	require.ErrorIs(t, err, ErrInvalidAccrued)
+}
+
+func TestSplitAccruedInterest(t *testing.T) {
+	whole, rest, err := SplitAccruedInterest("10.5001369863")
+	require.NoError(t, err)
+	require.Equal(t, int64(10), whole)
+	require.Equal(t, "0.5001369863", rest)
+
+	whole, rest, err = SplitAccruedInterest("0")
+	require.NoError(t, err)
+	require.Zero(t, whole)
+	require.Equal(t, "0.0000000000", rest)
+}
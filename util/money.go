@@ -0,0 +1,140 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Different types of error returned by money functions
+var (
+	ErrCurrencyMismatch    = errors.New("currency mismatch")
+	ErrUnsupportedCurrency = errors.New("currency is not supported")
+	ErrInvalidMoney        = errors.New("amount must be a decimal number with at most as many fraction digits as the currency has")
+)
+
+// minorUnitExponents are the numbers of digits after decimal point of amounts in every supported currency
+var minorUnitExponents = map[string]int{
+	USD: 2,
+	EUR: 2,
+	RUB: 2,
+}
+
+var moneyRegexp = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Money is an amount in minor units of the currency, like cents of USD
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// NewMoney returns money of the amount in minor units of the currency
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// MinorUnitExponent returns the number of digits after decimal point of amounts in the currency
+func MinorUnitExponent(currency string) (int, error) {
+	exponent, ok := minorUnitExponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	return exponent, nil
+}
+
+// ParseMoney parses a decimal amount in major units like "12.34" into money of the currency
+// Fraction digits beyond the minor unit of the currency are rejected instead of rounded
+func ParseMoney(value string, currency string) (Money, error) {
+	exponent, err := MinorUnitExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	if !moneyRegexp.MatchString(value) {
+		return Money{}, ErrInvalidMoney
+	}
+
+	negative := strings.HasPrefix(value, "-")
+	whole, fraction, _ := strings.Cut(strings.TrimPrefix(value, "-"), ".")
+
+	if len(fraction) > exponent {
+		return Money{}, ErrInvalidMoney
+	}
+
+	//pad the fraction to the minor unit, so "12.3" becomes 1230 cents
+	digits := whole + fraction + strings.Repeat("0", exponent-len(fraction))
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("cannot parse %s: %w", value, ErrAmountOverflow)
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return NewMoney(amount, currency), nil
+}
+
+// Decimal formats the amount in major units with all digits of the minor unit, like "12.30"
+func (m Money) Decimal() string {
+	exponent, ok := minorUnitExponents[m.Currency]
+	if !ok || exponent == 0 {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+
+	//format the absolute value through uint64, so the smallest int64 doesn't overflow
+	sign := ""
+	abs := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign = "-"
+		abs = -abs
+	}
+
+	digits := strconv.FormatUint(abs, 10)
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+
+	point := len(digits) - exponent
+
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// String formats money with its currency, like "12.30 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// Add returns the sum of two amounts of the same currency
+// It returns ErrCurrencyMismatch for different currencies and ErrAmountOverflow if the sum doesn't fit into int64
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, fmt.Errorf("cannot add %s to %s: %w", other, m, ErrAmountOverflow)
+	}
+
+	return NewMoney(m.Amount+other.Amount, m.Currency), nil
+}
+
+// Sub returns the difference of two amounts of the same currency
+// It returns ErrCurrencyMismatch for different currencies and ErrAmountOverflow if the difference doesn't fit into int64
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s vs %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+
+	if (other.Amount < 0 && m.Amount > math.MaxInt64+other.Amount) ||
+		(other.Amount > 0 && m.Amount < math.MinInt64+other.Amount) {
+		return Money{}, fmt.Errorf("cannot subtract %s from %s: %w", other, m, ErrAmountOverflow)
+	}
+
+	return NewMoney(m.Amount-other.Amount, m.Currency), nil
+}
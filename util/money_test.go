@@ -0,0 +1,87 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		value  string
+		amount int64
+	}{
+		{value: "12.34", amount: 1234},
+		{value: "12.3", amount: 1230},
+		{value: "12", amount: 1200},
+		{value: "0.05", amount: 5},
+		{value: "-1.50", amount: -150},
+		{value: "92233720368547758.07", amount: math.MaxInt64},
+	}
+
+	for _, tc := range testCases {
+		money, err := ParseMoney(tc.value, USD)
+		require.NoError(t, err, tc.value)
+		require.Equal(t, NewMoney(tc.amount, USD), money, tc.value)
+	}
+}
+
+func TestParseMoneyInvalid(t *testing.T) {
+	for _, value := range []string{"", "abc", "1.234", ".5", "1.", "1,50", "+1"} {
+		_, err := ParseMoney(value, USD)
+		require.ErrorIs(t, err, ErrInvalidMoney, value)
+	}
+
+	_, err := ParseMoney("92233720368547758.08", USD)
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	_, err = ParseMoney("1.00", "XYZ")
+	require.ErrorIs(t, err, ErrUnsupportedCurrency)
+}
+
+func TestMoneyDecimal(t *testing.T) {
+	require.Equal(t, "12.34", NewMoney(1234, USD).Decimal())
+	require.Equal(t, "0.05", NewMoney(5, EUR).Decimal())
+	require.Equal(t, "0.00", NewMoney(0, RUB).Decimal())
+	require.Equal(t, "-12.30", NewMoney(-1230, USD).Decimal())
+	require.Equal(t, "-92233720368547758.08", NewMoney(math.MinInt64, USD).Decimal())
+	require.Equal(t, "12.34 USD", NewMoney(1234, USD).String())
+
+	//decimal string is parsed back into the same money
+	money, err := ParseMoney(NewMoney(-987654321, EUR).Decimal(), EUR)
+	require.NoError(t, err)
+	require.Equal(t, int64(-987654321), money.Amount)
+}
+
+func TestMoneyAddSub(t *testing.T) {
+	sum, err := NewMoney(150, USD).Add(NewMoney(25, USD))
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(175, USD), sum)
+
+	diff, err := NewMoney(150, USD).Sub(NewMoney(200, USD))
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(-50, USD), diff)
+
+	_, err = NewMoney(150, USD).Add(NewMoney(25, EUR))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = NewMoney(150, USD).Sub(NewMoney(25, EUR))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = NewMoney(math.MaxInt64, USD).Add(NewMoney(1, USD))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	_, err = NewMoney(math.MinInt64, USD).Add(NewMoney(-1, USD))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	_, err = NewMoney(math.MinInt64, USD).Sub(NewMoney(1, USD))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	_, err = NewMoney(0, USD).Sub(NewMoney(math.MinInt64, USD))
+	require.ErrorIs(t, err, ErrAmountOverflow)
+
+	diff, err = NewMoney(-1, USD).Sub(NewMoney(math.MaxInt64, USD))
+	require.NoError(t, err)
+	require.Equal(t, int64(math.MinInt64), diff.Amount)
+}
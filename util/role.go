@@ -0,0 +1,7 @@
+package util
+
+// Constants for all user roles
+const (
+	DepositorRole = "depositor"
+	AdminRole     = "admin"
+)
@@ -0,0 +1,65 @@
+package util
+
+import "time"
+
+// Constants for all supported recurrences of scheduled transfers, empty recurrence means one-shot transfer
+const (
+	DailyRecurrence   = "@daily"
+	WeeklyRecurrence  = "@weekly"
+	MonthlyRecurrence = "@monthly"
+)
+
+// Constants for all statuses of scheduled transfers
+const (
+	ActiveSchedule    = "active"
+	CompletedSchedule = "completed"
+	FailedSchedule    = "failed"
+	CancelledSchedule = "cancelled"
+)
+
+// Constants for all outcomes of scheduled transfer runs
+const (
+	SucceededRun = "succeeded"
+	FailedRun    = "failed"
+	SkippedRun   = "skipped"
+)
+
+// IsSupportedRecurrence returns true if the recurrence is supported and false or not
+func IsSupportedRecurrence(recurrence string) bool {
+	switch recurrence {
+	case DailyRecurrence, WeeklyRecurrence, MonthlyRecurrence:
+		return true
+	}
+	return false
+}
+
+// NextOccurrence returns the time of the occurrence following t, it returns false for one-shot transfer
+// Monthly occurrences fall on anchorDay of the month in the location of t, shorter months use their last day
+// The anchor keeps a schedule on the 31st from sticking to the 28th after February, zero anchors on the day of t
+func NextOccurrence(recurrence string, t time.Time, anchorDay int) (time.Time, bool) {
+	switch recurrence {
+	case DailyRecurrence:
+		return t.AddDate(0, 0, 1), true
+	case WeeklyRecurrence:
+		return t.AddDate(0, 0, 7), true
+	case MonthlyRecurrence:
+		year, month, day := t.Date()
+		if anchorDay > 0 {
+			day = anchorDay
+		}
+
+		//day 0 of the month after next is the last day of the next month
+		lastDay := time.Date(year, month+2, 0, 0, 0, 0, 0, t.Location()).Day()
+		if day > lastDay {
+			day = lastDay
+		}
+
+		return time.Date(year, month+1, day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()), true
+	}
+	return time.Time{}, false
+}
+
+// AnchorDay returns the day of month in UTC monthly occurrences of a schedule starting at t fall on
+func AnchorDay(t time.Time) int32 {
+	return int32(t.UTC().Day())
+}
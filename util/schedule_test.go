@@ -0,0 +1,71 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextOccurrence(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	testCases := []struct {
+		name       string
+		recurrence string
+		from       time.Time
+		next       time.Time
+	}{
+		{name: "Daily", recurrence: DailyRecurrence, from: date(2023, time.December, 31), next: date(2024, time.January, 1)},
+		{name: "Weekly", recurrence: WeeklyRecurrence, from: date(2024, time.February, 26), next: date(2024, time.March, 4)},
+		{name: "Monthly", recurrence: MonthlyRecurrence, from: date(2024, time.January, 1), next: date(2024, time.February, 1)},
+		{name: "MonthlyEndOfYear", recurrence: MonthlyRecurrence, from: date(2023, time.December, 15), next: date(2024, time.January, 15)},
+		{name: "MonthlyShorterMonth", recurrence: MonthlyRecurrence, from: date(2024, time.January, 31), next: date(2024, time.February, 29)},
+		{name: "MonthlyNonLeapYear", recurrence: MonthlyRecurrence, from: date(2023, time.January, 30), next: date(2023, time.February, 28)},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			next, ok := NextOccurrence(tc.recurrence, tc.from, 0)
+
+			require.True(t, ok)
+			require.Equal(t, tc.next, next)
+		})
+	}
+}
+
+func TestNextOccurrenceAnchorDay(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2023, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	//the schedule returns to the 31st after February instead of sticking to the 28th
+	occurrences := []time.Time{
+		date(time.January, 31),
+		date(time.February, 28),
+		date(time.March, 31),
+		date(time.April, 30),
+		date(time.May, 31),
+	}
+
+	anchorDay := int(AnchorDay(occurrences[0]))
+	require.Equal(t, 31, anchorDay)
+
+	for i := 1; i < len(occurrences); i++ {
+		next, ok := NextOccurrence(MonthlyRecurrence, occurrences[i-1], anchorDay)
+
+		require.True(t, ok)
+		require.Equal(t, occurrences[i], next)
+	}
+}
+
+func TestNextOccurrenceOneShot(t *testing.T) {
+	next, ok := NextOccurrence("", time.Now(), 0)
+
+	require.False(t, ok)
+	require.True(t, next.IsZero())
+}
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// WebhookTestEvent is the type of deliveries sent on request of the owner to check the receiver
+const WebhookTestEvent = "webhook.test"
+
+// Constants for all statuses of webhook deliveries
+const (
+	PendingDelivery   = "pending"
+	SucceededDelivery = "succeeded"
+	FailedDelivery    = "failed"
+)
+
+// Headers sent with every webhook delivery
+const (
+	WebhookEventHeader     = "X-Simplebank-Event"
+	WebhookDeliveryHeader  = "X-Simplebank-Delivery"
+	WebhookTimestampHeader = "X-Simplebank-Timestamp"
+	WebhookSignatureHeader = "X-Simplebank-Signature"
+)
+
+// webhookSignaturePrefix names the algorithm of the signature
+const webhookSignaturePrefix = "sha256="
+
+// NewWebhookSecret generates a random secret the deliveries of a webhook are signed with
+func NewWebhookSecret() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return "whsec_" + hex.EncodeToString(key), nil
+}
+
+// SignWebhook returns the HMAC-SHA256 signature of the timestamp and the body joined by a dot
+// The timestamp is signed too, so receivers can reject replays of old deliveries
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyWebhookSignature returns true if the signature was made with the secret for the timestamp and the body
+func VerifyWebhookSignature(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(SignWebhook(secret, timestamp, body)), []byte(signature))
+}
+
+// ErrWebhookAddressNotAllowed is returned for webhook hosts which are not reachable from the internet
+var ErrWebhookAddressNotAllowed = errors.New("webhook address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not public either
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// IsPublicWebhookIP returns true if webhooks may be sent to the address
+// Loopback, link-local, private, unspecified and multicast addresses are refused, so a webhook cannot reach internal services
+func IsPublicWebhookIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsPrivate() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// ValidateWebhookURL returns an error if webhooks cannot be registered with the URL
+// Only absolute https URLs are accepted, plain http too if allowHTTP is true,
+// hosts given as addresses must be public and localhost names are refused, other names are checked when deliveries dial them
+func ValidateWebhookURL(rawURL string, allowHTTP bool) error {
+	webhookURL, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if webhookURL.Scheme != "https" && !(allowHTTP && webhookURL.Scheme == "http") {
+		return fmt.Errorf("webhook url scheme %q is not allowed", webhookURL.Scheme)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(webhookURL.Hostname()), ".")
+	if host == "" {
+		return errors.New("webhook url must be absolute")
+	}
+
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrWebhookAddressNotAllowed, host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil && !IsPublicWebhookIP(ip) {
+		return fmt.Errorf("%w: %s", ErrWebhookAddressNotAllowed, host)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhook(t *testing.T) {
+	secret, err := NewWebhookSecret()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	body := []byte(`{"event_type":"transfer.created"}`)
+	timestamp := int64(1700000000)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("1700000000." + string(body)))
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	signature := SignWebhook(secret, timestamp, body)
+	require.Equal(t, expected, signature)
+
+	require.True(t, VerifyWebhookSignature(secret, timestamp, body, signature))
+	require.False(t, VerifyWebhookSignature(secret, timestamp+1, body, signature))
+	require.False(t, VerifyWebhookSignature(secret, timestamp, []byte(`{}`), signature))
+	require.False(t, VerifyWebhookSignature("whsec_other", timestamp, body, signature))
+
+	other, err := NewWebhookSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret, other)
+}
+
+func TestIsPublicWebhookIP(t *testing.T) {
+	for _, address := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		require.True(t, IsPublicWebhookIP(net.ParseIP(address)), address)
+	}
+
+	for _, address := range []string{
+		"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "::", "fe80::1", "fc00::1", "224.0.0.1", "::ffff:127.0.0.1",
+	} {
+		require.False(t, IsPublicWebhookIP(net.ParseIP(address)), address)
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	require.NoError(t, ValidateWebhookURL("https://example.com/hooks", false))
+	require.NoError(t, ValidateWebhookURL("https://93.184.216.34/hooks", false))
+	require.NoError(t, ValidateWebhookURL("http://example.com/hooks", true))
+
+	require.Error(t, ValidateWebhookURL("http://example.com/hooks", false))
+	require.Error(t, ValidateWebhookURL("ftp://example.com/hooks", true))
+	require.Error(t, ValidateWebhookURL("/hooks", true))
+	require.Error(t, ValidateWebhookURL("https:///hooks", false))
+
+	for _, rawURL := range []string{
+		"https://localhost/hooks",
+		"https://LOCALHOST./hooks",
+		"https://api.localhost/hooks",
+		"https://127.0.0.1:8080/hooks",
+		"https://[::1]/hooks",
+		"https://169.254.169.254/latest/meta-data",
+		"https://10.0.0.5/hooks",
+	} {
+		require.ErrorIs(t, ValidateWebhookURL(rawURL, true), ErrWebhookAddressNotAllowed, rawURL)
+	}
+}
@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/lib/pq"
+)
+
+// accountActivityChannel is the channel the database notifies with IDs of accounts which entries, balance or holds changed
+const accountActivityChannel = "account_activity"
+
+// accountListenerPing is how often an idle connection of the listener is checked
+const accountListenerPing = 90 * time.Second
+
+// AccountNotifier is notified about activity of accounts, like the broker of account event streams
+type AccountNotifier interface {
+	Notify(accountID int64)
+	NotifyAll()
+}
+
+// AccountListener listens to notifications the database sends when activity of an account is committed
+// and passes them to the notifier, so every server instance learns about changes made by any of them
+type AccountListener struct {
+	config   util.Config
+	notifier AccountNotifier
+}
+
+// NewAccountListener creates a new account listener
+func NewAccountListener(config util.Config, notifier AccountNotifier) *AccountListener {
+	return &AccountListener{
+		config:   config,
+		notifier: notifier,
+	}
+}
+
+// Start listens to notifications until the context is done, the connection is re-established when it breaks
+func (listener *AccountListener) Start(ctx context.Context) {
+	pqListener := pq.NewListener(listener.config.DBSource, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Println("account listener:", err)
+		}
+	})
+	defer pqListener.Close()
+
+	if err := pqListener.Listen(accountActivityChannel); err != nil {
+		log.Println("cannot listen to account activity:", err)
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification := <-pqListener.Notify:
+			listener.handle(notification)
+		case <-time.After(accountListenerPing):
+			go pqListener.Ping()
+		}
+	}
+}
+
+// handle notifies about the account of the notification
+// A nil notification follows a reconnect, notifications may have been lost then, so every account is notified
+func (listener *AccountListener) handle(notification *pq.Notification) {
+	if notification == nil {
+		listener.notifier.NotifyAll()
+		return
+	}
+
+	accountID, err := strconv.ParseInt(notification.Extra, 10, 64)
+	if err != nil {
+		log.Printf("invalid account activity notification %q", notification.Extra)
+		return
+	}
+
+	listener.notifier.Notify(accountID)
+}
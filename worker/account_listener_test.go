@@ -0,0 +1,42 @@
+package worker
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier records the accounts it is notified about, -1 stands for all of them
+type recordingNotifier struct {
+	mutex    sync.Mutex
+	accounts []int64
+}
+
+func (notifier *recordingNotifier) Notify(accountID int64) {
+	notifier.mutex.Lock()
+	defer notifier.mutex.Unlock()
+
+	notifier.accounts = append(notifier.accounts, accountID)
+}
+
+func (notifier *recordingNotifier) NotifyAll() {
+	notifier.Notify(-1)
+}
+
+func TestAccountListenerHandle(t *testing.T) {
+	notifier := &recordingNotifier{}
+	listener := NewAccountListener(util.Config{}, notifier)
+
+	accountID := util.RandomInt(1, 1000)
+
+	listener.handle(&pq.Notification{Channel: accountActivityChannel, Extra: "invalid"})
+	listener.handle(&pq.Notification{Channel: accountActivityChannel, Extra: "42"})
+	listener.handle(nil)
+	listener.handle(&pq.Notification{Channel: accountActivityChannel, Extra: strconv.FormatInt(accountID, 10)})
+
+	require.Equal(t, []int64{42, -1, accountID}, notifier.accounts)
+}
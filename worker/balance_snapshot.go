@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// snapshotDelay is how long after midnight snapshots wait, so transactions started before it usually have committed their entries
+// The store holds back midnights before any transaction which is still in flight, the delay only saves runs which would take nothing
+const snapshotDelay = 10 * time.Minute
+
+// BalanceSnapshotter takes daily balance snapshots of accounts which historical balances are computed from
+// Each account is snapshotted in its own transaction, so a failure of one account doesn't stop the others
+type BalanceSnapshotter struct {
+	config util.Config
+	store  db.Store
+}
+
+// NewBalanceSnapshotter creates a new balance snapshotter
+func NewBalanceSnapshotter(config util.Config, store db.Store) *BalanceSnapshotter {
+	return &BalanceSnapshotter{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start takes snapshots every interval until the context is done
+func (snapshotter *BalanceSnapshotter) Start(ctx context.Context) {
+	ticker := time.NewTicker(snapshotter.config.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := snapshotter.SnapshotDue(ctx, time.Now()); err != nil {
+			log.Println("cannot take balance snapshots:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SnapshotDue takes snapshots through the last midnight which is at least snapshotDelay before now for every account which is behind
+func (snapshotter *BalanceSnapshotter) SnapshotDue(ctx context.Context, now time.Time) error {
+	year, month, day := now.Add(-snapshotDelay).UTC().Date()
+	through := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	accountIDs, err := snapshotter.store.ListDueBalanceSnapshots(ctx, through)
+	if err != nil {
+		return err
+	}
+
+	for _, accountID := range accountIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		_, err := snapshotter.store.SnapshotBalancesTx(ctx, db.SnapshotBalancesTxParam{
+			AccountID: accountID,
+			Through:   through,
+		})
+
+		//every other account would fail the same way until the privilege is granted
+		if errors.Is(err, db.ErrSessionsNotVisible) {
+			return err
+		}
+
+		if err != nil {
+			log.Printf("cannot take balance snapshots of account %d: %v", accountID, err)
+		}
+	}
+
+	return nil
+}
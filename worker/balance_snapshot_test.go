@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceSnapshotterSnapshotDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	snapshotter := NewBalanceSnapshotter(util.Config{SnapshotInterval: time.Hour}, store)
+
+	now := time.Date(2026, time.April, 1, 0, 30, 0, 0, time.UTC)
+	through := time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListDueBalanceSnapshots(gomock.Any(), gomock.Eq(through)).
+		Times(1).
+		Return([]int64{1, 2}, nil)
+
+	//mismatch of one account doesn't stop the others
+	store.EXPECT().
+		SnapshotBalancesTx(gomock.Any(), gomock.Eq(db.SnapshotBalancesTxParam{AccountID: 1, Through: through})).
+		Times(1).
+		Return(db.SnapshotBalancesTxResult{}, fmt.Errorf("account 1: %w", db.ErrSnapshotMismatch))
+
+	store.EXPECT().
+		SnapshotBalancesTx(gomock.Any(), gomock.Eq(db.SnapshotBalancesTxParam{AccountID: 2, Through: through})).
+		Times(1).
+		Return(db.SnapshotBalancesTxResult{}, nil)
+
+	err := snapshotter.SnapshotDue(context.Background(), now)
+	require.NoError(t, err)
+}
+
+func TestBalanceSnapshotterWaitsAfterMidnight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	snapshotter := NewBalanceSnapshotter(util.Config{SnapshotInterval: time.Hour}, store)
+
+	//transactions started before midnight may still be committing, so the previous midnight is the last one
+	now := time.Date(2026, time.April, 1, 0, 5, 0, 0, time.UTC)
+	through := time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListDueBalanceSnapshots(gomock.Any(), gomock.Eq(through)).
+		Times(1).
+		Return([]int64{}, nil)
+
+	store.EXPECT().SnapshotBalancesTx(gomock.Any(), gomock.Any()).Times(0)
+
+	err := snapshotter.SnapshotDue(context.Background(), now)
+	require.NoError(t, err)
+}
+
+func TestBalanceSnapshotterStopsWhenSessionsNotVisible(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	snapshotter := NewBalanceSnapshotter(util.Config{SnapshotInterval: time.Hour}, store)
+
+	now := time.Date(2026, time.April, 1, 0, 30, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListDueBalanceSnapshots(gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]int64{1, 2}, nil)
+
+	//without pg_read_all_stats no account can be snapshotted, so the run stops at the first one
+	store.EXPECT().
+		SnapshotBalancesTx(gomock.Any(), gomock.Eq(db.SnapshotBalancesTxParam{AccountID: 1, Through: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC)})).
+		Times(1).
+		Return(db.SnapshotBalancesTxResult{}, db.ErrSessionsNotVisible)
+
+	err := snapshotter.SnapshotDue(context.Background(), now)
+	require.ErrorIs(t, err, db.ErrSessionsNotVisible)
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// HoldExpirer marks active holds past their expiry as expired
+// Expired holds don't reduce available balance even before they are marked, so this only keeps statuses accurate
+type HoldExpirer struct {
+	config util.Config
+	store  db.Store
+}
+
+// NewHoldExpirer creates a new hold expirer
+func NewHoldExpirer(config util.Config, store db.Store) *HoldExpirer {
+	return &HoldExpirer{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start expires holds every interval until the context is done
+func (expirer *HoldExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(expirer.config.SchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := expirer.store.ExpireHolds(ctx); err != nil {
+			log.Println("cannot expire holds:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// IdempotencyKeyPurger deletes expired idempotency keys
+// Expired keys are reused on conflict anyway, purging only keeps the table from growing without bound
+type IdempotencyKeyPurger struct {
+	config util.Config
+	store  db.Store
+}
+
+// NewIdempotencyKeyPurger creates a new idempotency key purger
+func NewIdempotencyKeyPurger(config util.Config, store db.Store) *IdempotencyKeyPurger {
+	return &IdempotencyKeyPurger{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start purges expired keys every interval until the context is done
+func (purger *IdempotencyKeyPurger) Start(ctx context.Context) {
+	ticker := time.NewTicker(purger.config.IdempotencyPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := purger.PurgeExpired(ctx, time.Now()); err != nil {
+			log.Println("cannot purge expired idempotency keys:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeExpired deletes keys which have been expired for longer than the retention and returns their number
+func (purger *IdempotencyKeyPurger) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	return purger.store.DeleteExpiredIdempotencyKeys(ctx, now.Add(-purger.config.IdempotencyKeyRetention))
+}
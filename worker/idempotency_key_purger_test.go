@@ -0,0 +1,51 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeyPurgerPurgeExpired(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	purger := NewIdempotencyKeyPurger(util.Config{IdempotencyPurgeInterval: time.Hour, IdempotencyKeyRetention: 24 * time.Hour}, store)
+
+	now := time.Date(2026, time.April, 2, 12, 0, 0, 0, time.UTC)
+
+	//keys are kept for the retention after they expire
+	store.EXPECT().
+		DeleteExpiredIdempotencyKeys(gomock.Any(), gomock.Eq(time.Date(2026, time.April, 1, 12, 0, 0, 0, time.UTC))).
+		Times(1).
+		Return(int64(3), nil)
+
+	deleted, err := purger.PurgeExpired(context.Background(), now)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), deleted)
+}
+
+func TestIdempotencyKeyPurgerWithoutRetention(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	purger := NewIdempotencyKeyPurger(util.Config{IdempotencyPurgeInterval: time.Hour}, store)
+
+	now := time.Date(2026, time.April, 2, 12, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		DeleteExpiredIdempotencyKeys(gomock.Any(), gomock.Eq(now)).
+		Times(1).
+		Return(int64(0), errors.New("connection refused"))
+
+	_, err := purger.PurgeExpired(context.Background(), now)
+	require.Error(t, err)
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// InterestAccruer accrues interest of interest-bearing accounts for every finished day
+// Each account is accrued in its own transaction, so a failure of one account doesn't stop the others
+type InterestAccruer struct {
+	config util.Config
+	store  db.Store
+}
+
+// NewInterestAccruer creates a new interest accruer
+func NewInterestAccruer(config util.Config, store db.Store) *InterestAccruer {
+	return &InterestAccruer{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start accrues interest every interval until the context is done
+func (accruer *InterestAccruer) Start(ctx context.Context) {
+	ticker := time.NewTicker(accruer.config.InterestInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := accruer.AccrueDue(ctx, time.Now()); err != nil {
+			log.Println("cannot accrue interest:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// AccrueDue accrues interest through the day before now for every account which is behind
+func (accruer *InterestAccruer) AccrueDue(ctx context.Context, now time.Time) error {
+	year, month, day := now.UTC().Date()
+	through := time.Date(year, month, day-1, 0, 0, 0, 0, time.UTC)
+
+	accountIDs, err := accruer.store.ListDueAccountInterest(ctx, through)
+	if err != nil {
+		return err
+	}
+
+	for _, accountID := range accountIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		_, err := accruer.store.AccrueInterestTx(ctx, db.AccrueInterestTxParam{
+			AccountID: accountID,
+			Through:   through,
+		})
+
+		if err != nil {
+			log.Printf("cannot accrue interest of account %d: %v", accountID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterestAccruerAccrueDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	accruer := NewInterestAccruer(util.Config{InterestInterval: time.Hour}, store)
+
+	now := time.Date(2024, time.March, 1, 0, 30, 0, 0, time.UTC)
+	through := time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)
+
+	store.EXPECT().
+		ListDueAccountInterest(gomock.Any(), gomock.Eq(through)).
+		Times(1).
+		Return([]int64{1, 2}, nil)
+
+	//failure of one account doesn't stop the others
+	store.EXPECT().
+		AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParam{AccountID: 1, Through: through})).
+		Times(1).
+		Return(db.AccrueInterestTxResult{}, errors.New("conflict"))
+
+	store.EXPECT().
+		AccrueInterestTx(gomock.Any(), gomock.Eq(db.AccrueInterestTxParam{AccountID: 2, Through: through})).
+		Times(1).
+		Return(db.AccrueInterestTxResult{}, nil)
+
+	err := accruer.AccrueDue(context.Background(), now)
+	require.NoError(t, err)
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// outboxBatchSize is the number of pending events claimed from the outbox at once
+const outboxBatchSize = 100
+
+// outboxLease is how long other relays skip claimed events, it must be longer than publishing a batch takes
+const outboxLease = time.Minute
+
+// OutboxRelay publishes events written to the outbox by committed transactions and marks them sent
+// Events are published in the order of their IDs and the relay stops at the first failure, so it is retried on the next run
+// Several relays may run at once, each claims its batch for the lease, so an event isn't published by two of them together
+type OutboxRelay struct {
+	config    util.Config
+	store     db.Store
+	publisher Publisher
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(config util.Config, store db.Store, publisher Publisher) *OutboxRelay {
+	return &OutboxRelay{
+		config:    config,
+		store:     store,
+		publisher: publisher,
+	}
+}
+
+// Start relays pending events every interval until the context is done
+func (relay *OutboxRelay) Start(ctx context.Context) {
+	ticker := time.NewTicker(relay.config.OutboxInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := relay.RelayPending(ctx); err != nil {
+			log.Println("cannot relay outbox events:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending claims and publishes all pending events and returns how many of them were sent
+// Claimed events which are not sent are released at a failure, so the next run starts from the failed one again
+func (relay *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	sent := 0
+
+	for {
+		events, err := relay.store.ClaimPendingOutboxEvents(ctx, db.ClaimPendingOutboxEventsParams{
+			LockedUntil: time.Now().Add(outboxLease),
+			Limit:       outboxBatchSize,
+		})
+		if err != nil {
+			return sent, err
+		}
+
+		for i, event := range events {
+			if err := relay.publisher.Publish(ctx, event); err != nil {
+				relay.release(ctx, events[i:])
+				return sent, fmt.Errorf("cannot publish event %d: %w", event.ID, err)
+			}
+
+			if err := relay.store.MarkOutboxEventSent(ctx, event.ID); err != nil {
+				relay.release(ctx, events[i:])
+				return sent, fmt.Errorf("cannot mark event %d sent: %w", event.ID, err)
+			}
+
+			sent++
+		}
+
+		if len(events) < outboxBatchSize {
+			return sent, nil
+		}
+	}
+}
+
+// release gives up the claim of the events, if it fails they are picked up once the lease expires
+func (relay *OutboxRelay) release(ctx context.Context, events []db.Outbox) {
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+
+	if err := relay.store.ReleaseOutboxEvents(ctx, ids); err != nil {
+		log.Println("cannot release outbox events:", err)
+	}
+}
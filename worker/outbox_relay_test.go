@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// failingPublisher fails to publish the event with the ID and publishes the others to memory
+type failingPublisher struct {
+	*MemoryPublisher
+	failID int64
+}
+
+func (publisher failingPublisher) Publish(ctx context.Context, event db.Outbox) error {
+	if event.ID == publisher.failID {
+		return errors.New("broker is down")
+	}
+	return publisher.MemoryPublisher.Publish(ctx, event)
+}
+
+func randomOutboxEvents(firstID int64, n int) []db.Outbox {
+	events := make([]db.Outbox, n)
+	for i := range events {
+		events[i] = db.Outbox{
+			ID:            firstID + int64(i),
+			AggregateType: util.TransferAggregate,
+			AggregateID:   util.RandomInt(1, 1000),
+			EventType:     util.TransferCreatedEvent,
+			Payload:       json.RawMessage(`{}`),
+		}
+	}
+	return events
+}
+
+func TestOutboxRelayRelayPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	publisher := NewMemoryPublisher()
+	relay := NewOutboxRelay(util.Config{OutboxInterval: time.Second}, store, publisher)
+
+	//a full batch means there may be more pending events
+	batch1 := randomOutboxEvents(1, outboxBatchSize)
+	batch2 := randomOutboxEvents(outboxBatchSize+1, 2)
+
+	checkClaim := func(_ context.Context, arg db.ClaimPendingOutboxEventsParams) {
+		require.Equal(t, int32(outboxBatchSize), arg.Limit)
+		require.WithinDuration(t, time.Now().Add(outboxLease), arg.LockedUntil, time.Second)
+	}
+
+	gomock.InOrder(
+		store.EXPECT().ClaimPendingOutboxEvents(gomock.Any(), gomock.Any()).Times(1).Do(checkClaim).Return(batch1, nil),
+		store.EXPECT().ClaimPendingOutboxEvents(gomock.Any(), gomock.Any()).Times(1).Do(checkClaim).Return(batch2, nil),
+	)
+
+	store.EXPECT().MarkOutboxEventSent(gomock.Any(), gomock.Any()).Times(outboxBatchSize + 2).Return(nil)
+	store.EXPECT().ReleaseOutboxEvents(gomock.Any(), gomock.Any()).Times(0)
+
+	sent, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, outboxBatchSize+2, sent)
+	require.Equal(t, append(batch1, batch2...), publisher.Events())
+}
+
+func TestOutboxRelayStopsAtFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	publisher := failingPublisher{MemoryPublisher: NewMemoryPublisher(), failID: 2}
+	relay := NewOutboxRelay(util.Config{OutboxInterval: time.Second}, store, publisher)
+
+	events := randomOutboxEvents(1, 3)
+
+	store.EXPECT().ClaimPendingOutboxEvents(gomock.Any(), gomock.Any()).Times(1).Return(events, nil)
+
+	//events after the failed one stay pending, so they are never published out of order
+	store.EXPECT().MarkOutboxEventSent(gomock.Any(), gomock.Eq(int64(1))).Times(1).Return(nil)
+	store.EXPECT().MarkOutboxEventSent(gomock.Any(), gomock.Eq(int64(2))).Times(0)
+	store.EXPECT().MarkOutboxEventSent(gomock.Any(), gomock.Eq(int64(3))).Times(0)
+
+	//their claim is released, so the next run doesn't wait for the lease to retry them
+	store.EXPECT().ReleaseOutboxEvents(gomock.Any(), gomock.Eq([]int64{2, 3})).Times(1).Return(nil)
+
+	sent, err := relay.RelayPending(context.Background())
+	require.Error(t, err)
+	require.Equal(t, 1, sent)
+	require.Equal(t, events[:1], publisher.Events())
+}
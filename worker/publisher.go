@@ -0,0 +1,147 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// Constants for all publishers the outbox relay can be configured with
+const (
+	StdoutPublisher = "stdout"
+	FilePublisher   = "file"
+)
+
+// Publisher delivers events of the outbox to downstream systems
+// An event may be published more than once if marking it sent fails, so consumers deduplicate events by ID
+type Publisher interface {
+	Publish(ctx context.Context, event db.Outbox) error
+}
+
+// EventMessage is an event of the outbox as it is sent to downstream systems
+type EventMessage struct {
+	ID            int64           `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// NewEventMessage creates the message of the event
+func NewEventMessage(event db.Outbox) EventMessage {
+	return EventMessage{
+		ID:            event.ID,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		EventType:     event.EventType,
+		Payload:       event.Payload,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+// NewPublisher creates the publisher chosen in the config, stdout is the default
+func NewPublisher(config util.Config) (Publisher, error) {
+	switch config.OutboxPublisher {
+	case "", StdoutPublisher:
+		return NewWriterPublisher(os.Stdout), nil
+	case FilePublisher:
+		return NewFilePublisher(config.OutboxFile)
+	}
+
+	return nil, fmt.Errorf("unknown outbox publisher %q", config.OutboxPublisher)
+}
+
+// WriterPublisher writes every event as a line of JSON
+type WriterPublisher struct {
+	mutex  sync.Mutex
+	writer io.Writer
+}
+
+// NewWriterPublisher creates a publisher which writes events to the writer
+func NewWriterPublisher(writer io.Writer) *WriterPublisher {
+	return &WriterPublisher{writer: writer}
+}
+
+// NewFilePublisher creates a publisher which appends events to the file
+func NewFilePublisher(path string) (*WriterPublisher, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open outbox file: %w", err)
+	}
+
+	return NewWriterPublisher(file), nil
+}
+
+// Publish writes the event followed by a new line
+func (publisher *WriterPublisher) Publish(ctx context.Context, event db.Outbox) error {
+	data, err := json.Marshal(NewEventMessage(event))
+	if err != nil {
+		return err
+	}
+
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	_, err = publisher.writer.Write(append(data, '\n'))
+	return err
+}
+
+// MemoryPublisher keeps published events in memory, it is meant for tests
+type MemoryPublisher struct {
+	mutex  sync.Mutex
+	events []db.Outbox
+}
+
+// NewMemoryPublisher creates a publisher which keeps events in memory
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish keeps the event
+func (publisher *MemoryPublisher) Publish(ctx context.Context, event db.Outbox) error {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	publisher.events = append(publisher.events, event)
+	return nil
+}
+
+// Events returns all events published so far in the order of publishing
+func (publisher *MemoryPublisher) Events() []db.Outbox {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	events := make([]db.Outbox, len(publisher.events))
+	copy(events, publisher.events)
+	return events
+}
+
+// MultiPublisher publishes every event to all of its publishers in turn
+// It stops at the first failure, so publishers must tolerate an event published again on the next run
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher creates a publisher which publishes events to all the publishers
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	return &MultiPublisher{publishers: publishers}
+}
+
+// Publish publishes the event to every publisher
+func (publisher *MultiPublisher) Publish(ctx context.Context, event db.Outbox) error {
+	for _, p := range publisher.publishers {
+		if err := p.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
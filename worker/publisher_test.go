@@ -0,0 +1,63 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterPublisher(t *testing.T) {
+	var buffer bytes.Buffer
+	publisher := NewWriterPublisher(&buffer)
+
+	event := db.Outbox{
+		ID:            1,
+		AggregateType: util.AccountAggregate,
+		AggregateID:   42,
+		EventType:     util.AccountCreatedEvent,
+		Payload:       json.RawMessage(`{"id":42}`),
+		CreatedAt:     time.Date(2026, time.April, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	require.NoError(t, publisher.Publish(context.Background(), event))
+
+	//every event is a line of its own
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+
+	var message EventMessage
+	err := json.Unmarshal([]byte(lines[0]), &message)
+	require.NoError(t, err)
+	require.Equal(t, NewEventMessage(event), message)
+}
+
+func TestNewPublisher(t *testing.T) {
+	publisher, err := NewPublisher(util.Config{})
+	require.NoError(t, err)
+	require.IsType(t, &WriterPublisher{}, publisher)
+
+	path := filepath.Join(t.TempDir(), "outbox.log")
+
+	publisher, err = NewPublisher(util.Config{OutboxPublisher: FilePublisher, OutboxFile: path})
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), db.Outbox{ID: 1, Payload: json.RawMessage(`{}`)})
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"id":1`)
+
+	_, err = NewPublisher(util.Config{OutboxPublisher: "kafka"})
+	require.Error(t, err)
+}
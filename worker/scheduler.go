@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// errApprovalRequired fails scheduled transfers above the approval threshold, nobody can approve them
+var errApprovalRequired = errors.New("amount is above the approval threshold")
+
+// schedulerLease is how long other workers skip a claimed scheduled transfer, it must be longer than a transfer takes
+const schedulerLease = time.Minute
+
+// Scheduler executes due scheduled transfers in the background
+// Several schedulers may run at once, each scheduled transfer is claimed by only one of them
+type Scheduler struct {
+	config util.Config
+	store  db.Store
+}
+
+// NewScheduler creates a new scheduler
+func NewScheduler(config util.Config, store db.Store) *Scheduler {
+	return &Scheduler{
+		config: config,
+		store:  store,
+	}
+}
+
+// Start runs due scheduled transfers every interval until the context is done
+func (scheduler *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.config.SchedulerInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := scheduler.RunDue(ctx); err != nil {
+			log.Println("cannot run scheduled transfers:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue claims and executes scheduled transfers until none of them is due
+func (scheduler *Scheduler) RunDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		schedule, err := scheduler.store.ClaimDueScheduledTransfer(ctx, time.Now().Add(schedulerLease))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+
+		//the claim expires after the lease so another run picks the schedule up later
+		if err := scheduler.run(ctx, schedule); err != nil {
+			log.Printf("cannot run scheduled transfer [%d]: %v", schedule.ID, err)
+		}
+	}
+
+	return ctx.Err()
+}
+
+// run executes one occurrence of the scheduled transfer and records the outcome
+// Rejected transfers are retried after a delay while retries are left, then the occurrence is skipped
+func (scheduler *Scheduler) run(ctx context.Context, schedule db.ScheduledTransfer) error {
+	key := scheduledTransferKey(schedule)
+
+	var transferTx db.TransferTxResult
+
+	//the API rejects schedules above the approval threshold, but the threshold may have been lowered since
+	requiresApproval, err := scheduler.requiresApproval(ctx, schedule)
+	if err != nil {
+		return err
+	}
+
+	if requiresApproval {
+		err = errApprovalRequired
+	} else {
+		//the key makes the occurrence execute only once even if the outcome was not recorded
+		transferTx, err = scheduler.store.TransferTx(ctx, db.TransferTxParam{
+			FromAccountID:  schedule.FromAccountID,
+			ToAccountID:    schedule.ToAccountID,
+			Amount:         schedule.Amount,
+			IdempotencyKey: key,
+			KeyUsername:    scheduledTransferKeyUsername,
+			RequestHash:    key,
+			KeyExpiredAt:   time.Now().Add(scheduler.config.IdempotencyKeyDuration),
+		})
+	}
+
+	arg := db.RecordScheduledTransferRunTxParam{
+		ScheduledTransfer: schedule,
+		NextRunAt:         schedule.NextRunAt,
+		Status:            util.ActiveSchedule,
+	}
+
+	switch {
+	case err == nil:
+		arg.TransferID = sql.NullInt64{Int64: transferTx.Transfer.ID, Valid: true}
+		arg.RunStatus = util.SucceededRun
+		nextOccurrence(&arg, util.CompletedSchedule)
+	case errors.Is(err, errApprovalRequired):
+		//no occurrence can run without approval, so the schedule fails at once
+		arg.RunStatus = util.SkippedRun
+		arg.Error = err.Error()
+		arg.Status = util.FailedSchedule
+	case isTransferRejected(err) && schedule.RetryCount < schedule.MaxRetries:
+		arg.RunStatus = util.FailedRun
+		arg.Error = err.Error()
+		arg.RetryCount = schedule.RetryCount + 1
+		arg.LockedUntil = time.Now().Add(scheduler.config.SchedulerRetryDelay)
+	case isTransferRejected(err):
+		arg.RunStatus = util.SkippedRun
+		arg.Error = err.Error()
+		nextOccurrence(&arg, util.FailedSchedule)
+	default:
+		return err
+	}
+
+	_, err = scheduler.store.RecordScheduledTransferRunTx(ctx, arg)
+	return err
+}
+
+// requiresApproval returns true if the occurrence is above the approval threshold of the currency of the sender
+func (scheduler *Scheduler) requiresApproval(ctx context.Context, schedule db.ScheduledTransfer) (bool, error) {
+	//without thresholds the currency doesn't matter, so the account isn't read
+	if len(scheduler.config.ApprovalThresholds) == 0 {
+		return false, nil
+	}
+
+	account, err := scheduler.store.GetAccount(ctx, schedule.FromAccountID)
+	if err != nil {
+		return false, err
+	}
+
+	return scheduler.config.RequiresApproval(schedule.Amount, account.Currency), nil
+}
+
+// nextOccurrence moves the schedule to its next occurrence, one-shot schedule gets the final status instead
+func nextOccurrence(arg *db.RecordScheduledTransferRunTxParam, finalStatus string) {
+	schedule := arg.ScheduledTransfer
+
+	//anchor day is kept in UTC
+	next, ok := util.NextOccurrence(schedule.Recurrence, schedule.NextRunAt.UTC(), int(schedule.AnchorDay))
+	if !ok {
+		arg.Status = finalStatus
+		return
+	}
+
+	arg.NextRunAt = next
+	arg.RetryCount = 0
+}
+
+// scheduledTransferKeyUsername owns idempotency keys of scheduled transfers, users can't send keys under a system name
+const scheduledTransferKeyUsername = util.SystemOwnerPrefix + "scheduler"
+
+// scheduledTransferKey returns idempotency key of the current occurrence of the scheduled transfer
+func scheduledTransferKey(schedule db.ScheduledTransfer) string {
+	return fmt.Sprintf("scheduled-transfer:%d:%d", schedule.ID, schedule.NextRunAt.Unix())
+}
+
+// isTransferRejected returns true if the transfer failed because of the state of accounts, not because of an internal error
+func isTransferRejected(err error) bool {
+	return errors.Is(err, db.ErrInsufficientFunds) ||
+		errors.Is(err, db.ErrExchangeRateNotFound) ||
+		errors.Is(err, db.ErrAmountTooSmall) ||
+		errors.Is(err, db.ErrAccountNotActive) ||
+		errors.Is(err, db.ErrTransferLimitExceeded) ||
+		errors.Is(err, util.ErrAmountOverflow)
+}
@@ -0,0 +1,235 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduler(store db.Store) *Scheduler {
+	config := util.Config{
+		IdempotencyKeyDuration: time.Minute,
+		SchedulerInterval:      time.Minute,
+		SchedulerRetryDelay:    time.Hour,
+	}
+
+	return NewScheduler(config, store)
+}
+
+func randomScheduledTransfer(recurrence string) db.ScheduledTransfer {
+	return db.ScheduledTransfer{
+		ID:            util.RandomInt(1, 1000),
+		Owner:         util.RandomOwner(),
+		FromAccountID: util.RandomInt(1, 1000),
+		ToAccountID:   util.RandomInt(1001, 2000),
+		Amount:        util.RandomMoney(),
+		Recurrence:    recurrence,
+		NextRunAt:     time.Date(2024, time.January, 31, 9, 0, 0, 0, time.UTC),
+		Status:        util.ActiveSchedule,
+		MaxRetries:    2,
+		AnchorDay:     31,
+	}
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	transfer := db.Transfer{ID: util.RandomInt(1, 1000)}
+
+	testCases := []struct {
+		name        string
+		schedule    db.ScheduledTransfer
+		transferErr error
+		checkRun    func(t *testing.T, arg db.RecordScheduledTransferRunTxParam)
+	}{
+		{
+			name:     "RecurringSucceeded",
+			schedule: randomScheduledTransfer(util.MonthlyRecurrence),
+			checkRun: func(t *testing.T, arg db.RecordScheduledTransferRunTxParam) {
+				require.Equal(t, util.SucceededRun, arg.RunStatus)
+				require.Equal(t, sql.NullInt64{Int64: transfer.ID, Valid: true}, arg.TransferID)
+				require.Equal(t, util.ActiveSchedule, arg.Status)
+				require.Equal(t, time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC), arg.NextRunAt)
+				require.Zero(t, arg.RetryCount)
+				require.True(t, arg.LockedUntil.IsZero())
+			},
+		},
+		{
+			name: "RecurringBackToAnchorDay",
+			schedule: func() db.ScheduledTransfer {
+				schedule := randomScheduledTransfer(util.MonthlyRecurrence)
+				schedule.NextRunAt = time.Date(2024, time.February, 29, 9, 0, 0, 0, time.UTC)
+				return schedule
+			}(),
+			checkRun: func(t *testing.T, arg db.RecordScheduledTransferRunTxParam) {
+				require.Equal(t, util.SucceededRun, arg.RunStatus)
+				require.Equal(t, time.Date(2024, time.March, 31, 9, 0, 0, 0, time.UTC), arg.NextRunAt)
+			},
+		},
+		{
+			name:     "OneShotSucceeded",
+			schedule: randomScheduledTransfer(""),
+			checkRun: func(t *testing.T, arg db.RecordScheduledTransferRunTxParam) {
+				require.Equal(t, util.SucceededRun, arg.RunStatus)
+				require.Equal(t, util.CompletedSchedule, arg.Status)
+			},
+		},
+		{
+			name:        "InsufficientFundsRetry",
+			schedule:    randomScheduledTransfer(util.MonthlyRecurrence),
+			transferErr: db.ErrInsufficientFunds,
+			checkRun: func(t *testing.T, arg db.RecordScheduledTransferRunTxParam) {
+				require.Equal(t, util.FailedRun, arg.RunStatus)
+				require.Equal(t, db.ErrInsufficientFunds.Error(), arg.Error)
+				require.False(t, arg.TransferID.Valid)
+				require.Equal(t, util.ActiveSchedule, arg.Status)
+				require.Equal(t, arg.ScheduledTransfer.NextRunAt, arg.NextRunAt)
+				require.Equal(t, int32(1), arg.RetryCount)
+				require.WithinDuration(t, time.Now().Add(time.Hour), arg.LockedUntil, time.Second)
+			},
+		},
+		{
+			name: "InsufficientFundsSkipRecurring",
+			schedule: func() db.ScheduledTransfer {
+				schedule := randomScheduledTransfer(util.DailyRecurrence)
+				schedule.RetryCount = schedule.MaxRetries
+				return schedule
+			}(),
+			transferErr: db.ErrInsufficientFunds,
+			checkRun: func(t *testing.T, arg db.RecordScheduledTransferRunTxParam) {
+				require.Equal(t, util.SkippedRun, arg.RunStatus)
+				require.Equal(t, util.ActiveSchedule, arg.Status)
+				require.Equal(t, time.Date(2024, time.February, 1, 9, 0, 0, 0, time.UTC), arg.NextRunAt)
+				require.Zero(t, arg.RetryCount)
+				require.True(t, arg.LockedUntil.IsZero())
+			},
+		},
+		{
+			name: "InsufficientFundsSkipOneShot",
+			schedule: func() db.ScheduledTransfer {
+				schedule := randomScheduledTransfer("")
+				schedule.RetryCount = schedule.MaxRetries
+				return schedule
+			}(),
+			transferErr: db.ErrInsufficientFunds,
+			checkRun: func(t *testing.T, arg db.RecordScheduledTransferRunTxParam) {
+				require.Equal(t, util.SkippedRun, arg.RunStatus)
+				require.Equal(t, util.FailedSchedule, arg.Status)
+			},
+		},
+		{
+			name:        "InternalError",
+			schedule:    randomScheduledTransfer(util.WeeklyRecurrence),
+			transferErr: sql.ErrConnDone,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+
+			gomock.InOrder(
+				store.EXPECT().
+					ClaimDueScheduledTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(tc.schedule, nil),
+				store.EXPECT().
+					ClaimDueScheduledTransfer(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.ScheduledTransfer{}, sql.ErrNoRows),
+			)
+
+			store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, arg db.TransferTxParam) (db.TransferTxResult, error) {
+					require.Equal(t, tc.schedule.FromAccountID, arg.FromAccountID)
+					require.Equal(t, tc.schedule.ToAccountID, arg.ToAccountID)
+					require.Equal(t, tc.schedule.Amount, arg.Amount)
+					require.Equal(t, scheduledTransferKey(tc.schedule), arg.IdempotencyKey)
+					require.Equal(t, scheduledTransferKeyUsername, arg.KeyUsername)
+
+					if tc.transferErr != nil {
+						return db.TransferTxResult{}, tc.transferErr
+					}
+					return db.TransferTxResult{Transfer: transfer}, nil
+				})
+
+			if tc.checkRun == nil {
+				store.EXPECT().
+					RecordScheduledTransferRunTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			} else {
+				store.EXPECT().
+					RecordScheduledTransferRunTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.RecordScheduledTransferRunTxParam) (db.RecordScheduledTransferRunTxResult, error) {
+						require.Equal(t, tc.schedule, arg.ScheduledTransfer)
+						tc.checkRun(t, arg)
+						return db.RecordScheduledTransferRunTxResult{}, nil
+					})
+			}
+
+			err := newTestScheduler(store).RunDue(context.Background())
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSchedulerRunAboveApprovalThreshold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+
+	schedule := randomScheduledTransfer(util.MonthlyRecurrence)
+
+	gomock.InOrder(
+		store.EXPECT().
+			ClaimDueScheduledTransfer(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(schedule, nil),
+		store.EXPECT().
+			ClaimDueScheduledTransfer(gomock.Any(), gomock.Any()).
+			Times(1).
+			Return(db.ScheduledTransfer{}, sql.ErrNoRows),
+	)
+
+	store.EXPECT().
+		GetAccount(gomock.Any(), gomock.Eq(schedule.FromAccountID)).
+		Times(1).
+		Return(db.Account{ID: schedule.FromAccountID, Currency: util.USD}, nil)
+
+	//nobody can approve a scheduled transfer, so it is never executed
+	store.EXPECT().
+		TransferTx(gomock.Any(), gomock.Any()).
+		Times(0)
+
+	store.EXPECT().
+		RecordScheduledTransferRunTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.RecordScheduledTransferRunTxParam) (db.RecordScheduledTransferRunTxResult, error) {
+			require.Equal(t, schedule, arg.ScheduledTransfer)
+			require.Equal(t, util.SkippedRun, arg.RunStatus)
+			require.Equal(t, errApprovalRequired.Error(), arg.Error)
+			require.False(t, arg.TransferID.Valid)
+			require.Equal(t, util.FailedSchedule, arg.Status)
+			return db.RecordScheduledTransferRunTxResult{}, nil
+		})
+
+	scheduler := newTestScheduler(store)
+	scheduler.config.ApprovalThresholds = map[string]int64{util.USD: schedule.Amount - 1}
+
+	err := scheduler.RunDue(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,290 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+)
+
+// webhookLease is how long other workers skip a claimed delivery, it must be longer than WebhookTimeout
+const webhookLease = time.Minute
+
+// maxWebhookRetryDelay caps the exponential backoff between attempts of a delivery
+const maxWebhookRetryDelay = 24 * time.Hour
+
+// maxWebhookResponseSize is how much of the response of a receiver is read before the connection is reused
+const maxWebhookResponseSize = 64 << 10
+
+// WebhookMessage is the body of a webhook delivery
+// Receivers deduplicate messages by event ID, test deliveries have none
+type WebhookMessage struct {
+	DeliveryID int64           `json:"delivery_id"`
+	EventID    int64           `json:"event_id,omitempty"`
+	EventType  string          `json:"event_type"`
+	Payload    json.RawMessage `json:"payload"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// NewWebhookMessage creates the message of the delivery
+func NewWebhookMessage(delivery db.WebhookDelivery) WebhookMessage {
+	return WebhookMessage{
+		DeliveryID: delivery.ID,
+		EventID:    delivery.EventID.Int64,
+		EventType:  delivery.EventType,
+		Payload:    delivery.Payload,
+		CreatedAt:  delivery.CreatedAt,
+	}
+}
+
+// WebhookPublisher queues a delivery of the event for every webhook of the users the event concerns
+// Queueing is idempotent, an event published again gets no second delivery
+type WebhookPublisher struct {
+	store db.Store
+}
+
+// NewWebhookPublisher creates a new webhook publisher
+func NewWebhookPublisher(store db.Store) *WebhookPublisher {
+	return &WebhookPublisher{store: store}
+}
+
+// Publish queues deliveries of the event to the webhooks subscribed to its type
+func (publisher *WebhookPublisher) Publish(ctx context.Context, event db.Outbox) error {
+	owners, err := publisher.eventOwners(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	for _, owner := range owners {
+		_, err := publisher.store.CreateEventWebhookDeliveries(ctx, db.CreateEventWebhookDeliveriesParams{
+			EventID:   event.ID,
+			EventType: event.EventType,
+			Payload:   event.Payload,
+			Owner:     owner,
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// eventOwners returns owners of all accounts the event concerns, both sides of a transfer are notified
+func (publisher *WebhookPublisher) eventOwners(ctx context.Context, event db.Outbox) ([]string, error) {
+	var accountIDs []int64
+
+	switch event.AggregateType {
+	case util.AccountAggregate:
+		accountIDs = []int64{event.AggregateID}
+	case util.TransferAggregate:
+		var transfer db.Transfer
+		if err := json.Unmarshal(event.Payload, &transfer); err != nil {
+			return nil, fmt.Errorf("cannot decode transfer of event %d: %w", event.ID, err)
+		}
+		accountIDs = []int64{transfer.FromAccountID, transfer.ToAccountID}
+	default:
+		return nil, nil
+	}
+
+	owners := []string{}
+	seen := map[string]bool{}
+
+	for _, accountID := range accountIDs {
+		account, err := publisher.store.GetAccount(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[account.Owner] {
+			seen[account.Owner] = true
+			owners = append(owners, account.Owner)
+		}
+	}
+
+	return owners, nil
+}
+
+// WebhookDispatcher sends queued webhook deliveries and retries failed ones with exponential backoff
+// Several dispatchers may run at once, each delivery is claimed by only one of them
+type WebhookDispatcher struct {
+	config util.Config
+	store  db.Store
+	client *http.Client
+}
+
+// NewWebhookDispatcher creates a new webhook dispatcher
+func NewWebhookDispatcher(config util.Config, store db.Store) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		config: config,
+		store:  store,
+		client: newWebhookClient(config),
+	}
+}
+
+// newWebhookClient creates a client which connects to public addresses only
+// Addresses are checked when the connection is made, after names are resolved, so neither DNS answers nor redirects can point a webhook at internal services
+func newWebhookClient(config util.Config) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: config.WebhookTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if ip := net.ParseIP(host); ip == nil || !util.IsPublicWebhookIP(ip) {
+				return fmt.Errorf("%w: %s", util.ErrWebhookAddressNotAllowed, host)
+			}
+
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   config.WebhookTimeout,
+		Transport: transport,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return util.ValidateWebhookURL(request.URL.String(), config.IsDevelopment())
+		},
+	}
+}
+
+// Start sends due deliveries every interval until the context is done
+func (dispatcher *WebhookDispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(dispatcher.config.WebhookInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := dispatcher.DispatchDue(ctx); err != nil {
+			log.Println("cannot dispatch webhook deliveries:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue claims and sends deliveries until none of them is due
+func (dispatcher *WebhookDispatcher) DispatchDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		delivery, err := dispatcher.store.ClaimDueWebhookDelivery(ctx, time.Now().Add(webhookLease))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil
+			}
+			return err
+		}
+
+		//the claim expires after the lease so another run picks the delivery up later
+		if err := dispatcher.dispatch(ctx, delivery); err != nil {
+			log.Printf("cannot dispatch webhook delivery [%d]: %v", delivery.ID, err)
+		}
+	}
+
+	return ctx.Err()
+}
+
+// dispatch sends the delivery once and records the attempt
+// A failed delivery is retried after a delay while attempts are left, then it is given up
+func (dispatcher *WebhookDispatcher) dispatch(ctx context.Context, delivery db.WebhookDelivery) error {
+	webhook, err := dispatcher.store.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		return err
+	}
+
+	responseStatus, sendErr := dispatcher.send(ctx, webhook, delivery)
+
+	arg := db.RecordWebhookAttemptTxParam{
+		DeliveryID:     delivery.ID,
+		ResponseStatus: responseStatus,
+		Status:         util.SucceededDelivery,
+		NextAttemptAt:  delivery.NextAttemptAt,
+	}
+
+	if sendErr != nil {
+		arg.Error = sendErr.Error()
+		arg.Status = util.PendingDelivery
+		arg.NextAttemptAt = time.Now().Add(webhookRetryDelay(dispatcher.config.WebhookRetryDelay, delivery.Attempts))
+
+		if delivery.Attempts+1 >= dispatcher.config.WebhookMaxAttempts {
+			arg.Status = util.FailedDelivery
+		}
+	}
+
+	_, err = dispatcher.store.RecordWebhookAttemptTx(ctx, arg)
+	return err
+}
+
+// send posts the signed message of the delivery to the webhook and returns the status of the response
+// Any status other than 2xx is an error, the status is zero if the receiver did not respond
+func (dispatcher *WebhookDispatcher) send(ctx context.Context, webhook db.Webhook, delivery db.WebhookDelivery) (int32, error) {
+	body, err := json.Marshal(NewWebhookMessage(delivery))
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(util.WebhookEventHeader, delivery.EventType)
+	request.Header.Set(util.WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	request.Header.Set(util.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(util.WebhookSignatureHeader, util.SignWebhook(webhook.Secret, timestamp, body))
+
+	response, err := dispatcher.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(response.Body, maxWebhookResponseSize))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return int32(response.StatusCode), fmt.Errorf("receiver responded with status %d", response.StatusCode)
+	}
+
+	return int32(response.StatusCode), nil
+}
+
+// webhookRetryDelay returns the delay before the next attempt, doubled after every attempt made so far
+func webhookRetryDelay(base time.Duration, attempts int32) time.Duration {
+	delay := base
+	for i := int32(0); i < attempts && delay < maxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxWebhookRetryDelay {
+		return maxWebhookRetryDelay
+	}
+
+	return delay
+}
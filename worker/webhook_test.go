@@ -0,0 +1,342 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/gu3sswho/simplebank/db/mock"
+	db "github.com/gu3sswho/simplebank/db/sqlc"
+	"github.com/gu3sswho/simplebank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestWebhookDispatcher(store db.Store) *WebhookDispatcher {
+	config := util.Config{
+		WebhookInterval:    time.Second,
+		WebhookTimeout:     time.Second,
+		WebhookRetryDelay:  time.Minute,
+		WebhookMaxAttempts: 3,
+	}
+
+	dispatcher := NewWebhookDispatcher(config, store)
+
+	//test receivers listen on loopback, which the dispatcher refuses to connect to
+	dispatcher.client = &http.Client{Timeout: config.WebhookTimeout}
+
+	return dispatcher
+}
+
+func randomWebhookDelivery(webhookID int64, attempts int32) db.WebhookDelivery {
+	return db.WebhookDelivery{
+		ID:            util.RandomInt(1, 1000),
+		WebhookID:     webhookID,
+		EventID:       sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true},
+		EventType:     util.TransferCreatedEvent,
+		Payload:       json.RawMessage(`{"id":1}`),
+		Status:        util.PendingDelivery,
+		Attempts:      attempts,
+		NextAttemptAt: time.Now().Add(webhookLease),
+		CreatedAt:     time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// receivedRequest is a webhook request captured by the test receiver
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// newTestReceiver starts a receiver answering every request with the status and captures the requests
+func newTestReceiver(t *testing.T, status int) (*httptest.Server, chan receivedRequest) {
+	requests := make(chan receivedRequest, 1)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		requests <- receivedRequest{header: r.Header, body: body}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(receiver.Close)
+
+	return receiver, requests
+}
+
+func TestWebhookDispatcherDispatchDue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		status        int
+		attempts      int32
+		checkDelivery func(t *testing.T, arg db.RecordWebhookAttemptTxParam)
+	}{
+		{
+			name:   "Succeeded",
+			status: http.StatusNoContent,
+			checkDelivery: func(t *testing.T, arg db.RecordWebhookAttemptTxParam) {
+				require.Equal(t, util.SucceededDelivery, arg.Status)
+				require.Equal(t, int32(http.StatusNoContent), arg.ResponseStatus)
+				require.Empty(t, arg.Error)
+			},
+		},
+		{
+			name:     "Retry",
+			status:   http.StatusInternalServerError,
+			attempts: 1,
+			checkDelivery: func(t *testing.T, arg db.RecordWebhookAttemptTxParam) {
+				require.Equal(t, util.PendingDelivery, arg.Status)
+				require.Equal(t, int32(http.StatusInternalServerError), arg.ResponseStatus)
+				require.Contains(t, arg.Error, "500")
+				require.WithinDuration(t, time.Now().Add(2*time.Minute), arg.NextAttemptAt, time.Second)
+			},
+		},
+		{
+			name:     "GiveUp",
+			status:   http.StatusGone,
+			attempts: 2,
+			checkDelivery: func(t *testing.T, arg db.RecordWebhookAttemptTxParam) {
+				require.Equal(t, util.FailedDelivery, arg.Status)
+				require.Equal(t, int32(http.StatusGone), arg.ResponseStatus)
+				require.NotEmpty(t, arg.Error)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			receiver, requests := newTestReceiver(t, tc.status)
+
+			secret, err := util.NewWebhookSecret()
+			require.NoError(t, err)
+
+			webhook := db.Webhook{
+				ID:     util.RandomInt(1, 1000),
+				Owner:  util.RandomOwner(),
+				Url:    receiver.URL,
+				Secret: secret,
+			}
+			delivery := randomWebhookDelivery(webhook.ID, tc.attempts)
+
+			store := mockdb.NewMockStore(ctrl)
+
+			gomock.InOrder(
+				store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(delivery, nil),
+				store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{}, sql.ErrNoRows),
+			)
+
+			store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+
+			store.EXPECT().
+				RecordWebhookAttemptTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx context.Context, arg db.RecordWebhookAttemptTxParam) (db.RecordWebhookAttemptTxResult, error) {
+					require.Equal(t, delivery.ID, arg.DeliveryID)
+					tc.checkDelivery(t, arg)
+					return db.RecordWebhookAttemptTxResult{}, nil
+				})
+
+			err = newTestWebhookDispatcher(store).DispatchDue(context.Background())
+			require.NoError(t, err)
+
+			request := <-requests
+			require.Equal(t, "application/json", request.header.Get("Content-Type"))
+			require.Equal(t, delivery.EventType, request.header.Get(util.WebhookEventHeader))
+			require.Equal(t, strconv.FormatInt(delivery.ID, 10), request.header.Get(util.WebhookDeliveryHeader))
+
+			timestamp, err := strconv.ParseInt(request.header.Get(util.WebhookTimestampHeader), 10, 64)
+			require.NoError(t, err)
+			require.InDelta(t, time.Now().Unix(), timestamp, 5)
+
+			signature := request.header.Get(util.WebhookSignatureHeader)
+			require.True(t, util.VerifyWebhookSignature(secret, timestamp, request.body, signature))
+
+			var message WebhookMessage
+			err = json.Unmarshal(request.body, &message)
+			require.NoError(t, err)
+			require.Equal(t, NewWebhookMessage(delivery), message)
+		})
+	}
+}
+
+func TestWebhookDispatcherUnreachable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver, _ := newTestReceiver(t, http.StatusOK)
+	receiver.Close()
+
+	webhook := db.Webhook{ID: util.RandomInt(1, 1000), Url: receiver.URL, Secret: "secret"}
+	delivery := randomWebhookDelivery(webhook.ID, 0)
+
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(delivery, nil),
+		store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{}, sql.ErrNoRows),
+	)
+
+	store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+
+	store.EXPECT().
+		RecordWebhookAttemptTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.RecordWebhookAttemptTxParam) (db.RecordWebhookAttemptTxResult, error) {
+			require.Equal(t, util.PendingDelivery, arg.Status)
+			require.Zero(t, arg.ResponseStatus)
+			require.NotEmpty(t, arg.Error)
+			require.WithinDuration(t, time.Now().Add(time.Minute), arg.NextAttemptAt, time.Second)
+			return db.RecordWebhookAttemptTxResult{}, nil
+		})
+
+	err := newTestWebhookDispatcher(store).DispatchDue(context.Background())
+	require.NoError(t, err)
+}
+
+func TestWebhookDispatcherPrivateAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	receiver, requests := newTestReceiver(t, http.StatusOK)
+
+	webhook := db.Webhook{ID: util.RandomInt(1, 1000), Url: receiver.URL, Secret: "secret"}
+	delivery := randomWebhookDelivery(webhook.ID, 0)
+
+	store := mockdb.NewMockStore(ctrl)
+
+	gomock.InOrder(
+		store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(delivery, nil),
+		store.EXPECT().ClaimDueWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{}, sql.ErrNoRows),
+	)
+
+	store.EXPECT().GetWebhook(gomock.Any(), gomock.Eq(webhook.ID)).Times(1).Return(webhook, nil)
+
+	store.EXPECT().
+		RecordWebhookAttemptTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.RecordWebhookAttemptTxParam) (db.RecordWebhookAttemptTxResult, error) {
+			require.Equal(t, util.PendingDelivery, arg.Status)
+			require.Zero(t, arg.ResponseStatus)
+			require.Contains(t, arg.Error, util.ErrWebhookAddressNotAllowed.Error())
+			return db.RecordWebhookAttemptTxResult{}, nil
+		})
+
+	config := util.Config{
+		WebhookTimeout:     time.Second,
+		WebhookRetryDelay:  time.Minute,
+		WebhookMaxAttempts: 3,
+	}
+
+	err := NewWebhookDispatcher(config, store).DispatchDue(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, requests)
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	require.Equal(t, time.Minute, webhookRetryDelay(time.Minute, 0))
+	require.Equal(t, 2*time.Minute, webhookRetryDelay(time.Minute, 1))
+	require.Equal(t, 8*time.Minute, webhookRetryDelay(time.Minute, 3))
+	require.Equal(t, maxWebhookRetryDelay, webhookRetryDelay(time.Minute, 20))
+	require.Equal(t, maxWebhookRetryDelay, webhookRetryDelay(time.Minute, 1000))
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	owner1 := util.RandomOwner()
+	owner2 := util.RandomOwner()
+
+	account1 := db.Account{ID: util.RandomInt(1, 1000), Owner: owner1}
+	account2 := db.Account{ID: util.RandomInt(1001, 2000), Owner: owner2}
+	account3 := db.Account{ID: util.RandomInt(2001, 3000), Owner: owner1}
+
+	transferEvent := func(from, to db.Account) db.Outbox {
+		payload, err := json.Marshal(db.Transfer{ID: util.RandomInt(1, 1000), FromAccountID: from.ID, ToAccountID: to.ID})
+		require.NoError(t, err)
+
+		return db.Outbox{
+			ID:            util.RandomInt(1, 1000),
+			AggregateType: util.TransferAggregate,
+			EventType:     util.TransferCreatedEvent,
+			Payload:       payload,
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		event      db.Outbox
+		buildStubs func(store *mockdb.MockStore)
+		owners     []string
+	}{
+		{
+			name:  "Transfer",
+			event: transferEvent(account1, account2),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			},
+			owners: []string{owner1, owner2},
+		},
+		{
+			name:  "OwnTransfer",
+			event: transferEvent(account1, account3),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+			},
+			owners: []string{owner1},
+		},
+		{
+			name: "Account",
+			event: db.Outbox{
+				ID:            util.RandomInt(1, 1000),
+				AggregateType: util.AccountAggregate,
+				AggregateID:   account2.ID,
+				EventType:     util.AccountStatusChangedEvent,
+				Payload:       json.RawMessage(`{}`),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			},
+			owners: []string{owner2},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			for _, owner := range tc.owners {
+				arg := db.CreateEventWebhookDeliveriesParams{
+					EventID:   tc.event.ID,
+					EventType: tc.event.EventType,
+					Payload:   tc.event.Payload,
+					Owner:     owner,
+				}
+
+				store.EXPECT().
+					CreateEventWebhookDeliveries(gomock.Any(), gomock.Eq(arg)).
+					Times(1).
+					Return([]db.WebhookDelivery{}, nil)
+			}
+
+			err := NewWebhookPublisher(store).Publish(context.Background(), tc.event)
+			require.NoError(t, err)
+		})
+	}
+}